@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/tgifai/friday/internal/pkg/utils"
+	"github.com/tgifai/friday/internal/security/audit"
+)
+
+var auditHwd = &AuditRunner{}
+
+type AuditRunner struct{}
+
+func (r *AuditRunner) cmd() *cli.Command {
+	return &cli.Command{
+		Name:  "audit",
+		Usage: "Show tool executions from the audit log",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "Start date (YYYY-MM-DD), default 7 days ago",
+			},
+			&cli.StringFlag{
+				Name:  "until",
+				Usage: "End date (YYYY-MM-DD), inclusive",
+			},
+			&cli.StringFlag{
+				Name:  "user",
+				Usage: "Only include this user ID",
+			},
+			&cli.StringFlag{
+				Name:  "tool",
+				Usage: "Only include this tool",
+			},
+			&cli.StringFlag{
+				Name:  "session",
+				Usage: "Only include this session key",
+			},
+			&cli.StringFlag{
+				Name:  "agent",
+				Usage: "Only include this agent ID",
+			},
+			&cli.StringFlag{
+				Name:    "channelId",
+				Aliases: []string{"chanId"},
+				Usage:   "Only include this channel ID",
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Value: 100,
+				Usage: "Show at most this many of the newest entries, 0 for all",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print entries as JSON lines",
+			},
+		},
+		Action: r.list,
+		Commands: []*cli.Command{
+			{
+				Name:   "verify",
+				Usage:  "Check that the audit log has not been altered",
+				Action: r.verify,
+			},
+		},
+	}
+}
+
+func (r *AuditRunner) list(_ context.Context, cmd *cli.Command) error {
+	filter := audit.Filter{
+		AgentID:    strings.TrimSpace(cmd.String("agent")),
+		UserID:     strings.TrimSpace(cmd.String("user")),
+		ChannelID:  strings.TrimSpace(cmd.String("channelId")),
+		SessionKey: strings.TrimSpace(cmd.String("session")),
+		Tool:       strings.TrimSpace(cmd.String("tool")),
+	}
+
+	today := time.Now()
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	filter.Since = today.AddDate(0, 0, -6)
+	if s := strings.TrimSpace(cmd.String("since")); s != "" {
+		t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --since %q: %w", s, err)
+		}
+		filter.Since = t
+	}
+	if s := strings.TrimSpace(cmd.String("until")); s != "" {
+		t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --until %q: %w", s, err)
+		}
+		filter.Until = t.AddDate(0, 0, 1)
+	}
+
+	entries, err := audit.Default().Query(filter)
+	if err != nil {
+		return fmt.Errorf("read audit log: %w", err)
+	}
+	if limit := cmd.Int("limit"); limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	if cmd.Bool("json") {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(entries) == 0 {
+		fmt.Println("No audit entries.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tAGENT\tCHANNEL\tUSER\tSESSION\tTOOL\tOUTCOME\tDURATION\tARGS")
+	for _, e := range entries {
+		args := "-"
+		if len(e.Args) > 0 {
+			raw, _ := json.Marshal(e.Args)
+			args = utils.Truncate80(string(raw))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Time.Local().Format(time.DateTime),
+			dash(e.AgentID), dash(e.ChannelID), dash(e.UserID), dash(e.SessionKey),
+			e.Tool, e.Outcome, time.Duration(e.DurationMs)*time.Millisecond, args,
+		)
+	}
+	return w.Flush()
+}
+
+func (r *AuditRunner) verify(_ context.Context, _ *cli.Command) error {
+	report, err := audit.Default().Verify()
+	if err != nil {
+		return fmt.Errorf("after %d intact entries: %w", report.Entries, err)
+	}
+	if report.Entries == 0 {
+		fmt.Println("Audit log is empty.")
+		return nil
+	}
+	fmt.Printf("Audit log intact: %d entries in %d file(s), head %s\n", report.Entries, report.Files, report.Head)
+	return nil
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/security/sandbox"
+)
+
+var doctorHwd = &DoctorRunner{}
+
+type DoctorRunner struct{}
+
+func (r *DoctorRunner) cmd() *cli.Command {
+	return &cli.Command{
+		Name:   "doctor",
+		Usage:  "Check the config and report how each agent runs commands",
+		Action: r.run,
+	}
+}
+
+func (r *DoctorRunner) run(_ context.Context, _ *cli.Command) error {
+	path := consts.DefaultConfigPath()
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Config %s is valid.\n\n", path)
+
+	ids := make([]string, 0, len(cfg.Agents))
+	for id := range cfg.Agents {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tTOOL\tSANDBOX\tNOTE")
+	for _, id := range ids {
+		ag := cfg.Agents[id]
+		for _, name := range sandbox.Tools {
+			runtime, note := sandboxStatus(ag, name)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", id, name, runtime, dash(note))
+		}
+		// CLI backends run their own tools, which neither the sandbox nor
+		// tool_roles reach.
+		for _, spec := range append([]string{ag.Models.Primary}, ag.Models.Fallback...) {
+			if cfg.IsCLIModel(spec) {
+				fmt.Fprintf(w, "%s\tmodel %s\tnone\tCLI backend: runs its own tools on the host; tool_roles cannot limit it\n", id, spec)
+			}
+		}
+	}
+	return w.Flush()
+}
+
+// sandboxStatus reports the sandbox the commands of toolName run in for
+// agent ag, and anything that stops the tool from working with it.
+func sandboxStatus(ag config.AgentConfig, toolName string) (string, string) {
+	cfg := ag.Security.Sandbox
+	executor, ok, err := sandbox.NewExecutorForTool(ag.Workspace, cfg, toolName)
+	switch {
+	case err != nil:
+		return cfg.Runtime, err.Error()
+	case !ok:
+		return "none", "runs directly on the host"
+	case toolName != "exec":
+		if _, ok := executor.(sandbox.Launcher); !ok {
+			return cfg.Runtime, "cannot run long-running processes; calls will fail"
+		}
+	}
+	if cfg.Runtime == sandbox.BackboneLocal {
+		return cfg.Runtime, "no isolation; runs on the host in the workspace"
+	}
+	return cfg.Runtime, ""
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/tgifai/friday/internal/channel/telegram"
 	"github.com/tgifai/friday/internal/config"
 	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/security/secrets"
 )
 
 var msgHwd = &MsgRunner{}
@@ -65,6 +66,9 @@ func (r *MsgRunner) run(ctx context.Context, cmd *cli.Command) error {
 	if !ok {
 		return fmt.Errorf("channel %q was not found in the configured channels", channelID)
 	}
+	if chCfg.Config, err = secrets.ExpandMap(chCfg.Config); err != nil {
+		return fmt.Errorf("channel %s config: %w", channelID, err)
+	}
 
 	switch channel.Type(strings.ToLower(strings.TrimSpace(chCfg.Type))) {
 	case channel.Telegram:
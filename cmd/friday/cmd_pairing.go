@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/security/pairing"
+)
+
+var pairingHwd = &PairingRunner{}
+
+// PairingRunner manages channel ACLs and pending pairing requests. Changes
+// go through config.Update, so they are safe while the gateway runs; the
+// gateway picks them up on its next config reload.
+type PairingRunner struct{}
+
+func (r *PairingRunner) cmd() *cli.Command {
+	channelFlag := &cli.StringFlag{
+		Name:    "channelId",
+		Aliases: []string{"chanId"},
+		Usage:   "Channel ID defined in the config file",
+	}
+	targetFlags := []cli.Flag{
+		channelFlag,
+		&cli.StringFlag{
+			Name:  "chat",
+			Usage: "Chat key, e.g. user:<chatId> or group:<chatId>",
+		},
+		&cli.StringFlag{
+			Name:  "user",
+			Usage: "User ID",
+		},
+	}
+
+	forFlag := &cli.DurationFlag{
+		Name:  "for",
+		Usage: "Grant access for this long, e.g. 24h; default is the channel's security.grant_ttl",
+	}
+
+	return &cli.Command{
+		Name:  "pairing",
+		Usage: "Inspect and manage who can use the bot in each chat",
+		Commands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "List allowed and blocked users with how access was granted",
+				Flags:  []cli.Flag{channelFlag},
+				Action: r.list,
+			},
+			{
+				Name:   "pending",
+				Usage:  "List pairing requests awaiting approval",
+				Flags:  []cli.Flag{channelFlag},
+				Action: r.pending,
+			},
+			{
+				Name:      "approve",
+				Usage:     "Approve a pending pairing request",
+				ArgsUsage: "<request-id>",
+				Flags:     []cli.Flag{channelFlag, forFlag},
+				Action:    r.approve,
+			},
+			{
+				Name:   "grant",
+				Usage:  "Allow a user in a chat without a pairing request",
+				Flags:  append(slices.Clone(targetFlags), forFlag),
+				Action: r.grant,
+			},
+			{
+				Name:   "revoke",
+				Usage:  "Remove a user from a chat's allow list",
+				Flags:  targetFlags,
+				Action: r.revoke,
+			},
+			{
+				Name:   "block",
+				Usage:  "Block a user in a chat; blocked users are not offered pairing",
+				Flags:  targetFlags,
+				Action: r.block,
+			},
+		},
+	}
+}
+
+func (r *PairingRunner) list(_ context.Context, cmd *cli.Command) error {
+	cfg, err := config.Load(consts.DefaultConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	channelID := strings.TrimSpace(cmd.String("channelId"))
+	if channelID != "" {
+		if _, ok := cfg.Channels[channelID]; !ok {
+			return fmt.Errorf("channel %q was not found in the configured channels", channelID)
+		}
+	}
+
+	fmt.Print(pairing.FormatEntries(pairing.Entries(cfg, channelID, time.Now())))
+	return nil
+}
+
+type channelRequest struct {
+	channelID string
+	mgr       *pairing.Manager
+	req       pairing.Request
+}
+
+func (r *PairingRunner) pending(_ context.Context, cmd *cli.Command) error {
+	cfg, err := config.Load(consts.DefaultConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	reqs, err := pendingRequests(cfg, strings.TrimSpace(cmd.String("channelId")))
+	if err != nil {
+		return err
+	}
+	if len(reqs) == 0 {
+		fmt.Println("No pending pairing requests.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHANNEL\tREQUEST\tCHAT KEY\tUSER\tREQUESTED\tEXPIRES\tMESSAGE")
+	for _, one := range reqs {
+		user := one.req.UserID
+		if one.req.UserName != "" {
+			user += " (" + one.req.UserName + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			one.channelID, one.req.ReqID, one.req.ChatKey, user,
+			one.req.CreatedAt.Local().Format(time.DateTime),
+			one.req.ExpiresAt.Local().Format(time.DateTime),
+			one.req.Preview,
+		)
+	}
+	return w.Flush()
+}
+
+func (r *PairingRunner) approve(_ context.Context, cmd *cli.Command) error {
+	reqID := strings.TrimSpace(cmd.Args().First())
+	if reqID == "" {
+		return errors.New("request ID is required")
+	}
+	cfg, err := config.Load(consts.DefaultConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	reqs, err := pendingRequests(cfg, strings.TrimSpace(cmd.String("channelId")))
+	if err != nil {
+		return err
+	}
+
+	var matches []channelRequest
+	for _, one := range reqs {
+		if one.req.ReqID == reqID {
+			matches = []channelRequest{one}
+			break
+		}
+		if strings.HasPrefix(one.req.ReqID, reqID) {
+			matches = append(matches, one)
+		}
+	}
+	switch {
+	case len(matches) == 0:
+		return fmt.Errorf("pairing request %q not found or expired", reqID)
+	case len(matches) > 1:
+		return fmt.Errorf("request ID %q is ambiguous; give more characters", reqID)
+	}
+	found := matches[0]
+
+	// Grant first so a failed config write leaves the request pending.
+	if _, err := found.mgr.GrantACL(found.req.ChatKey, found.req.UserID, cliGrant(cmd)); err != nil {
+		return fmt.Errorf("grant access: %w", err)
+	}
+	if _, err := found.mgr.ResolveRequest(found.req.ReqID, true); err != nil {
+		return fmt.Errorf("resolve request: %w", err)
+	}
+	fmt.Printf("Approved %s: user %s can now use %s on channel %s.\n",
+		found.req.ReqID, found.req.UserID, found.req.ChatKey, found.channelID)
+	fmt.Println("The requester is not notified; their next message will be answered.")
+	return nil
+}
+
+func (r *PairingRunner) grant(_ context.Context, cmd *cli.Command) error {
+	mgr, chatKey, userID, err := aclTarget(cmd)
+	if err != nil {
+		return err
+	}
+	changed, err := mgr.GrantACL(chatKey, userID, cliGrant(cmd))
+	if err != nil {
+		return fmt.Errorf("grant access: %w", err)
+	}
+	if !changed {
+		fmt.Printf("User %s already has access to %s.\n", userID, chatKey)
+		return nil
+	}
+	fmt.Printf("Granted user %s access to %s.\n", userID, chatKey)
+	return nil
+}
+
+// cliGrant builds the grant recorded by approve and grant from --for.
+func cliGrant(cmd *cli.Command) config.ACLGrant {
+	grant := config.ACLGrant{At: time.Now(), Via: consts.GrantViaCLI}
+	if ttl := cmd.Duration("for"); ttl > 0 {
+		grant.Expires = grant.At.Add(ttl)
+	}
+	return grant
+}
+
+func (r *PairingRunner) revoke(_ context.Context, cmd *cli.Command) error {
+	mgr, chatKey, userID, err := aclTarget(cmd)
+	if err != nil {
+		return err
+	}
+	changed, err := mgr.RevokeACL(chatKey, userID)
+	if err != nil {
+		return fmt.Errorf("revoke access: %w", err)
+	}
+	if !changed {
+		fmt.Printf("User %s is not on the allow list of %s.\n", userID, chatKey)
+		return nil
+	}
+	fmt.Printf("Revoked access of user %s to %s.\n", userID, chatKey)
+	return nil
+}
+
+func (r *PairingRunner) block(_ context.Context, cmd *cli.Command) error {
+	mgr, chatKey, userID, err := aclTarget(cmd)
+	if err != nil {
+		return err
+	}
+	changed, err := mgr.BlockACL(chatKey, userID)
+	if err != nil {
+		return fmt.Errorf("block user: %w", err)
+	}
+	if !changed {
+		fmt.Printf("User %s is already blocked in %s.\n", userID, chatKey)
+		return nil
+	}
+	fmt.Printf("Blocked user %s in %s.\n", userID, chatKey)
+	return nil
+}
+
+// aclTarget loads the config and resolves the --channelId, --chat and --user
+// flags of grant, revoke and block.
+func aclTarget(cmd *cli.Command) (*pairing.Manager, string, string, error) {
+	channelID := strings.TrimSpace(cmd.String("channelId"))
+	chatKey := strings.TrimSpace(cmd.String("chat"))
+	userID := strings.TrimSpace(cmd.String("user"))
+	switch {
+	case channelID == "":
+		return nil, "", "", errors.New("--channelId is required")
+	case chatKey == "":
+		return nil, "", "", errors.New("--chat is required")
+	case userID == "":
+		return nil, "", "", errors.New("--user is required")
+	}
+
+	cfg, err := config.Load(consts.DefaultConfigPath())
+	if err != nil {
+		return nil, "", "", fmt.Errorf("load config: %w", err)
+	}
+	chCfg, ok := cfg.Channels[channelID]
+	if !ok {
+		return nil, "", "", fmt.Errorf("channel %q was not found in the configured channels", channelID)
+	}
+	return pairing.Get(pairing.GetKey(chCfg.Type, channelID)), chatKey, userID, nil
+}
+
+// pendingRequests collects the pending requests of one channel, or of all
+// channels when channelID is empty, oldest first.
+func pendingRequests(cfg *config.Config, channelID string) ([]channelRequest, error) {
+	if channelID != "" {
+		if _, ok := cfg.Channels[channelID]; !ok {
+			return nil, fmt.Errorf("channel %q was not found in the configured channels", channelID)
+		}
+	}
+
+	var out []channelRequest
+	for id, chCfg := range cfg.Channels {
+		if channelID != "" && id != channelID {
+			continue
+		}
+		mgr := pairing.Get(pairing.GetKey(chCfg.Type, id))
+		reqs, err := mgr.PendingRequests()
+		if err != nil {
+			return nil, fmt.Errorf("read pending requests of %s: %w", id, err)
+		}
+		for _, req := range reqs {
+			out = append(out, channelRequest{channelID: id, mgr: mgr, req: req})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].req.CreatedAt.Before(out[j].req.CreatedAt) })
+	return out, nil
+}
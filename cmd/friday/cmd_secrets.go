@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/tgifai/friday/internal/security/secrets"
+)
+
+var secretsHwd = &SecretsRunner{}
+
+// SecretsRunner manages the encrypted secrets store referenced from
+// config.yaml as ${secret:name}.
+type SecretsRunner struct{}
+
+func (r *SecretsRunner) cmd() *cli.Command {
+	return &cli.Command{
+		Name:  "secrets",
+		Usage: "Manage the encrypted store for API keys and tokens referenced as ${secret:name}",
+		Commands: []*cli.Command{
+			{
+				Name:      "set",
+				Usage:     "Store a secret; the value is read from stdin unless --value is given",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "value",
+						Usage: "Secret value; prefer stdin so it does not end up in shell history",
+					},
+				},
+				Action: r.set,
+			},
+			{
+				Name:      "get",
+				Usage:     "Print a decrypted secret",
+				ArgsUsage: "<name>",
+				Action:    r.get,
+			},
+			{
+				Name:   "list",
+				Usage:  "List stored secret names",
+				Action: r.list,
+			},
+			{
+				Name:      "rm",
+				Usage:     "Remove a secret",
+				ArgsUsage: "<name>",
+				Action:    r.rm,
+			},
+		},
+	}
+}
+
+func (r *SecretsRunner) set(_ context.Context, cmd *cli.Command) error {
+	name, err := secretName(cmd)
+	if err != nil {
+		return err
+	}
+
+	value := cmd.String("value")
+	if !cmd.IsSet("value") {
+		if value, err = readSecretValue(name); err != nil {
+			return err
+		}
+	}
+	if value == "" {
+		return errors.New("secret value cannot be empty")
+	}
+
+	store := secrets.Default()
+	if err := store.Set(name, value); err != nil {
+		return fmt.Errorf("store secret: %w", err)
+	}
+	source, _ := store.KeySource()
+	fmt.Printf("Stored secret %s in %s (key: %s).\n", name, store.Path(), source)
+	fmt.Printf("Reference it in config.yaml as \"${secret:%s}\".\n", name)
+	return nil
+}
+
+func (r *SecretsRunner) get(_ context.Context, cmd *cli.Command) error {
+	name, err := secretName(cmd)
+	if err != nil {
+		return err
+	}
+	value, err := secrets.Default().Get(name)
+	if err != nil {
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func (r *SecretsRunner) list(_ context.Context, _ *cli.Command) error {
+	store := secrets.Default()
+	infos, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(infos) == 0 {
+		fmt.Println("No secrets stored.")
+		return nil
+	}
+
+	source, _ := store.KeySource()
+	fmt.Printf("Store: %s (key: %s)\n\n", store.Path(), source)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tUPDATED")
+	for _, one := range infos {
+		fmt.Fprintf(w, "%s\t%s\n", one.Name, one.UpdatedAt.Local().Format(time.DateTime))
+	}
+	return w.Flush()
+}
+
+func (r *SecretsRunner) rm(_ context.Context, cmd *cli.Command) error {
+	name, err := secretName(cmd)
+	if err != nil {
+		return err
+	}
+	removed, err := secrets.Default().Delete(name)
+	if err != nil {
+		return fmt.Errorf("remove secret: %w", err)
+	}
+	if !removed {
+		fmt.Printf("Secret %s does not exist.\n", name)
+		return nil
+	}
+	fmt.Printf("Removed secret %s.\n", name)
+	return nil
+}
+
+func secretName(cmd *cli.Command) (string, error) {
+	name := strings.TrimSpace(cmd.Args().First())
+	if name == "" {
+		return "", errors.New("secret name is required")
+	}
+	return name, nil
+}
+
+// readSecretValue reads the value from stdin: the first line when typed at
+// a terminal, everything up to EOF when piped, minus the trailing newline.
+func readSecretValue(name string) (string, error) {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return "", fmt.Errorf("stat stdin: %w", err)
+	}
+	if info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Printf("Value for %s: ", name)
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("read value: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	raw, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("read value: %w", err)
+	}
+	return strings.TrimRight(string(raw), "\r\n"), nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/usage"
+)
+
+var usageHwd = &UsageRunner{}
+
+type UsageRunner struct{}
+
+func (r *UsageRunner) cmd() *cli.Command {
+	return &cli.Command{
+		Name:  "usage",
+		Usage: "Report token usage and cost from the usage ledger",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "Start date (YYYY-MM-DD), default 30 days ago",
+			},
+			&cli.StringFlag{
+				Name:  "until",
+				Usage: "End date (YYYY-MM-DD), inclusive",
+			},
+			&cli.StringFlag{
+				Name:  "by",
+				Value: "day,user,model",
+				Usage: "Comma-separated grouping: " + strings.Join(usage.Dimensions, ", "),
+			},
+			&cli.StringFlag{
+				Name:  "agent",
+				Usage: "Only include this agent ID",
+			},
+			&cli.StringFlag{
+				Name:  "user",
+				Usage: "Only include this user ID",
+			},
+			&cli.StringFlag{
+				Name:    "channelId",
+				Aliases: []string{"chanId"},
+				Usage:   "Only include this channel ID",
+			},
+			&cli.StringFlag{
+				Name:  "model",
+				Usage: "Only include this model (model_name or provider_id:model_name)",
+			},
+		},
+		Action: r.run,
+	}
+}
+
+func (r *UsageRunner) run(_ context.Context, cmd *cli.Command) error {
+	filter := usage.Filter{
+		AgentID:   strings.TrimSpace(cmd.String("agent")),
+		UserID:    strings.TrimSpace(cmd.String("user")),
+		ChannelID: strings.TrimSpace(cmd.String("channelId")),
+		Model:     strings.TrimSpace(cmd.String("model")),
+	}
+
+	today := time.Now()
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	filter.Since = today.AddDate(0, 0, -29)
+	if s := strings.TrimSpace(cmd.String("since")); s != "" {
+		t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --since %q: %w", s, err)
+		}
+		filter.Since = t
+	}
+	if s := strings.TrimSpace(cmd.String("until")); s != "" {
+		t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --until %q: %w", s, err)
+		}
+		filter.Until = t.AddDate(0, 0, 1)
+	}
+
+	var by []string
+	for _, dim := range strings.Split(cmd.String("by"), ",") {
+		if dim = strings.ToLower(strings.TrimSpace(dim)); dim != "" {
+			by = append(by, dim)
+		}
+	}
+	if err := usage.ValidateDimensions(by); err != nil {
+		return fmt.Errorf("invalid --by: %w", err)
+	}
+
+	records, err := usage.Default().Query(filter)
+	if err != nil {
+		return fmt.Errorf("read usage ledger: %w", err)
+	}
+
+	currency := "USD"
+	if cfg, err := config.Load(consts.DefaultConfigPath()); err == nil && cfg.Usage.Currency != "" {
+		currency = cfg.Usage.Currency
+	}
+	fmt.Print(usage.FormatTable(usage.Summarize(records, by...), by, currency))
+	return nil
+}
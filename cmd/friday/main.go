@@ -17,6 +17,11 @@ func main() {
 			gwHwd.cmd(),
 			msgHwd.cmd(),
 			cronjobHwd.cmd(),
+			usageHwd.cmd(),
+			pairingHwd.cmd(),
+			auditHwd.cmd(),
+			doctorHwd.cmd(),
+			secretsHwd.cmd(),
 			onboardHwd.cmd(),
 			updateHwd.cmd(),
 		},
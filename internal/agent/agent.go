@@ -15,6 +15,7 @@ import (
 
 	"github.com/tgifai/friday/internal/agent/session"
 	"github.com/tgifai/friday/internal/agent/skill"
+	"github.com/tgifai/friday/internal/agent/speech"
 	"github.com/tgifai/friday/internal/agent/tool"
 	"github.com/tgifai/friday/internal/agent/tool/agentx"
 	"github.com/tgifai/friday/internal/agent/tool/browserx"
@@ -34,6 +35,12 @@ import (
 	"github.com/tgifai/friday/internal/pkg/logs"
 	"github.com/tgifai/friday/internal/pkg/utils"
 	"github.com/tgifai/friday/internal/provider"
+	"github.com/tgifai/friday/internal/security/egress"
+	"github.com/tgifai/friday/internal/security/fspolicy"
+	"github.com/tgifai/friday/internal/security/redact"
+	"github.com/tgifai/friday/internal/security/role"
+	"github.com/tgifai/friday/internal/security/sandbox"
+	"github.com/tgifai/friday/internal/usage"
 )
 
 const (
@@ -49,10 +56,16 @@ type Agent struct {
 	name      string
 	workspace string
 
-	tools   *tool.Registry
-	skills  *skill.Registry
-	mcpMgr  *mcpx.MCPTool
-	sessMgr *session.Manager
+	tools     *tool.Registry
+	fsPolicy  *fspolicy.Policy            // paths the file tools and commands may use
+	sandboxes map[string]sandbox.Executor // tool name → executor its commands run through
+	skills    *skill.Registry
+	mcpMgr    *mcpx.MCPTool
+	sessMgr   *session.Manager
+
+	transcriber speech.Transcriber // nil when voice.stt is not configured
+	synthesizer speech.Synthesizer // nil when voice.tts is not configured
+	ttsCfg      config.TTSConfig
 
 	enqueue          EnqueueFunc // allows agent to self-enqueue messages (set by gateway)
 	consolidateEvery int
@@ -60,6 +73,7 @@ type Agent struct {
 	contextBudget    int
 	reserveTokens    int
 	toolsRegistered  sync.Map // providerID → true; ensures RegisterTools is called once per provider
+	budgetNotices    sync.Map // budget notice key → struct{}; one warning per budget period
 }
 
 func NewAgent(_ context.Context, cfg config.AgentConfig) (*Agent, error) {
@@ -101,17 +115,41 @@ func NewAgent(_ context.Context, cfg config.AgentConfig) (*Agent, error) {
 		reserveTokens = defaultReserveTokens
 	}
 
+	transcriber, err := speech.NewTranscriber(cfg.Voice.STT)
+	if err != nil {
+		return nil, fmt.Errorf("init transcriber: %w", err)
+	}
+
+	synthesizer, err := speech.NewSynthesizer(cfg.Voice.TTS)
+	if err != nil {
+		return nil, fmt.Errorf("init synthesizer: %w", err)
+	}
+
+	fsPolicy, err := newFSPolicy(cfg.Workspace, cfg.Security.Filesystem)
+	if err != nil {
+		return nil, fmt.Errorf("init filesystem policy: %w", err)
+	}
+	sandboxes, err := newSandboxes(cfg.Workspace, cfg.Security.Sandbox)
+	if err != nil {
+		return nil, fmt.Errorf("init sandbox: %w", err)
+	}
+
 	ag := &Agent{
 		id:               cfg.ID,
 		name:             cfg.Name,
 		workspace:        cfg.Workspace,
 		sessMgr:          sessMgr,
 		tools:            tool.NewRegistry(),
+		fsPolicy:         fsPolicy,
+		sandboxes:        sandboxes,
 		skills:           skill.NewRegistry(cfg.Workspace),
 		consolidateEvery: consolidateEvery,
 		flushCooldown:    flushCooldown,
 		contextBudget:    contextBudget,
 		reserveTokens:    reserveTokens,
+		transcriber:      transcriber,
+		synthesizer:      synthesizer,
+		ttsCfg:           cfg.Voice.TTS,
 	}
 
 	return ag, nil
@@ -143,23 +181,13 @@ func (ag *Agent) Init(ctx context.Context) error {
 		logs.Warn("[agent:%s] failed to load skills: %v", ag.id, err)
 	}
 
-	allowedPaths := []string{ag.workspace}
-	// Merge extra allowed paths from macOS sandbox bookmarks (colon-separated).
-	if paths := os.Getenv("FRIDAY_ALLOWED_PATHS"); paths != "" {
-		for _, p := range strings.Split(paths, ":") {
-			p = strings.TrimSpace(p)
-			if p != "" {
-				allowedPaths = append(allowedPaths, p)
-			}
-		}
-	}
 	// file related tools
-	_ = ag.tools.Register(filex.NewFileTool(ag.workspace, allowedPaths))
-	_ = ag.tools.Register(filex.NewReadTool(ag.workspace, allowedPaths))
-	_ = ag.tools.Register(filex.NewWriteTool(ag.workspace, allowedPaths))
-	_ = ag.tools.Register(filex.NewListTool(ag.workspace, allowedPaths))
-	_ = ag.tools.Register(filex.NewDeleteTool(ag.workspace, allowedPaths))
-	_ = ag.tools.Register(filex.NewEditTool(ag.workspace, allowedPaths))
+	_ = ag.tools.Register(filex.NewFileTool(ag.fsPolicy))
+	_ = ag.tools.Register(filex.NewReadTool(ag.fsPolicy))
+	_ = ag.tools.Register(filex.NewWriteTool(ag.fsPolicy))
+	_ = ag.tools.Register(filex.NewListTool(ag.fsPolicy))
+	_ = ag.tools.Register(filex.NewDeleteTool(ag.fsPolicy))
+	_ = ag.tools.Register(filex.NewEditTool(ag.fsPolicy))
 
 	// time tool
 	_ = ag.tools.Register(timex.NewTimeTool())
@@ -168,8 +196,8 @@ func (ag *Agent) Init(ctx context.Context) error {
 	_ = ag.tools.Register(msgx.NewMessageTool())
 
 	// shell related tools
-	_ = ag.tools.Register(shellx.NewExecTool(ag.workspace))
-	_ = ag.tools.Register(shellx.NewProcessTool(ag.workspace))
+	_ = ag.tools.Register(shellx.NewExecTool(ag.workspace, ag.sandboxes["exec"]).WithFSPolicy(ag.fsPolicy))
+	_ = ag.tools.Register(shellx.NewProcessTool(ag.workspace, ag.sandboxes["process"]).WithFSPolicy(ag.fsPolicy))
 
 	// knowledge base tools (only if qmd CLI is available)
 	if qmdx.Available() {
@@ -188,13 +216,13 @@ func (ag *Agent) Init(ctx context.Context) error {
 	_ = ag.tools.Register(cronx.NewCronTool())
 
 	// agent delegation tools
-	_ = ag.tools.Register(agentx.NewAgentTool(ag.workspace))
+	_ = ag.tools.Register(agentx.NewAgentTool(ag.workspace, ag.sandboxes["agent"]))
 
 	// browser automation tools
 	_ = ag.tools.Register(browserx.NewBrowserTool())
 
 	// MCP server tools
-	ag.mcpMgr = mcpx.NewMCPTool()
+	ag.mcpMgr = mcpx.NewMCPTool(ag.sandboxes["mcp"])
 	if err := ag.mcpMgr.LoadConfig(ctx, ag.workspace); err != nil {
 		logs.Warn("[agent:%s] failed to load mcp config: %v", ag.id, err)
 	}
@@ -252,13 +280,49 @@ func (ag *Agent) ProcessMessage(ctx context.Context, msg *channel.Message) (*cha
 	}
 
 	// get or create current session
-	var sess *session.Session
-	if msg.SessionKey != "" {
-		sess = ag.sessMgr.GetOrCreate(msg.SessionKey)
-	} else {
-		sess = ag.sessMgr.GetOrCreateFor(msg.ChannelType, msg.ChannelID, msg.ChatID)
-	}
+	sess := ag.sessionFor(msg)
 	msg.SessionKey = sess.SessionKey
+	// Every model call of this turn, transcription included, is billed to
+	// the same agent, session and user.
+	tags := usage.Tags{
+		AgentID:    ag.id,
+		SessionKey: sess.SessionKey,
+		UserID:     msg.UserID,
+		ChannelID:  msg.ChannelID,
+		Scheduled:  msg.ChannelType == channel.Type("cron"),
+	}
+	ctx = usage.WithTags(ctx, tags)
+	// roleLimited is set when the sender's role may not use some tool.
+	var roleLimited bool
+	if len(agCfg.ToolRoles) > 0 && msg.Role != "" {
+		allow := func(name string) bool {
+			return role.ToolAllowed(agCfg.ToolRoles, name, msg.Role)
+		}
+		ctx = tool.WithFilter(ctx, allow)
+		for _, t := range ag.tools.List() {
+			roleLimited = roleLimited || !allow(t.Name())
+		}
+	}
+	ctx = withUntrustedTurn(ctx, agCfg.Security.Untrusted, sess, msg)
+	ctx = redact.WithTurn(ctx, redact.Default().NewTurn())
+	egressPolicy, err := newEgressPolicy(cfg.Egress, agCfg.Security.Egress)
+	if err != nil {
+		return nil, fmt.Errorf("egress policy: %w", err)
+	}
+	ctx = egress.WithPolicy(ctx, egressPolicy)
+
+	models := append([]string{agCfg.Models.Primary}, agCfg.Models.Fallback...)
+	models, refusal, err := ag.applyBudget(ctx, &cfg.Usage.Budget, tags, msg, models)
+	if err != nil || refusal != nil {
+		return refusal, err
+	}
+
+	// Persist uploaded files once so every model attempt sees the same paths.
+	ag.saveAttachments(ctx, msg)
+	// Turn voice notes into text before any model sees the message.
+	inboundVoice := hasVoice(msg)
+	ag.transcribeVoice(ctx, msg)
+
 	defer func() {
 		if err := ag.sessMgr.Save(sess); err != nil {
 			logs.CtxWarn(ctx, "[agent:%s] failed to persist session: %v", ag.id, err)
@@ -266,9 +330,9 @@ func (ag *Agent) ProcessMessage(ctx context.Context, msg *channel.Message) (*cha
 	}()
 
 	var resp *channel.Response
-	models := append([]string{agCfg.Models.Primary}, agCfg.Models.Fallback...)
 	ch, _ := channel.Get(msg.ChannelID)
 	var modelErrors []string
+	progress := &turnProgress{}
 	for _, spec := range models {
 		ms, err := provider.ParseModelSpec(spec)
 		if err != nil {
@@ -280,25 +344,43 @@ func (ag *Agent) ProcessMessage(ctx context.Context, msg *channel.Message) (*cha
 			logs.CtxWarn(ctx, "[agent:%s] provider not found: %s", ag.id, ms.ProviderID)
 			continue
 		}
+		if roleLimited && prov.Type() == provider.CLI {
+			// CLI backends run their own tools, outside the role filter.
+			logs.CtxWarn(ctx, "[agent:%s] skipping CLI model %s: role %s is limited by tool_roles", ag.id, ms, msg.Role)
+			modelErrors = append(modelErrors, fmt.Sprintf("[%s] skipped: CLI models cannot apply tool_roles", spec))
+			continue
+		}
 		if _, loaded := ag.toolsRegistered.LoadOrStore(ms.ProviderID, true); !loaded {
 			prov.RegisterTools(ag.tools.ListToolInfos())
 		}
-		resp, err = ag.runLoop(ctx, prov, ms, sess, msg, agCfg.Config)
+		resp, err = ag.runLoop(ctx, prov, ms, sess, msg, agCfg.Config, progress)
+		if err != nil && ctx.Err() != nil {
+			// The turn was cancelled (e.g. superseded by an edit); trying
+			// fallback models would only fail the same way.
+			return nil, ctx.Err()
+		}
 		if err != nil {
 			logs.CtxWarn(ctx, "[agent:%s] model %s failed: %v", ag.id, ms, err)
-			errMsg := fmt.Sprintf("[%s] error: %v", spec, err)
+			errMsg := redact.Scrub(ctx, fmt.Sprintf("[%s] error: %v", spec, err))
 			modelErrors = append(modelErrors, errMsg)
-			if ch != nil {
-				_ = ch.SendMessage(ctx, msg.ChatID, errMsg)
+			if ch != nil && agCfg.Config.ShowModelErrors {
+				_ = ch.SendMessage(ctx, msg.ChatID, errMsg, channel.WithThread(msg.ThreadID), channel.WithOrigin(msg.ID))
 			}
 			continue
 		}
 		break
 	}
 
+	if resp != nil {
+		ag.maybeVoiceReply(ctx, sess, msg, resp, inboundVoice)
+	}
+
 	// fallback response — all models failed
 	if resp == nil {
-		fallbackContent := "All models failed:\n\n" + strings.Join(modelErrors, "\n")
+		fallbackContent := "All models failed. Please try again later."
+		if agCfg.Config.ShowModelErrors {
+			fallbackContent = "All models failed:\n\n" + strings.Join(modelErrors, "\n")
+		}
 		resp = &channel.Response{
 			ID:      msg.ID,
 			Content: fallbackContent,
@@ -331,11 +413,69 @@ func (ag *Agent) SetEnqueue(fn EnqueueFunc) {
 	ag.enqueue = fn
 }
 
+// SessionKeyFor returns the session key for an inbound message, applying the
+// channel's session scoping: forum topics and threads get their own session
+// when session.threads is on, and session.scope decides whether a session is
+// shared by the chat or private to each user.
+func (ag *Agent) SessionKeyFor(msg *channel.Message) string {
+	k := session.Key{
+		AgentID:     ag.id,
+		ChannelType: msg.ChannelType,
+		ChannelID:   msg.ChannelID,
+		ChatID:      msg.ChatID,
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return session.GenerateKey(k)
+	}
+	chCfg := cfg.Channels[msg.ChannelID]
+	if msg.ThreadID != "" && chCfg.Session.Threads {
+		k.ThreadID = msg.ThreadID
+	}
+	// Messages without a sender (cron, anonymous HTTP) stay chat-scoped.
+	if msg.UserID != "" {
+		switch chCfg.Session.Scope {
+		case consts.SessionScopeUserInChat:
+			k.UserID = msg.UserID
+		case consts.SessionScopeUser:
+			k.ChatID = session.AnyChat
+			k.ThreadID = ""
+			k.UserID = msg.UserID
+		}
+	}
+	return session.GenerateKey(k)
+}
+
+// sessionFor resolves the session of msg, preferring the key assigned by the
+// gateway when the message was enqueued.
+func (ag *Agent) sessionFor(msg *channel.Message) *session.Session {
+	if msg.SessionKey != "" {
+		return ag.sessMgr.GetOrCreate(msg.SessionKey)
+	}
+	return ag.sessMgr.GetOrCreate(ag.SessionKeyFor(msg))
+}
+
+// ForgetMessage removes the turn started by msg (matched by message ID) from
+// its session, e.g. after the user edited or recalled the message. It reports
+// whether the session contained the turn.
+func (ag *Agent) ForgetMessage(ctx context.Context, msg *channel.Message) bool {
+	sess := ag.sessionFor(msg)
+	if !sess.RemoveTurn(msg.ID) {
+		return false
+	}
+	if err := ag.sessMgr.Save(sess); err != nil {
+		logs.CtxWarn(ctx, "[agent:%s] failed to persist session: %v", ag.id, err)
+	}
+	logs.CtxInfo(ctx, "[agent:%s] removed message %s from session %s", ag.id, msg.ID, sess.SessionKey)
+	return true
+}
+
 // ResetSession clears the current session for the given message's channel/chat.
 // Before clearing, it archives a brief summary of user messages to today's
 // daily memory file. No LLM call is made.
 func (ag *Agent) ResetSession(ctx context.Context, msg *channel.Message) (string, error) {
-	sess := ag.sessMgr.GetOrCreateFor(msg.ChannelType, msg.ChannelID, msg.ChatID)
+	sess := ag.sessionFor(msg)
 
 	history := sess.History()
 	msgCount := sess.MsgCount()
@@ -347,7 +487,12 @@ func (ag *Agent) ResetSession(ctx context.Context, msg *channel.Message) (string
 		}
 	}
 
+	// The per-chat voice preference is a setting, not conversation state.
+	voicePref := sess.GetMeta(voiceReplyMetaKey)
 	sess.Clear()
+	if voicePref != "" {
+		sess.SetMeta(voiceReplyMetaKey, voicePref)
+	}
 
 	if err := ag.sessMgr.Save(sess); err != nil {
 		return "", fmt.Errorf("save cleared session: %w", err)
@@ -0,0 +1,166 @@
+package agent
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/tgifai/friday/internal/channel"
+	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/pkg/doctext"
+	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/provider"
+)
+
+const (
+	// maxDocumentRunes caps the extracted text injected per document.
+	maxDocumentRunes = 32_000
+	// maxDocumentTotalRunes caps the extracted text injected per message.
+	maxDocumentTotalRunes = 96_000
+)
+
+// saveAttachments copies file attachments, including those of the
+// replied-to message, into the workspace uploads directory so tools can read,
+// convert or edit them later. The saved path is recorded on the attachment
+// and surfaced to the LLM by buildUserMessage.
+func (ag *Agent) saveAttachments(ctx context.Context, msg *channel.Message) {
+	now := time.Now()
+	ag.saveFiles(ctx, now, msg.ID, msg.Attachments)
+	if msg.ReplyTo != nil {
+		ag.saveFiles(ctx, now, msg.ReplyTo.MessageID, msg.ReplyTo.Attachments)
+	}
+}
+
+func (ag *Agent) saveFiles(ctx context.Context, now time.Time, msgID string, atts []channel.Attachment) {
+	for i := range atts {
+		att := &atts[i]
+		if att.Type != channel.AttachmentFile || att.LocalPath != "" || len(att.Data) == 0 {
+			continue
+		}
+
+		name := sanitizeFileName(att.FileName)
+		if name == "" {
+			name = fmt.Sprintf("file-%d", i+1)
+		}
+		if msgID != "" {
+			name = sanitizeFileName(msgID) + "-" + name
+		}
+		relPath := filepath.Join(consts.UploadsDir(now), name)
+		dst := filepath.Join(ag.workspace, relPath)
+
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			logs.CtxWarn(ctx, "[agent:%s] save attachment: mkdir %s: %v", ag.id, filepath.Dir(dst), err)
+			continue
+		}
+		if err := os.WriteFile(dst, att.Data, 0o644); err != nil {
+			logs.CtxWarn(ctx, "[agent:%s] save attachment %s: %v", ag.id, name, err)
+			continue
+		}
+		att.LocalPath = dst
+		logs.CtxInfo(ctx, "[agent:%s] saved attachment %s (%d bytes)", ag.id, relPath, len(att.Data))
+	}
+}
+
+// buildFileParts renders a file attachment for the LLM. Providers with native
+// file support get the raw document; everyone else gets the extracted text in
+// a delimited block. Binary files without an extractor are announced by name
+// and path only. budget tracks the remaining rune allowance for this message.
+func buildFileParts(att channel.Attachment, provType provider.Type, budget *int) []schema.MessageInputPart {
+	name := att.FileName
+	if name == "" {
+		name = filepath.Base(att.LocalPath)
+	}
+	if name == "" || name == "." {
+		name = "file"
+	}
+	mimeType := doctext.MIMEType(att.MIMEType, name)
+
+	var header strings.Builder
+	fmt.Fprintf(&header, "[File attachment: %s (%s, %d bytes)", name, mimeType, len(att.Data))
+	if att.LocalPath != "" {
+		fmt.Fprintf(&header, ", saved to %s", att.LocalPath)
+	}
+	header.WriteString("]")
+
+	if provider.SupportsNativeFile(provType, mimeType) {
+		b64 := base64.StdEncoding.EncodeToString(att.Data)
+		return []schema.MessageInputPart{
+			{Type: schema.ChatMessagePartTypeText, Text: header.String()},
+			{
+				Type: schema.ChatMessagePartTypeFileURL,
+				File: &schema.MessageInputFile{
+					MessagePartCommon: schema.MessagePartCommon{
+						Base64Data: &b64,
+						MIMEType:   mimeType,
+					},
+					Name: name,
+				},
+			},
+		}
+	}
+
+	text, err := doctext.Extract(att.Data, mimeType, name)
+	switch {
+	case errors.Is(err, doctext.ErrUnsupported):
+		return []schema.MessageInputPart{{
+			Type: schema.ChatMessagePartTypeText,
+			Text: header.String() + "\n(binary file, content not shown; use tools on the saved path if needed)",
+		}}
+	case err != nil:
+		return []schema.MessageInputPart{{
+			Type: schema.ChatMessagePartTypeText,
+			Text: fmt.Sprintf("%s\n(text extraction failed: %v)", header.String(), err),
+		}}
+	}
+
+	text, truncated := truncateRunes(strings.TrimSpace(text), min(maxDocumentRunes, max(*budget, 0)))
+	*budget -= utf8.RuneCountInString(text)
+
+	var b strings.Builder
+	b.WriteString(header.String())
+	b.WriteString("\n<document name=\"")
+	b.WriteString(name)
+	b.WriteString("\">\n")
+	b.WriteString(text)
+	b.WriteString("\n</document>")
+	if truncated {
+		b.WriteString("\n(content truncated; read the saved file for the rest)")
+	}
+	return []schema.MessageInputPart{{Type: schema.ChatMessagePartTypeText, Text: b.String()}}
+}
+
+// truncateRunes cuts s to at most n runes and reports whether it was cut.
+func truncateRunes(s string, n int) (string, bool) {
+	if utf8.RuneCountInString(s) <= n {
+		return s, false
+	}
+	runes := []rune(s)
+	return string(runes[:n]), true
+}
+
+// sanitizeFileName strips directory components and characters that are
+// awkward in shell commands from a user-supplied file name.
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/"))
+	if name == "." || name == "/" || name == ".." {
+		return ""
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', 0:
+			return '_'
+		}
+		if r < 0x20 {
+			return '_'
+		}
+		return r
+	}, name)
+}
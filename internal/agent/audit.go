@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/tgifai/friday/internal/agent/tool"
+	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/audit"
+	"github.com/tgifai/friday/internal/security/egress"
+	"github.com/tgifai/friday/internal/security/fspolicy"
+	"github.com/tgifai/friday/internal/security/redact"
+	"github.com/tgifai/friday/internal/usage"
+)
+
+// auditToolCall appends a tool call to the audit log. Failures are logged
+// and never fail the call.
+func (ag *Agent) auditToolCall(ctx context.Context,
+	call *schema.ToolCall,
+	result string,
+	taintLevel string,
+	callErr error,
+	start time.Time,
+	elapsed time.Duration,
+) {
+	tags := usage.TagsFrom(ctx)
+	entry := audit.Entry{
+		Time:         start,
+		AgentID:      ag.id,
+		SessionKey:   tags.SessionKey,
+		UserID:       tags.UserID,
+		ChannelID:    tags.ChannelID,
+		Scheduled:    tags.Scheduled,
+		Tool:         call.Function.Name,
+		CallID:       call.ID,
+		Outcome:      audit.OutcomeOK,
+		DurationMs:   elapsed.Milliseconds(),
+		ResultSize:   len(result),
+		ResultDigest: audit.Digest(result),
+		Taint:        taintLevel,
+	}
+	var args map[string]any
+	if call.Function.Arguments != "" {
+		if err := sonic.UnmarshalString(call.Function.Arguments, &args); err != nil {
+			args = map[string]any{"_unparsed": call.Function.Arguments}
+		}
+	}
+	entry.Args = audit.RedactArgs(args)
+	if callErr != nil {
+		entry.Outcome = audit.OutcomeError
+		if errors.Is(callErr, tool.ErrNotAllowed) || errors.Is(callErr, fspolicy.ErrDenied) ||
+			errors.Is(callErr, egress.ErrBlocked) {
+			entry.Outcome = audit.OutcomeDenied
+		}
+		// The tool ran with secrets restored, so its error may echo them.
+		entry.Error = redact.Scrub(ctx, callErr.Error())
+	}
+	if err := audit.Default().Append(entry); err != nil {
+		logs.CtxWarn(ctx, "[agent:%s] audit tool call %s: %v", ag.id, call.Function.Name, err)
+	}
+}
@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tgifai/friday/internal/channel"
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/usage"
+)
+
+const defaultBudgetReply = "Usage budget reached: the {period} budget for {scope} is used up. Please try again later."
+
+// ErrBudgetExceeded is returned for scheduled turns whose budget is used up.
+var ErrBudgetExceeded = errors.New("usage budget exceeded")
+
+// applyBudget checks the usage budgets that govern the turn. It returns the
+// models the turn may use, or a refusal response when the budget is used up
+// and the configured action is to refuse. Soft warnings and downgrade notices
+// are sent once per budget period.
+func (ag *Agent) applyBudget(ctx context.Context,
+	cfg *config.BudgetConfig,
+	tags usage.Tags,
+	msg *channel.Message,
+	models []string,
+) ([]string, *channel.Response, error) {
+	verdict, err := usage.Default().CheckBudget(cfg, tags, time.Now())
+	if err != nil {
+		// Failing open keeps the assistant usable when the ledger is unreadable.
+		logs.CtxWarn(ctx, "[agent:%s] budget check failed: %v", ag.id, err)
+		return models, nil, nil
+	}
+
+	switch {
+	case verdict.Exceeded:
+		logs.CtxWarn(ctx, "[agent:%s] %s budget for %s exceeded (%.0f%%)",
+			ag.id, verdict.Period, verdict.Scope, verdict.Used*100)
+		if tags.Scheduled {
+			return nil, nil, fmt.Errorf("%w: %s budget for %s", ErrBudgetExceeded, verdict.Period, verdict.Scope)
+		}
+		if cfg.Action == consts.BudgetActionDowngrade {
+			if model := downgradeModel(cfg, models); model != "" {
+				ag.notifyBudgetOnce(ctx, msg, verdict.Key+":downgrade", fmt.Sprintf(
+					"The %s budget for %s is used up, so replies now use %s.", verdict.Period, verdict.Scope, model))
+				return []string{model}, nil, nil
+			}
+		}
+		reply := cfg.Reply
+		if reply == "" {
+			reply = defaultBudgetReply
+		}
+		reply = strings.NewReplacer("{period}", verdict.Period, "{scope}", verdict.Scope).Replace(reply)
+		return nil, &channel.Response{ID: msg.ID, Content: reply}, nil
+
+	case verdict.Warn && !tags.Scheduled:
+		ag.notifyBudgetOnce(ctx, msg, verdict.Key+":warn", fmt.Sprintf(
+			"Heads up: %.0f%% of the %s budget for %s has been used.", verdict.Used*100, verdict.Period, verdict.Scope))
+	}
+	return models, nil, nil
+}
+
+// downgradeModel picks the model used once a budget is exhausted: the
+// configured downgrade model, else the agent's last fallback.
+func downgradeModel(cfg *config.BudgetConfig, models []string) string {
+	if cfg.Downgrade != "" {
+		return cfg.Downgrade
+	}
+	if len(models) > 1 {
+		return models[len(models)-1]
+	}
+	return ""
+}
+
+// notifyBudgetOnce sends text to the chat unless a notice with the same key
+// has already been sent by this process.
+func (ag *Agent) notifyBudgetOnce(ctx context.Context, msg *channel.Message, key, text string) {
+	if _, sent := ag.budgetNotices.LoadOrStore(key, struct{}{}); sent {
+		return
+	}
+	ch, err := channel.Get(msg.ChannelID)
+	if err != nil {
+		return
+	}
+	if err := ch.SendMessage(ctx, msg.ChatID, text, channel.WithThread(msg.ThreadID), channel.WithOrigin(msg.ID)); err != nil {
+		logs.CtxDebug(ctx, "[agent:%s] budget notice failed: %v", ag.id, err)
+	}
+}
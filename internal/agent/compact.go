@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/bytedance/sonic"
 	"github.com/cloudwego/eino/schema"
@@ -11,6 +12,8 @@ import (
 	"github.com/tgifai/friday/internal/agent/session"
 	"github.com/tgifai/friday/internal/pkg/logs"
 	"github.com/tgifai/friday/internal/provider"
+	"github.com/tgifai/friday/internal/security/redact"
+	"github.com/tgifai/friday/internal/usage"
 )
 
 const (
@@ -96,7 +99,7 @@ func (ag *Agent) runPreFlush(
 	})
 
 	for iter := 0; iter < preFlushMaxIterations; iter++ {
-		resp, err := p.Generate(ctx, modelSpec.ModelName, flushMsgs)
+		resp, err := ag.generate(ctx, p, modelSpec, usage.PurposeFlush, flushMsgs)
 		if err != nil {
 			logs.CtxWarn(ctx, "[agent:%s] pre-flush LLM call failed: %v", ag.id, err)
 			return
@@ -128,8 +131,18 @@ func (ag *Agent) runPreFlush(
 
 // buildToolResultMessage executes a tool call and returns the result as a Tool message.
 // This is the shared helper used by both runLoop and runPreFlush.
+// Restricted tools are refused once the turn holds untrusted content, and
+// every call is recorded in the audit log. Redacted secrets in the
+// arguments are restored for the call only, and secrets in the result are
+// redacted before the model sees it.
 func (ag *Agent) buildToolResultMessage(ctx context.Context, call *schema.ToolCall) *schema.Message {
-	res, callErr := ag.tools.ExecuteToolCall(ctx, call)
+	start := time.Now()
+	var res interface{}
+	callErr := ag.guardUntrusted(ctx, call)
+	if callErr == nil {
+		res, callErr = ag.tools.ExecuteToolCall(ctx, restoreCall(ctx, call))
+	}
+	elapsed := time.Since(start)
 	callMsg := &schema.Message{
 		Role:       schema.Tool,
 		ToolName:   call.Function.Name,
@@ -145,6 +158,13 @@ func (ag *Agent) buildToolResultMessage(ctx context.Context, call *schema.ToolCa
 			callMsg.Content = jsonStr
 		}
 	}
+	callMsg.Content = redact.Scrub(ctx, callMsg.Content)
+	// Results of untrusted sources are fenced off and taint the turn.
+	var level string
+	if callMsg.Content, level = ag.fenceUntrusted(ctx, call, callMsg.Content, callErr); level != "" {
+		callMsg.Extra = map[string]any{taintExtraKey: level}
+	}
+	ag.auditToolCall(ctx, call, callMsg.Content, level, callErr, start, elapsed)
 	return callMsg
 }
 
@@ -168,7 +188,7 @@ func (ag *Agent) generateSummary(
 	})
 	summaryMsgs = append(summaryMsgs, truncated...)
 
-	resp, err := p.Generate(ctx, modelSpec.ModelName, summaryMsgs)
+	resp, err := ag.generate(ctx, p, modelSpec, usage.PurposeCompact, summaryMsgs)
 	if err != nil {
 		logs.CtxWarn(ctx, "[agent:%s] summary generation failed: %v", ag.id, err)
 		return nil
@@ -0,0 +1,31 @@
+package agent
+
+import (
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/security/egress"
+)
+
+// newEgressPolicy builds the policy the web tools of this agent connect
+// through: the top-level egress settings, with each field the agent sets
+// replacing the shared one.
+func newEgressPolicy(shared, own config.EgressConfig) (*egress.Policy, error) {
+	cfg := shared
+	if own.Allow != nil {
+		cfg.Allow = own.Allow
+	}
+	if own.Deny != nil {
+		cfg.Deny = own.Deny
+	}
+	if own.AllowPrivate != nil {
+		cfg.AllowPrivate = own.AllowPrivate
+	}
+	if own.AllowNetworks != nil {
+		cfg.AllowNetworks = own.AllowNetworks
+	}
+	return egress.New(egress.Options{
+		Allow:         cfg.Allow,
+		Deny:          cfg.Deny,
+		AllowPrivate:  cfg.AllowPrivate != nil && *cfg.AllowPrivate,
+		AllowNetworks: cfg.AllowNetworks,
+	})
+}
@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/tgifai/friday/internal/provider"
+)
+
+// turnProgress is the work a turn has done so far. When a model fails
+// mid-turn, the next model picks up from it instead of starting over, so
+// tool calls that already ran are neither lost nor repeated.
+type turnProgress struct {
+	msgs     []*schema.Message // tool-call turns completed so far
+	iter     int               // model calls made for them
+	provType provider.Type     // provider that produced msgs
+}
+
+// portableToolCallID matches call IDs every provider accepts: OpenAI caps
+// them at 40 characters, Anthropic allows only letters, digits, _ and -.
+var portableToolCallID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,40}$`)
+
+// resumeMessages copies the tool-call turns of progress into a form the
+// provider type to accepts. Call IDs that are empty or that to would
+// reject are replaced, and the tool results are pointed at the new IDs.
+// Reasoning and signatures are dropped when the provider type changes,
+// since they are only valid for the provider that made them.
+func resumeMessages(progress *turnProgress, to provider.Type) []*schema.Message {
+	foreign := progress.provType != to
+	out := make([]*schema.Message, 0, len(progress.msgs))
+	var ids map[string]string // old to new ID, for the last tool-call turn
+	var order []string        // new IDs of the last tool-call turn
+	var results int           // tool results seen since that turn
+	for i, m := range progress.msgs {
+		c := *m
+		switch c.Role {
+		case schema.Assistant:
+			if foreign {
+				c.ReasoningContent = ""
+				c.Extra = nil
+			}
+			ids, order, results = make(map[string]string, len(c.ToolCalls)), nil, 0
+			c.ToolCalls = make([]schema.ToolCall, len(m.ToolCalls))
+			for j, call := range m.ToolCalls {
+				if !portableToolCallID.MatchString(call.ID) {
+					call.ID = fmt.Sprintf("call_%d_%d", i, j)
+				}
+				if call.Type == "" {
+					call.Type = "function"
+				}
+				if foreign {
+					call.Extra = nil
+				}
+				ids[m.ToolCalls[j].ID] = call.ID
+				order = append(order, call.ID)
+				c.ToolCalls[j] = call
+			}
+		case schema.Tool:
+			// Results name their call by ID; some providers give calls no
+			// ID, so results are then matched by position.
+			if id, ok := ids[c.ToolCallID]; ok && c.ToolCallID != "" {
+				c.ToolCallID = id
+			} else if results < len(order) {
+				c.ToolCallID = order[results]
+			}
+			results++
+		}
+		out = append(out, &c)
+	}
+	return out
+}
+
+// cliResumeMessage returns userMsg with the tool calls of progress written
+// out as text. CLI providers run their own tools and only read the text of
+// user messages, so this is how they learn what the turn has done.
+func cliResumeMessage(userMsg *schema.Message, progress *turnProgress) *schema.Message {
+	var b strings.Builder
+	b.WriteString("\n\n[Steps already taken for this message by another model; do not repeat them]\n")
+	for _, m := range progress.msgs {
+		switch m.Role {
+		case schema.Assistant:
+			if text := strings.TrimSpace(m.Content); text != "" {
+				b.WriteString(text + "\n")
+			}
+			for _, call := range m.ToolCalls {
+				fmt.Fprintf(&b, "- called %s %s\n", call.Function.Name, call.Function.Arguments)
+			}
+		case schema.Tool:
+			fmt.Fprintf(&b, "- %s returned: %s\n", m.ToolName, m.Content)
+		}
+	}
+
+	c := *userMsg
+	c.Content += b.String()
+	return &c
+}
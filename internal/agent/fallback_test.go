@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/tgifai/friday/internal/provider"
+)
+
+func TestResumeMessages(t *testing.T) {
+	progress := &turnProgress{provType: provider.Gemini, iter: 2, msgs: []*schema.Message{
+		{Role: schema.Assistant, ReasoningContent: "thinking", Extra: map[string]any{"gemini_thought_signature": []byte("sig")},
+			ToolCalls: []schema.ToolCall{
+				{ID: "functions.read:0", Function: schema.FunctionCall{Name: "read", Arguments: "{}"}, Extra: map[string]any{"sig": 1}},
+				{ID: "call_ok", Type: "function", Function: schema.FunctionCall{Name: "list", Arguments: "{}"}},
+			}},
+		{Role: schema.Tool, ToolName: "list", ToolCallID: "call_ok", Content: "b"},
+		{Role: schema.Tool, ToolName: "read", ToolCallID: "functions.read:0", Content: "a"},
+		{Role: schema.Assistant, ToolCalls: []schema.ToolCall{
+			{Function: schema.FunctionCall{Name: "read", Arguments: "{}"}},
+			{Function: schema.FunctionCall{Name: "read", Arguments: "{}"}},
+		}},
+		{Role: schema.Tool, ToolName: "read", Content: "c"},
+		{Role: schema.Tool, ToolName: "read", Content: "d"},
+	}}
+
+	msgs := resumeMessages(progress, provider.Anthropic)
+	calls := msgs[0].ToolCalls
+	if calls[0].ID != "call_0_0" || calls[0].Type != "function" || calls[1].ID != "call_ok" {
+		t.Fatalf("call IDs not adapted: %+v", calls)
+	}
+	if msgs[0].ReasoningContent != "" || msgs[0].Extra != nil || calls[0].Extra != nil {
+		t.Errorf("reasoning of another provider kept: %+v", msgs[0])
+	}
+	if msgs[1].ToolCallID != "call_ok" || msgs[2].ToolCallID != "call_0_0" {
+		t.Errorf("results not matched by ID: %s, %s", msgs[1].ToolCallID, msgs[2].ToolCallID)
+	}
+	if msgs[4].ToolCallID != "call_3_0" || msgs[5].ToolCallID != "call_3_1" {
+		t.Errorf("results without IDs not matched by position: %s, %s", msgs[4].ToolCallID, msgs[5].ToolCallID)
+	}
+	if progress.msgs[0].ToolCalls[0].ID != "functions.read:0" || progress.msgs[2].ToolCallID != "functions.read:0" {
+		t.Errorf("progress modified")
+	}
+
+	if msgs := resumeMessages(progress, provider.Gemini); msgs[0].ReasoningContent != "thinking" || msgs[0].Extra == nil {
+		t.Errorf("reasoning dropped for the same provider type")
+	}
+}
+
+func TestCLIResumeMessage(t *testing.T) {
+	userMsg := &schema.Message{Role: schema.User, Content: "summarize the page"}
+	progress := &turnProgress{msgs: []*schema.Message{
+		{Role: schema.Assistant, Content: "Fetching it.", ToolCalls: []schema.ToolCall{
+			{ID: "1", Function: schema.FunctionCall{Name: "web_fetch", Arguments: `{"url":"https://example.com"}`}},
+		}},
+		{Role: schema.Tool, ToolName: "web_fetch", ToolCallID: "1", Content: "Example Domain"},
+	}}
+
+	got := cliResumeMessage(userMsg, progress).Content
+	for _, want := range []string{"summarize the page", "Fetching it.", `called web_fetch {"url":"https://example.com"}`, "web_fetch returned: Example Domain"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("resume message lacks %q:\n%s", want, got)
+		}
+	}
+	if userMsg.Content != "summarize the page" {
+		t.Errorf("user message modified")
+	}
+}
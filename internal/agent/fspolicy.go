@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"os"
+	"strings"
+
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/security/fspolicy"
+	"github.com/tgifai/friday/internal/security/secrets"
+)
+
+// newFSPolicy builds the filesystem policy of the file tools and command
+// working directories. Without read_write roots the workspace is writable.
+// Paths from FRIDAY_ALLOWED_PATHS (macOS sandbox bookmarks) are added as
+// writable roots, and the secrets store and its key file are always
+// denied.
+func newFSPolicy(workspace string, cfg config.FilesystemConfig) (*fspolicy.Policy, error) {
+	readWrite := cfg.ReadWrite
+	if len(readWrite) == 0 {
+		readWrite = []string{workspace}
+	}
+	if paths := os.Getenv("FRIDAY_ALLOWED_PATHS"); paths != "" {
+		for _, p := range strings.Split(paths, ":") {
+			if p = strings.TrimSpace(p); p != "" {
+				readWrite = append(readWrite, p)
+			}
+		}
+	}
+	deny := append([]string{secrets.DefaultPath(), secrets.KeyOptionsFromEnv().KeyFile}, cfg.Deny...)
+
+	return fspolicy.New(fspolicy.Options{
+		Base:           workspace,
+		ReadOnly:       cfg.ReadOnly,
+		ReadWrite:      readWrite,
+		Deny:           deny,
+		MaxReadBytes:   cfg.MaxReadBytes,
+		MaxWriteBytes:  cfg.MaxWriteBytes,
+		RefuseSymlinks: cfg.Symlinks == consts.SymlinksRefuse,
+	})
+}
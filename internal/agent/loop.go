@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bytedance/sonic"
@@ -11,23 +12,34 @@ import (
 	"github.com/cloudwego/eino/schema"
 
 	"github.com/tgifai/friday/internal/agent/session"
+	"github.com/tgifai/friday/internal/agent/tool"
 	"github.com/tgifai/friday/internal/channel"
 	"github.com/tgifai/friday/internal/config"
 	"github.com/tgifai/friday/internal/pkg/logs"
 	"github.com/tgifai/friday/internal/provider"
+	"github.com/tgifai/friday/internal/security/taint"
+	"github.com/tgifai/friday/internal/usage"
 )
 
 const (
-	defaultMaxIterations = 25
+	defaultMaxIterations     = 25
+	defaultParallelToolCalls = 4
 
 	loopNotifyDebounce = time.Second * 3
 )
 
-func (ag *Agent) runLoop(ctx context.Context, p provider.Provider, modelSpec *provider.ModelSpec, sess *session.Session, msg *channel.Message, cfg config.AgentRuntimeConfig) (*channel.Response, error) {
+// runLoop runs the turn of msg on one model. It resumes from progress,
+// the work an earlier model did before it failed, and records its own
+// work there so a fallback model can take over if this one fails too.
+func (ag *Agent) runLoop(ctx context.Context, p provider.Provider, modelSpec *provider.ModelSpec, sess *session.Session, msg *channel.Message, cfg config.AgentRuntimeConfig, progress *turnProgress) (*channel.Response, error) {
 	// Inject session into context so CLI providers can access metadata.
 	ctx = session.WithContext(ctx, sess)
 	promptMsgs := ag.buildMessages(ctx, sess, msg, p.Type())
-	userMsg := buildUserMessage(msg)
+	userMsg := buildUserMessage(msg, p.Type())
+	scrubMessage(ctx, userMsg)
+	if msg.ID != "" {
+		userMsg.Extra = map[string]any{session.MessageIDKey: msg.ID}
+	}
 
 	// Check token budget and compact if needed.
 	promptMsgs = ag.maybeCompact(ctx, p, modelSpec, sess, promptMsgs, userMsg)
@@ -37,21 +49,36 @@ func (ag *Agent) runLoop(ctx context.Context, p provider.Provider, modelSpec *pr
 	if cfg.MaxIterations > 0 {
 		maxIterations = cfg.MaxIterations
 	}
+	parallel := defaultParallelToolCalls
+	if cfg.ParallelToolCalls > 0 {
+		parallel = cfg.ParallelToolCalls
+	}
 
 	logs.CtxDebug(ctx, "[agent:%s] sending to provider %s:%s, messages count: %d, max_iterations: %d",
 		ag.id, modelSpec.ProviderID, modelSpec.ModelName, len(promptMsgs), maxIterations)
 
 	var finalMsg *schema.Message
 	msgs := make([]*schema.Message, 0, 4)
-	notifier := &loopNotifier{agent: ag, chatID: msg.ChatID}
+	var carried []*schema.Message // tool-call turns kept out of a CLI prompt
+	if len(progress.msgs) > 0 {
+		logs.CtxInfo(ctx, "[agent:%s] resuming turn on %s:%s after %d messages from a failed model",
+			ag.id, modelSpec.ProviderID, modelSpec.ModelName, len(progress.msgs))
+		if p.Type() == provider.CLI {
+			promptMsgs[len(promptMsgs)-1] = cliResumeMessage(userMsg, progress)
+			carried = progress.msgs
+		} else {
+			msgs = resumeMessages(progress, p.Type())
+		}
+	}
+	notifier := &loopNotifier{agent: ag, chatID: msg.ChatID, threadID: msg.ThreadID, originID: msg.ID}
 	notifier.channel, _ = channel.Get(msg.ChannelID)
 
 	var opts []model.Option
 	if cfg.Temperature > 0 {
 		opts = append(opts, model.WithTemperature(float32(cfg.Temperature)))
 	}
-	for iter := 0; iter < maxIterations; iter++ {
-		llmResp, err := p.Generate(ctx, modelSpec.ModelName, append(promptMsgs, msgs...), opts...)
+	for iter := progress.iter; iter < maxIterations; iter++ {
+		llmResp, err := ag.generate(ctx, p, modelSpec, usage.PurposeTurn, append(promptMsgs, msgs...), opts...)
 		if err != nil {
 			logs.CtxWarn(ctx, "[agent:%s] LLM call to %s:%s failed: %v", ag.id, modelSpec.ProviderID, modelSpec.ModelName, err)
 			return nil, err
@@ -60,6 +87,7 @@ func (ag *Agent) runLoop(ctx context.Context, p provider.Provider, modelSpec *pr
 			logs.CtxWarn(ctx, "[agent:%s] LLM call to %s:%s returned empty response", ag.id, modelSpec.ProviderID, modelSpec.ModelName)
 			return nil, fmt.Errorf("LLM returned empty response from %s:%s", modelSpec.ProviderID, modelSpec.ModelName)
 		}
+		scrubMessage(ctx, llmResp)
 
 		if logs.DefaultLogger().GetLevel() <= logs.DebugLevel {
 			str, _ := sonic.MarshalString(llmResp)
@@ -68,14 +96,8 @@ func (ag *Agent) runLoop(ctx context.Context, p provider.Provider, modelSpec *pr
 		if len(llmResp.ToolCalls) > 0 {
 			notifier.send(ctx, llmResp.Content, llmResp.ReasoningContent)
 			msgs = append(msgs, llmResp)
-			for _, call := range llmResp.ToolCalls {
-				logs.CtxDebug(ctx, "[agent:%s:%d] call: %+v", ag.id, iter, call)
-				callMsg := ag.buildToolResultMessage(ctx, &call)
-				if callMsg.Content != "" && strings.HasPrefix(callMsg.Content, "ERROR: ") {
-					logs.CtxWarn(ctx, "[agent:%s] tool %q (call_id=%s) failed: %s", ag.id, call.Function.Name, call.ID, callMsg.Content)
-				}
-				msgs = append(msgs, callMsg)
-			}
+			msgs = append(msgs, ag.executeToolCalls(ctx, iter, llmResp.ToolCalls, parallel)...)
+			progress.msgs, progress.iter, progress.provType = msgs, iter+1, p.Type()
 			continue
 		}
 
@@ -87,11 +109,12 @@ func (ag *Agent) runLoop(ctx context.Context, p provider.Provider, modelSpec *pr
 		// Iteration limit reached — ask LLM to summarize progress without tools.
 		logs.CtxWarn(ctx, "[agent:%s] iteration limit (%d) reached, requesting summary", ag.id, maxIterations)
 		finalMsg = ag.runLoopSummary(ctx, p, modelSpec, append(promptMsgs, msgs...))
+		scrubMessage(ctx, finalMsg)
 	}
 
 	// Commit user message, tool-call turns, and the final response to session.
 	sess.Append(userMsg)
-	for _, m := range msgs {
+	for _, m := range append(carried, msgs...) {
 		sess.Append(m)
 	}
 	sess.Append(finalMsg)
@@ -104,6 +127,53 @@ func (ag *Agent) runLoop(ctx context.Context, p provider.Provider, modelSpec *pr
 	}, nil
 }
 
+// executeToolCalls runs the tool calls of one model response and returns
+// their result messages in call order, as providers expect them.
+// Consecutive calls to parallel-safe tools run concurrently, at most limit
+// at a time. Any other call waits for the calls before it and runs alone,
+// so side effects and taint tracking follow the order the model chose.
+func (ag *Agent) executeToolCalls(ctx context.Context, iter int, calls []schema.ToolCall, limit int) []*schema.Message {
+	results := make([]*schema.Message, len(calls))
+	run := func(i int) {
+		call := &calls[i]
+		logs.CtxDebug(ctx, "[agent:%s:%d] call: %+v", ag.id, iter, *call)
+		callMsg := ag.buildToolResultMessage(ctx, call)
+		if callMsg.Content != "" && strings.HasPrefix(callMsg.Content, "ERROR: ") {
+			logs.CtxWarn(ctx, "[agent:%s] tool %q (call_id=%s) failed: %s", ag.id, call.Function.Name, call.ID, callMsg.Content)
+		}
+		results[i] = callMsg
+	}
+
+	sem := make(chan struct{}, max(limit, 1))
+	var wg sync.WaitGroup
+	for i := range calls {
+		if limit <= 1 || !ag.runsInParallel(ctx, calls[i].Function.Name) {
+			wg.Wait()
+			run(i)
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			run(i)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// runsInParallel reports whether a call to name may run alongside other
+// calls. Tools the taint policy can restrict never do, so they see the
+// taint left by every call before them.
+func (ag *Agent) runsInParallel(ctx context.Context, name string) bool {
+	if turn := untrustedTurnFrom(ctx); turn != nil && turn.policy.Restricts(name, taint.LevelSuspicious) {
+		return false
+	}
+	return ag.tools.ParallelSafe(name)
+}
+
 // runLoopSummary makes one final LLM call without tools to summarize what has
 // been accomplished and what remains when the iteration limit is exceeded.
 func (ag *Agent) runLoopSummary(ctx context.Context,
@@ -116,7 +186,7 @@ func (ag *Agent) runLoopSummary(ctx context.Context,
 		Content: "You have reached the maximum iteration limit. Please summarize what you have accomplished so far and what still remains to be done.",
 	})
 
-	resp, err := p.Generate(ctx, modelSpec.ModelName, msgs)
+	resp, err := ag.generate(ctx, p, modelSpec, usage.PurposeSummary, msgs)
 	if err != nil || resp == nil {
 		logs.CtxWarn(ctx, "[agent:%s] summary generation failed: %v", ag.id, err)
 		return &schema.Message{
@@ -127,10 +197,33 @@ func (ag *Agent) runLoopSummary(ctx context.Context,
 	return resp
 }
 
+// generate calls the model and records the usage it reports in the usage
+// ledger. Every model call made on behalf of the agent goes through here.
+// When the caller's role restricts the tools, only the permitted ones are
+// offered to the model.
+func (ag *Agent) generate(ctx context.Context,
+	p provider.Provider,
+	modelSpec *provider.ModelSpec,
+	purpose string,
+	msgs []*schema.Message,
+	opts ...model.Option,
+) (*schema.Message, error) {
+	if tool.FilterFrom(ctx) != nil {
+		opts = append(opts, model.WithTools(ag.tools.ListToolInfosFor(ctx)))
+	}
+	resp, err := p.Generate(ctx, modelSpec.ModelName, msgs, opts...)
+	if err == nil {
+		usage.Track(ctx, purpose, modelSpec.ProviderID, modelSpec.ModelName, resp)
+	}
+	return resp, err
+}
+
 type loopNotifier struct {
 	agent    *Agent
 	channel  channel.Channel
 	chatID   string
+	threadID string
+	originID string
 	lastSend time.Time
 }
 
@@ -144,7 +237,7 @@ func (n *loopNotifier) send(ctx context.Context, content, reasoning string) {
 		return
 	}
 	if now := time.Now(); now.Sub(n.lastSend) >= loopNotifyDebounce {
-		if err := n.channel.SendMessage(ctx, n.chatID, content); err != nil {
+		if err := n.channel.SendMessage(ctx, n.chatID, content, channel.WithThread(n.threadID), channel.WithOrigin(n.originID)); err != nil {
 			logs.CtxDebug(ctx, "[agent:%s] progress notify failed: %v", n.agent.id, err)
 			return
 		}
@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/tgifai/friday/internal/agent/session"
+	"github.com/tgifai/friday/internal/agent/tool"
+	"github.com/tgifai/friday/internal/channel"
+	"github.com/tgifai/friday/internal/config"
+)
+
+// traceTool records how many of its calls run at once and the order in
+// which they finish.
+type traceTool struct {
+	name     string
+	parallel bool
+	trace    *callTrace
+}
+
+type callTrace struct {
+	mu        sync.Mutex
+	active    int
+	maxActive int
+	finished  []string
+}
+
+func (t *traceTool) Name() string               { return t.name }
+func (t *traceTool) Description() string        { return t.name }
+func (t *traceTool) ParallelSafe() bool         { return t.parallel }
+func (t *traceTool) ToolInfo() *schema.ToolInfo { return &schema.ToolInfo{Name: t.name} }
+func (t *traceTool) Execute(_ context.Context, args map[string]interface{}) (interface{}, error) {
+	tr := t.trace
+	tr.mu.Lock()
+	tr.active++
+	tr.maxActive = max(tr.maxActive, tr.active)
+	tr.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	tr.mu.Lock()
+	tr.active--
+	tr.finished = append(tr.finished, args["id"].(string))
+	tr.mu.Unlock()
+	return args["id"], nil
+}
+
+func traceCalls(names ...string) []schema.ToolCall {
+	calls := make([]schema.ToolCall, len(names))
+	for i, name := range names {
+		id := fmt.Sprintf("%s%d", name, i)
+		calls[i] = schema.ToolCall{ID: id, Function: schema.FunctionCall{Name: name, Arguments: `{"id":"` + id + `"}`}}
+	}
+	return calls
+}
+
+func TestExecuteToolCalls_Parallel(t *testing.T) {
+	t.Setenv("FRIDAY_HOME", t.TempDir())
+	trace := &callTrace{}
+	ag := &Agent{id: "test", tools: tool.NewRegistry(
+		&traceTool{name: "read", parallel: true, trace: trace},
+		&traceTool{name: "write", trace: trace},
+	)}
+	calls := traceCalls("read", "read", "read", "write", "read")
+
+	results := ag.executeToolCalls(context.Background(), 0, calls, 2)
+	for i, res := range results {
+		if res.ToolCallID != calls[i].ID || res.Content != `"`+calls[i].ID+`"` {
+			t.Fatalf("result %d = %s %s, want %s in call order", i, res.ToolCallID, res.Content, calls[i].ID)
+		}
+	}
+	if trace.maxActive != 2 {
+		t.Errorf("%d calls ran at once, want the limit of 2", trace.maxActive)
+	}
+	// The write waits for every read before it and the last read waits
+	// for the write.
+	if got := trace.finished[3:]; got[0] != "write3" || got[1] != "read4" {
+		t.Errorf("finish order %v, want write3 then read4 last", trace.finished)
+	}
+
+	trace.maxActive = 0
+	ag.executeToolCalls(context.Background(), 0, calls, 1)
+	if trace.maxActive != 1 {
+		t.Errorf("%d calls ran at once with a limit of 1", trace.maxActive)
+	}
+}
+
+func TestExecuteToolCalls_RestrictedToolsRunAlone(t *testing.T) {
+	t.Setenv("FRIDAY_HOME", t.TempDir())
+	trace := &callTrace{}
+	ag := &Agent{id: "test", tools: tool.NewRegistry(
+		&traceTool{name: "web_fetch", parallel: true, trace: trace},
+		&traceTool{name: "read", parallel: true, trace: trace},
+	)}
+	cfg := config.UntrustedConfig{Restrict: []string{"read"}}
+	ctx := withUntrustedTurn(context.Background(), cfg, &session.Session{}, &channel.Message{})
+
+	ag.executeToolCalls(ctx, 0, traceCalls("web_fetch", "read"), 4)
+	if trace.maxActive != 1 {
+		t.Errorf("a tool the taint policy restricts ran alongside an untrusted source")
+	}
+}
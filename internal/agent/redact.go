@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/tgifai/friday/internal/security/redact"
+)
+
+// scrubMessage replaces the secrets in the text of m with the turn's
+// placeholders, so neither the model, the chat nor the session sees them.
+func scrubMessage(ctx context.Context, m *schema.Message) {
+	if m == nil {
+		return
+	}
+	m.Content = redact.Scrub(ctx, m.Content)
+	m.ReasoningContent = redact.Scrub(ctx, m.ReasoningContent)
+	for i, part := range m.UserInputMultiContent {
+		if part.Type == schema.ChatMessagePartTypeText {
+			m.UserInputMultiContent[i].Text = redact.Scrub(ctx, part.Text)
+		}
+	}
+	for i, call := range m.ToolCalls {
+		m.ToolCalls[i].Function.Arguments = redact.Scrub(ctx, call.Function.Arguments)
+	}
+}
+
+// restoreCall returns call with the placeholders in its arguments mapped
+// back to the secrets, for executing it. The call kept in the session and
+// the audit log is left scrubbed.
+func restoreCall(ctx context.Context, call *schema.ToolCall) *schema.ToolCall {
+	args := redact.TurnFrom(ctx).RestoreJSON(call.Function.Arguments)
+	if args == call.Function.Arguments {
+		return call
+	}
+	restored := *call
+	restored.Function.Arguments = args
+	return &restored
+}
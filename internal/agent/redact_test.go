@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/tgifai/friday/internal/agent/tool"
+	"github.com/tgifai/friday/internal/security/audit"
+	"github.com/tgifai/friday/internal/security/redact"
+)
+
+// echoTool returns its "text" argument and remembers it.
+type echoTool struct{ got string }
+
+func (t *echoTool) Name() string               { return "echo" }
+func (t *echoTool) Description() string        { return "echo" }
+func (t *echoTool) ParallelSafe() bool         { return false }
+func (t *echoTool) ToolInfo() *schema.ToolInfo { return &schema.ToolInfo{Name: "echo"} }
+func (t *echoTool) Execute(_ context.Context, args map[string]interface{}) (interface{}, error) {
+	t.got, _ = args["text"].(string)
+	return t.got, nil
+}
+
+func TestRedact_ToolRoundTrip(t *testing.T) {
+	t.Setenv("FRIDAY_HOME", t.TempDir())
+	echo := &echoTool{}
+	ag := &Agent{id: "test", tools: tool.NewRegistry(echo)}
+	r, err := redact.New(redact.Options{Values: map[string]string{"db": "db-pass-0123"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := redact.WithTurn(context.Background(), r.NewTurn())
+
+	// The model saw the password as a placeholder and uses it in a call.
+	user := &schema.Message{Role: schema.User, Content: "connect with db-pass-0123"}
+	scrubMessage(ctx, user)
+	if user.Content != "connect with [REDACTED:db#1]" {
+		t.Fatalf("user message = %q", user.Content)
+	}
+
+	call := &schema.ToolCall{ID: "c1", Function: schema.FunctionCall{Name: "echo", Arguments: `{"text":"pw=[REDACTED:db#1]"}`}}
+	res := ag.buildToolResultMessage(ctx, call)
+	if echo.got != "pw=db-pass-0123" {
+		t.Fatalf("tool got %q, want the restored secret", echo.got)
+	}
+	if strings.Contains(res.Content, "db-pass-0123") || !strings.Contains(res.Content, "[REDACTED:db#1]") {
+		t.Fatalf("tool result not scrubbed: %q", res.Content)
+	}
+	if strings.Contains(call.Function.Arguments, "db-pass-0123") {
+		t.Fatalf("call arguments kept the secret: %q", call.Function.Arguments)
+	}
+}
+
+// failTool fails with an error that echoes its "text" argument.
+type failTool struct{}
+
+func (failTool) Name() string               { return "fail" }
+func (failTool) Description() string        { return "fail" }
+func (failTool) ParallelSafe() bool         { return false }
+func (failTool) ToolInfo() *schema.ToolInfo { return &schema.ToolInfo{Name: "fail"} }
+func (failTool) Execute(_ context.Context, args map[string]interface{}) (interface{}, error) {
+	return nil, errors.New("bad input: " + args["text"].(string))
+}
+
+func TestRedact_ToolErrorAudited(t *testing.T) {
+	t.Setenv("FRIDAY_HOME", t.TempDir())
+	ag := &Agent{id: "test-redact-error", tools: tool.NewRegistry(failTool{})}
+	r, err := redact.New(redact.Options{Values: map[string]string{"db": "db-pass-0123"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := redact.WithTurn(context.Background(), r.NewTurn())
+	redact.Scrub(ctx, "db-pass-0123") // the model sees the placeholder
+
+	call := &schema.ToolCall{ID: "c1", Function: schema.FunctionCall{Name: "fail", Arguments: `{"text":"[REDACTED:db#1]"}`}}
+	ag.buildToolResultMessage(ctx, call)
+
+	entries, err := audit.Default().Query(audit.Filter{AgentID: ag.id})
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("audit entries = %d, %v", len(entries), err)
+	}
+	got := entries[len(entries)-1].Error
+	if strings.Contains(got, "db-pass-0123") || !strings.Contains(got, "[REDACTED:db#1]") {
+		t.Errorf("audited error not scrubbed: %q", got)
+	}
+}
@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/tgifai/friday/internal/security/sandbox"
+	"github.com/tgifai/friday/internal/security/secrets"
+)
+
+// newSandboxes builds the executor of each sandboxed command tool, keyed by
+// tool name. Tools without an entry run commands directly. A backbone that
+// cannot be built fails the agent rather than leaving its tools
+// unsandboxed.
+func newSandboxes(workspace string, cfg sandbox.SandboxConfig) (map[string]sandbox.Executor, error) {
+	token, err := secrets.Expand(cfg.GoJudge.AuthToken)
+	if err != nil {
+		return nil, fmt.Errorf("go_judge.auth_token: %w", err)
+	}
+	cfg.GoJudge.AuthToken = token
+
+	out := make(map[string]sandbox.Executor)
+	for _, name := range sandbox.Tools {
+		executor, ok, err := sandbox.NewExecutorForTool(workspace, cfg, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if ok {
+			out[name] = executor
+		}
+	}
+	return out, nil
+}
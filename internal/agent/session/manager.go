@@ -46,7 +46,7 @@ func (m *Manager) AgentID() string {
 }
 
 func (m *Manager) BuildKey(channelType channel.Type, channelID string, chatID string) string {
-	return GenerateKey(m.agentID, channelType, channelID, chatID)
+	return GenerateKey(Key{AgentID: m.agentID, ChannelType: channelType, ChannelID: channelID, ChatID: chatID})
 }
 
 func (m *Manager) GetOrCreateFor(channelType channel.Type, channelID string, chatID string) *Session {
@@ -90,11 +90,13 @@ func (m *Manager) Create(sessKey string) *Session {
 		createTime: timeNow,
 		updateTime: timeNow,
 	}
-	if agentID, channelType, channelID, chatID, err := ParseKey(sessKey); err == nil {
-		sess.AgentID = agentID
-		sess.Channel = channelType
-		sess.ChannelID = channelID
-		sess.ChatID = chatID
+	if k, err := ParseKey(sessKey); err == nil {
+		sess.AgentID = k.AgentID
+		sess.Channel = k.ChannelType
+		sess.ChannelID = k.ChannelID
+		sess.ChatID = k.ChatID
+		sess.ThreadID = k.ThreadID
+		sess.UserID = k.UserID
 	}
 
 	actual, _ := m.sessMap.LoadOrStore(sessKey, sess)
@@ -20,6 +20,8 @@ type Session struct {
 	Channel   channel.Type
 	ChannelID string
 	ChatID    string
+	ThreadID  string
+	UserID    string
 	messages  []*schema.Message
 	metadata  map[string]string
 
@@ -124,20 +126,111 @@ func (s *Session) SetMeta(key, value string) {
 	s.markMutationLocked()
 }
 
+// MessageIDKey tags a user message (schema.Message.Extra) with the channel
+// message it came from, so the turn can be found again on edit or recall.
+const MessageIDKey = "message_id"
+
+// RemoveTurn deletes the user message tagged with messageID together with the
+// tool calls and replies that followed it, up to the next user message. It
+// reports whether the turn was found.
+func (s *Session) RemoveTurn(messageID string) bool {
+	if messageID == "" {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := -1
+	for i, m := range s.messages {
+		if id, _ := m.Extra[MessageIDKey].(string); m.Role == schema.User && id == messageID {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return false
+	}
+	end := start + 1
+	for end < len(s.messages) && s.messages[end].Role != schema.User {
+		end++
+	}
+
+	s.messages = append(s.messages[:start], s.messages[end:]...)
+	s.msgCnt -= int64(end - start)
+	if s.msgCnt < 0 {
+		s.msgCnt = 0
+	}
+	s.updateTime = time.Now()
+	// Reset persisted state so the next Save rewrites the file without the turn.
+	s.persistedMsgLen = 0
+	s.markMutationLocked()
+	return true
+}
+
 func (s *Session) markMutationLocked() {
 	s.dirty = true
 	s.version++
 }
 
-func GenerateKey(agentID string, channelType channel.Type, channelID string, chatID string) string {
-	return fmt.Sprintf(sessKeyTpl, agentID, string(channelType), channelID, chatID)
+// Key identifies a conversation. The first five parts form the legacy key
+// "agent:<agentId>:<channel>:<channelId>:<chatId>"; optional scopes are
+// appended as ":<kind>:<id>" pairs so keys without them stay unchanged and
+// existing JSONL sessions keep resolving.
+type Key struct {
+	AgentID     string
+	ChannelType channel.Type
+	ChannelID   string
+	ChatID      string
+	ThreadID    string // forum topic / thread; empty when threads share the chat session
+	UserID      string // set when each user gets a private session
+}
+
+const (
+	keyScopeThread = "thread"
+	keyScopeUser   = "user"
+)
+
+// AnyChat is the chat segment of keys for sessions that follow a user across
+// every chat of a channel.
+const AnyChat = "*"
+
+func (k Key) String() string {
+	key := fmt.Sprintf(sessKeyTpl, k.AgentID, string(k.ChannelType), k.ChannelID, k.ChatID)
+	if k.ThreadID != "" {
+		key += ":" + keyScopeThread + ":" + k.ThreadID
+	}
+	if k.UserID != "" {
+		key += ":" + keyScopeUser + ":" + k.UserID
+	}
+	return key
+}
+
+func GenerateKey(k Key) string {
+	return k.String()
 }
 
-func ParseKey(sessionKey string) (agentID string, channelType channel.Type, channelID string, chatID string, err error) {
+func ParseKey(sessionKey string) (Key, error) {
 	parts := strings.Split(sessionKey, ":")
-	if len(parts) != 5 || parts[0] != "agent" {
-		return "", "", "", "", fmt.Errorf("invalid session key format: %s (expected agent:<agentId>:<channel>:<channelId>:<chatId>)", sessionKey)
+	if len(parts) < 5 || len(parts)%2 == 0 || parts[0] != "agent" {
+		return Key{}, fmt.Errorf("invalid session key format: %s (expected agent:<agentId>:<channel>:<channelId>:<chatId>[:<scope>:<id>...])", sessionKey)
 	}
 
-	return parts[1], channel.Type(parts[2]), parts[3], parts[4], nil
+	k := Key{
+		AgentID:     parts[1],
+		ChannelType: channel.Type(parts[2]),
+		ChannelID:   parts[3],
+		ChatID:      parts[4],
+	}
+	for i := 5; i < len(parts); i += 2 {
+		switch parts[i] {
+		case keyScopeThread:
+			k.ThreadID = parts[i+1]
+		case keyScopeUser:
+			k.UserID = parts[i+1]
+		default:
+			return Key{}, fmt.Errorf("invalid session key scope %q in %s", parts[i], sessionKey)
+		}
+	}
+	return k, nil
 }
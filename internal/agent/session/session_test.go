@@ -0,0 +1,82 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/tgifai/friday/internal/channel"
+)
+
+func TestKey_LegacyFormatUnchanged(t *testing.T) {
+	k := Key{AgentID: "a1", ChannelType: channel.Telegram, ChannelID: "tg", ChatID: "-100"}
+	if got, want := GenerateKey(k), "agent:a1:telegram:tg:-100"; got != want {
+		t.Fatalf("GenerateKey = %q, want %q", got, want)
+	}
+}
+
+func TestKey_RoundTrip(t *testing.T) {
+	cases := []Key{
+		{AgentID: "a1", ChannelType: channel.Telegram, ChannelID: "tg", ChatID: "-100"},
+		{AgentID: "a1", ChannelType: channel.Telegram, ChannelID: "tg", ChatID: "-100", ThreadID: "42"},
+		{AgentID: "a1", ChannelType: channel.Lark, ChannelID: "lk", ChatID: "oc_1", ThreadID: "omt_1"},
+		{AgentID: "a1", ChannelType: channel.Telegram, ChannelID: "tg", ChatID: "-100", UserID: "7"},
+		{AgentID: "a1", ChannelType: channel.Telegram, ChannelID: "tg", ChatID: "-100", ThreadID: "42", UserID: "7"},
+		{AgentID: "a1", ChannelType: channel.Telegram, ChannelID: "tg", ChatID: AnyChat, UserID: "7"},
+	}
+	for _, k := range cases {
+		got, err := ParseKey(k.String())
+		if err != nil {
+			t.Fatalf("ParseKey(%q): %v", k.String(), err)
+		}
+		if got != k {
+			t.Errorf("ParseKey(%q) = %+v, want %+v", k.String(), got, k)
+		}
+	}
+}
+
+func TestParseKey_Invalid(t *testing.T) {
+	for _, key := range []string{
+		"",
+		"agent:a1:telegram:tg",
+		"session:a1:telegram:tg:1",
+		"agent:a1:telegram:tg:1:thread",
+		"agent:a1:telegram:tg:1:bogus:2",
+	} {
+		if _, err := ParseKey(key); err == nil {
+			t.Errorf("ParseKey(%q) succeeded, want error", key)
+		}
+	}
+}
+
+func TestSession_RemoveTurn(t *testing.T) {
+	s := &Session{}
+	tag := func(id string) map[string]any { return map[string]any{MessageIDKey: id} }
+	s.Append(&schema.Message{Role: schema.User, Content: "one", Extra: tag("1")})
+	s.Append(&schema.Message{Role: schema.Assistant, Content: "reply one"})
+	s.Append(&schema.Message{Role: schema.User, Content: "two", Extra: tag("2")})
+	s.Append(&schema.Message{Role: schema.Assistant, ToolCalls: []schema.ToolCall{{ID: "c1"}}})
+	s.Append(&schema.Message{Role: schema.Tool, ToolCallID: "c1", Content: "ok"})
+	s.Append(&schema.Message{Role: schema.Assistant, Content: "reply two"})
+	s.Append(&schema.Message{Role: schema.User, Content: "three", Extra: tag("3")})
+
+	if s.RemoveTurn("missing") {
+		t.Fatal("RemoveTurn(missing) = true")
+	}
+	if !s.RemoveTurn("2") {
+		t.Fatal("RemoveTurn(2) = false")
+	}
+
+	var got []string
+	for _, m := range s.History() {
+		got = append(got, m.Content)
+	}
+	want := []string{"one", "reply one", "three"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("history = %v, want %v", got, want)
+	}
+	if s.MsgCount() != 3 {
+		t.Errorf("MsgCount = %d, want 3", s.MsgCount())
+	}
+}
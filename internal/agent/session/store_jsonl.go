@@ -43,6 +43,8 @@ type jsonlMetadataRecord struct {
 	Channel    string            `json:"channel,omitempty"`
 	ChannelID  string            `json:"channel_id,omitempty"`
 	ChatID     string            `json:"chat_id,omitempty"`
+	ThreadID   string            `json:"thread_id,omitempty"`
+	UserID     string            `json:"user_id,omitempty"`
 	CreatedAt  time.Time         `json:"created_at"`
 	UpdatedAt  time.Time         `json:"updated_at"`
 	ExpireAt   time.Time         `json:"expire_at,omitempty"`
@@ -198,6 +200,8 @@ func (s *jsonlStore) Load(ctx context.Context, sessionKey string) (*Session, err
 		Channel:         channel.Type(meta.Channel),
 		ChannelID:       meta.ChannelID,
 		ChatID:          meta.ChatID,
+		ThreadID:        meta.ThreadID,
+		UserID:          meta.UserID,
 		messages:        msgs,
 		metadata:        meta.Metadata,
 		createTime:      meta.CreatedAt,
@@ -214,19 +218,25 @@ func (s *jsonlStore) Load(ctx context.Context, sessionKey string) (*Session, err
 	}
 
 	if sess.AgentID == "" || sess.Channel == "" || sess.ChannelID == "" || sess.ChatID == "" {
-		agentID, ch, channelID, chatID, parseErr := ParseKey(sessionKey)
+		k, parseErr := ParseKey(sessionKey)
 		if parseErr == nil {
 			if sess.AgentID == "" {
-				sess.AgentID = agentID
+				sess.AgentID = k.AgentID
 			}
 			if sess.Channel == "" {
-				sess.Channel = ch
+				sess.Channel = k.ChannelType
 			}
 			if sess.ChannelID == "" {
-				sess.ChannelID = channelID
+				sess.ChannelID = k.ChannelID
 			}
 			if sess.ChatID == "" {
-				sess.ChatID = chatID
+				sess.ChatID = k.ChatID
+			}
+			if sess.ThreadID == "" {
+				sess.ThreadID = k.ThreadID
+			}
+			if sess.UserID == "" {
+				sess.UserID = k.UserID
 			}
 		}
 	}
@@ -264,6 +274,8 @@ func (s *jsonlStore) Save(ctx context.Context, sess *Session) error {
 		Channel:    string(sess.Channel),
 		ChannelID:  sess.ChannelID,
 		ChatID:     sess.ChatID,
+		ThreadID:   sess.ThreadID,
+		UserID:     sess.UserID,
 		CreatedAt:  sess.createTime,
 		UpdatedAt:  sess.updateTime,
 		ExpireAt:   sess.expireAt,
@@ -453,9 +465,21 @@ func writeMessages(writer *bufio.Writer, messages []*schema.Message) error {
 }
 
 func marshalMessageLine(msg *schema.Message) (string, error) {
+	content := msg.Content
+	if content == "" {
+		// Multimodal user messages carry their text in parts; keep that text
+		// (prompt, transcripts, extracted documents) and drop binary payloads.
+		var texts []string
+		for _, part := range msg.UserInputMultiContent {
+			if part.Type == schema.ChatMessagePartTypeText && part.Text != "" {
+				texts = append(texts, part.Text)
+			}
+		}
+		content = strings.Join(texts, "\n")
+	}
 	trimMsg := &schema.Message{
 		Role:       msg.Role,
-		Content:    msg.Content,
+		Content:    content,
 		ToolCalls:  msg.ToolCalls,
 		ToolCallID: msg.ToolCallID,
 		ToolName:   msg.ToolName,
@@ -137,3 +137,27 @@ func TestJSONLStore_FullHistory_Preserved(t *testing.T) {
 		t.Error("JSONL should contain a compact record")
 	}
 }
+
+func TestMarshalMessageLine_MultiContentText(t *testing.T) {
+	b64 := "AAAA"
+	msg := &schema.Message{
+		Role: schema.User,
+		UserInputMultiContent: []schema.MessageInputPart{
+			{Type: schema.ChatMessagePartTypeText, Text: "look at this"},
+			{Type: schema.ChatMessagePartTypeImageURL, Image: &schema.MessageInputImage{
+				MessagePartCommon: schema.MessagePartCommon{Base64Data: &b64, MIMEType: "image/png"},
+			}},
+			{Type: schema.ChatMessagePartTypeText, Text: "[Voice message transcript] hello"},
+		},
+	}
+	line, err := marshalMessageLine(msg)
+	if err != nil {
+		t.Fatalf("marshalMessageLine: %v", err)
+	}
+	if !strings.Contains(line, `look at this\n[Voice message transcript] hello`) {
+		t.Errorf("text parts not preserved: %s", line)
+	}
+	if strings.Contains(line, b64) {
+		t.Errorf("binary payload should be dropped: %s", line)
+	}
+}
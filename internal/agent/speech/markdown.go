@@ -0,0 +1,102 @@
+package speech
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxSpokenTableRows is the largest table (including the header row) that is
+// read out loud; longer tables are delivered as text.
+const maxSpokenTableRows = 4
+
+var (
+	mdImageRe      = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	mdLinkRe       = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	mdEmphasisRe   = regexp.MustCompile(`(\*\*|__|~~|\*|` + "`" + `)`)
+	mdHeadingRe    = regexp.MustCompile(`^#{1,6}\s+`)
+	mdBulletRe     = regexp.MustCompile(`^([-*+]|\d+[.)])\s+`)
+	mdTableSepRe   = regexp.MustCompile(`^\|?\s*:?-{2,}:?\s*(\|\s*:?-{2,}:?\s*)*\|?$`)
+	mdBlankLinesRe = regexp.MustCompile(`\n{3,}`)
+)
+
+// SplitSpeakable separates a markdown reply into prose that can be read out
+// loud and blocks that only make sense as text: fenced code blocks and tables
+// with more than a few rows. The spoken part has markdown syntax stripped; the
+// text blocks are returned verbatim in their original order.
+func SplitSpeakable(content string) (spoken string, textBlocks []string) {
+	var (
+		speech strings.Builder
+		block  []string
+		fence  string
+		table  []string
+	)
+
+	flushTable := func() {
+		if len(table) == 0 {
+			return
+		}
+		if len(table) > maxSpokenTableRows {
+			textBlocks = append(textBlocks, strings.Join(table, "\n"))
+		} else {
+			for _, row := range table {
+				if mdTableSepRe.MatchString(row) {
+					continue
+				}
+				cells := strings.Split(strings.Trim(row, "| "), "|")
+				for i := range cells {
+					cells[i] = strings.TrimSpace(cells[i])
+				}
+				speech.WriteString(plainText(strings.Join(cells, ", ")))
+				speech.WriteByte('\n')
+			}
+		}
+		table = table[:0]
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			block = append(block, line)
+			if strings.HasPrefix(trimmed, fence) {
+				textBlocks = append(textBlocks, strings.Join(block, "\n"))
+				block, fence = nil, ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			flushTable()
+			fence = trimmed[:3]
+			block = append(block, line)
+			continue
+		}
+		if strings.HasPrefix(trimmed, "|") {
+			table = append(table, trimmed)
+			continue
+		}
+		flushTable()
+		speech.WriteString(plainText(trimmed))
+		speech.WriteByte('\n')
+	}
+	flushTable()
+	if len(block) > 0 {
+		// Unterminated fence: treat the remainder as code.
+		textBlocks = append(textBlocks, strings.Join(block, "\n"))
+	}
+
+	spoken = mdBlankLinesRe.ReplaceAllString(speech.String(), "\n\n")
+	return strings.TrimSpace(spoken), textBlocks
+}
+
+// plainText strips inline markdown from a single line.
+func plainText(line string) string {
+	line = mdHeadingRe.ReplaceAllString(line, "")
+	line = mdBulletRe.ReplaceAllString(line, "")
+	line = mdImageRe.ReplaceAllString(line, "")
+	line = mdLinkRe.ReplaceAllString(line, "$1")
+	line = mdEmphasisRe.ReplaceAllString(line, "")
+	if strings.HasPrefix(line, ">") {
+		line = strings.TrimSpace(strings.TrimLeft(line, ">"))
+	}
+	return line
+}
@@ -0,0 +1,82 @@
+package speech
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/tgifai/friday/internal/provider"
+	"github.com/tgifai/friday/internal/usage"
+)
+
+const transcribePrompt = "Transcribe the attached audio verbatim. " +
+	"Reply with the transcript only, without quotes, commentary or translation."
+
+// ProviderTranscriber sends the clip as an audio input part to a chat model
+// that accepts audio (e.g. Gemini or gpt-4o-audio via an OpenAI provider).
+type ProviderTranscriber struct {
+	spec     *provider.ModelSpec
+	language string
+	timeout  time.Duration
+}
+
+func NewProviderTranscriber(modelSpec, language string, timeout time.Duration) (*ProviderTranscriber, error) {
+	spec, err := provider.ParseModelSpec(modelSpec)
+	if err != nil {
+		return nil, err
+	}
+	return &ProviderTranscriber{spec: spec, language: language, timeout: timeout}, nil
+}
+
+func (t *ProviderTranscriber) Name() string {
+	return t.spec.ProviderID + ":" + t.spec.ModelName
+}
+
+func (t *ProviderTranscriber) Transcribe(ctx context.Context, audio *Audio) (string, error) {
+	if audio == nil || len(audio.Data) == 0 {
+		return "", fmt.Errorf("empty audio")
+	}
+	// Providers are registered after agents are built, so resolve lazily.
+	p, err := provider.Get(t.spec.ProviderID)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	prompt := transcribePrompt
+	if t.language != "" {
+		prompt += " The speech is in language " + t.language + "."
+	}
+	b64 := base64.StdEncoding.EncodeToString(audio.Data)
+	msgs := []*schema.Message{{
+		Role: schema.User,
+		UserInputMultiContent: []schema.MessageInputPart{
+			{Type: schema.ChatMessagePartTypeText, Text: prompt},
+			{
+				Type: schema.ChatMessagePartTypeAudioURL,
+				Audio: &schema.MessageInputAudio{
+					MessagePartCommon: schema.MessagePartCommon{
+						Base64Data: &b64,
+						MIMEType:   audio.MIMEType,
+					},
+				},
+			},
+		},
+	}}
+
+	resp, err := p.Generate(ctx, t.spec.ModelName, msgs)
+	if err != nil {
+		return "", fmt.Errorf("generate transcript: %w", err)
+	}
+	usage.Track(ctx, usage.PurposeTranscribe, t.spec.ProviderID, t.spec.ModelName, resp)
+	if resp == nil {
+		return "", fmt.Errorf("empty transcript response from %s", t.Name())
+	}
+	return strings.TrimSpace(resp.Content), nil
+}
@@ -0,0 +1,105 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/security/secrets"
+)
+
+const defaultTimeout = 60 * time.Second
+
+// Audio is a recorded speech clip to transcribe.
+type Audio struct {
+	Data     []byte
+	MIMEType string
+	FileName string
+}
+
+// Transcriber converts recorded speech into text.
+type Transcriber interface {
+	// Name identifies the backend in logs.
+	Name() string
+	// Transcribe returns the spoken text of the clip.
+	Transcribe(ctx context.Context, audio *Audio) (string, error)
+}
+
+// Synthesizer renders text as speech.
+type Synthesizer interface {
+	// Name identifies the backend in logs.
+	Name() string
+	// Synthesize returns the spoken rendition of text.
+	Synthesize(ctx context.Context, text string) (*Audio, error)
+}
+
+// NewTranscriber builds the transcriber selected by cfg. It returns nil when
+// transcription is disabled (empty backend).
+func NewTranscriber(cfg config.STTConfig) (Transcriber, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	timeout := defaultTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case consts.STTBackendWhisper:
+		apiKey, err := secrets.Expand(cfg.APIKey)
+		if err != nil {
+			return nil, fmt.Errorf("stt api_key: %w", err)
+		}
+		return NewWhisperTranscriber(cfg.BaseURL, apiKey, cfg.Model, cfg.Language, timeout), nil
+	case consts.STTBackendProvider:
+		return NewProviderTranscriber(cfg.Model, cfg.Language, timeout)
+	default:
+		return nil, fmt.Errorf("unsupported stt backend: %s", cfg.Backend)
+	}
+}
+
+// NewSynthesizer builds the synthesizer selected by cfg. It returns nil when
+// voice replies are disabled (empty backend).
+func NewSynthesizer(cfg config.TTSConfig) (Synthesizer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	timeout := defaultTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case consts.TTSBackendOpenAI:
+		apiKey, err := secrets.Expand(cfg.APIKey)
+		if err != nil {
+			return nil, fmt.Errorf("tts api_key: %w", err)
+		}
+		return NewOpenAISynthesizer(cfg.BaseURL, apiKey, cfg.Model, cfg.Voice, cfg.Format, timeout), nil
+	case consts.TTSBackendCommand:
+		return NewCommandSynthesizer(cfg.Command, cfg.Format, timeout), nil
+	default:
+		return nil, fmt.Errorf("unsupported tts backend: %s", cfg.Backend)
+	}
+}
+
+// formatMIMEType maps a configured audio format to its MIME type and file
+// extension. "opus" means Ogg/Opus, which Telegram and Lark play as voice.
+func formatMIMEType(format string) (mimeType, ext string) {
+	switch format {
+	case "mp3":
+		return "audio/mpeg", ".mp3"
+	case "wav":
+		return "audio/wav", ".wav"
+	default:
+		return "audio/ogg", ".ogg"
+	}
+}
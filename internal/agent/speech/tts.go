@@ -0,0 +1,145 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+const (
+	defaultTTSModel = "tts-1"
+	defaultTTSVoice = "alloy"
+	// maxSpeechSize bounds synthesized audio; voice notes are small.
+	maxSpeechSize = 20 * 1024 * 1024
+)
+
+// OpenAISynthesizer talks to an OpenAI-compatible /audio/speech endpoint.
+type OpenAISynthesizer struct {
+	baseURL string
+	apiKey  string
+	model   string
+	voice   string
+	format  string
+	httpCli *http.Client
+}
+
+func NewOpenAISynthesizer(baseURL, apiKey, model, voice, format string, timeout time.Duration) *OpenAISynthesizer {
+	if model == "" {
+		model = defaultTTSModel
+	}
+	if voice == "" {
+		voice = defaultTTSVoice
+	}
+	if format == "" {
+		format = "opus"
+	}
+	return &OpenAISynthesizer{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		voice:   voice,
+		format:  format,
+		httpCli: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *OpenAISynthesizer) Name() string {
+	return "openai:" + s.model
+}
+
+func (s *OpenAISynthesizer) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("empty text")
+	}
+
+	payload, err := sonic.Marshal(map[string]string{
+		"model":           s.model,
+		"input":           text,
+		"voice":           s.voice,
+		"response_format": s.format,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal speech request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/audio/speech", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.httpCli.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("speech request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSpeechSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read speech response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("speech API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return newSpeechAudio(data, s.format)
+}
+
+// CommandSynthesizer runs a local TTS program (piper, espeak-ng + ffmpeg,
+// say, ...). The reply text is written to stdin and the audio is read from
+// stdout in the configured format.
+type CommandSynthesizer struct {
+	argv    []string
+	format  string
+	timeout time.Duration
+}
+
+func NewCommandSynthesizer(argv []string, format string, timeout time.Duration) *CommandSynthesizer {
+	if format == "" {
+		format = "opus"
+	}
+	return &CommandSynthesizer{argv: argv, format: format, timeout: timeout}
+}
+
+func (s *CommandSynthesizer) Name() string {
+	return "command:" + s.argv[0]
+}
+
+func (s *CommandSynthesizer) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("empty text")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.argv[0], s.argv[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("tts command: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return newSpeechAudio(stdout.Bytes(), s.format)
+}
+
+func newSpeechAudio(data []byte, format string) (*Audio, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty audio output")
+	}
+	if len(data) > maxSpeechSize {
+		return nil, fmt.Errorf("audio output exceeds %d bytes", maxSpeechSize)
+	}
+	mimeType, ext := formatMIMEType(format)
+	return &Audio{Data: data, MIMEType: mimeType, FileName: "reply" + ext}, nil
+}
@@ -0,0 +1,90 @@
+package speech
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitSpeakable(t *testing.T) {
+	content := strings.Join([]string{
+		"## Result",
+		"The **build** passed, see [the log](https://ci.example/1).",
+		"",
+		"```go",
+		"fmt.Println(\"hi\")",
+		"```",
+		"| a | b |",
+		"|---|---|",
+		"| 1 | 2 |",
+		"",
+		"| n |",
+		"|---|",
+		"| 1 |",
+		"| 2 |",
+		"| 3 |",
+		"- `done`",
+	}, "\n")
+
+	spoken, blocks := SplitSpeakable(content)
+	wantSpoken := "Result\nThe build passed, see the log.\n\na, b\n1, 2\n\ndone"
+	if spoken != wantSpoken {
+		t.Errorf("spoken = %q, want %q", spoken, wantSpoken)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("got %d text blocks, want 2: %q", len(blocks), blocks)
+	}
+	if !strings.HasPrefix(blocks[0], "```go") || !strings.HasSuffix(blocks[0], "```") {
+		t.Errorf("unexpected code block %q", blocks[0])
+	}
+	if !strings.HasPrefix(blocks[1], "| n |") || strings.Count(blocks[1], "\n") != 4 {
+		t.Errorf("unexpected table block %q", blocks[1])
+	}
+}
+
+func TestSplitSpeakable_OnlyCode(t *testing.T) {
+	spoken, blocks := SplitSpeakable("```\nls -la\n")
+	if spoken != "" || len(blocks) != 1 {
+		t.Errorf("spoken=%q blocks=%q", spoken, blocks)
+	}
+}
+
+func TestOpenAISynthesizer_Synthesize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/speech" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		for _, want := range []string{`"input":"hello"`, `"voice":"nova"`, `"response_format":"opus"`, `"model":"tts-1"`} {
+			if !strings.Contains(string(body), want) {
+				t.Errorf("request body %s missing %s", body, want)
+			}
+		}
+		_, _ = w.Write([]byte("OggS-audio"))
+	}))
+	defer srv.Close()
+
+	s := NewOpenAISynthesizer(srv.URL+"/v1", "", "", "nova", "", 5*time.Second)
+	audio, err := s.Synthesize(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+	if string(audio.Data) != "OggS-audio" || audio.MIMEType != "audio/ogg" || audio.FileName != "reply.ogg" {
+		t.Errorf("unexpected audio %+v", audio)
+	}
+}
+
+func TestCommandSynthesizer_Synthesize(t *testing.T) {
+	s := NewCommandSynthesizer([]string{"cat"}, "mp3", 5*time.Second)
+	audio, err := s.Synthesize(context.Background(), "spoken")
+	if err != nil {
+		t.Skipf("cat not available: %v", err)
+	}
+	if string(audio.Data) != "spoken" || audio.MIMEType != "audio/mpeg" {
+		t.Errorf("unexpected audio %+v", audio)
+	}
+}
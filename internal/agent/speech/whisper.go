@@ -0,0 +1,121 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+const (
+	defaultWhisperModel = "whisper-1"
+	maxWhisperResponse  = 1 * 1024 * 1024
+)
+
+// WhisperTranscriber talks to an OpenAI-compatible /audio/transcriptions
+// endpoint. Besides OpenAI itself this covers local servers such as
+// whisper.cpp, faster-whisper-server and LocalAI.
+type WhisperTranscriber struct {
+	baseURL  string
+	apiKey   string
+	model    string
+	language string
+	httpCli  *http.Client
+}
+
+func NewWhisperTranscriber(baseURL, apiKey, model, language string, timeout time.Duration) *WhisperTranscriber {
+	if model == "" {
+		model = defaultWhisperModel
+	}
+	return &WhisperTranscriber{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		apiKey:   apiKey,
+		model:    model,
+		language: language,
+		httpCli:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (w *WhisperTranscriber) Name() string {
+	return "whisper:" + w.model
+}
+
+func (w *WhisperTranscriber) Transcribe(ctx context.Context, audio *Audio) (string, error) {
+	if audio == nil || len(audio.Data) == 0 {
+		return "", fmt.Errorf("empty audio")
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", audioFileName(audio))
+	if err != nil {
+		return "", fmt.Errorf("create form file: %w", err)
+	}
+	if _, err := fw.Write(audio.Data); err != nil {
+		return "", fmt.Errorf("write form file: %w", err)
+	}
+	_ = mw.WriteField("model", w.model)
+	_ = mw.WriteField("response_format", "json")
+	if w.language != "" {
+		_ = mw.WriteField("language", w.language)
+	}
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("close multipart writer: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.baseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if w.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+w.apiKey)
+	}
+
+	resp, err := w.httpCli.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("transcription request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, maxWhisperResponse))
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("transcription API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := sonic.Unmarshal(raw, &result); err != nil {
+		return "", fmt.Errorf("decode transcription response: %w", err)
+	}
+	return strings.TrimSpace(result.Text), nil
+}
+
+// audioFileName picks an upload name whose extension lets the server detect
+// the container format.
+func audioFileName(audio *Audio) string {
+	if audio.FileName != "" && strings.Contains(audio.FileName, ".") {
+		return audio.FileName
+	}
+	ext := ".ogg"
+	switch strings.ToLower(strings.TrimSpace(strings.SplitN(audio.MIMEType, ";", 2)[0])) {
+	case "audio/mpeg", "audio/mp3":
+		ext = ".mp3"
+	case "audio/mp4", "audio/m4a", "audio/x-m4a":
+		ext = ".m4a"
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		ext = ".wav"
+	case "audio/webm":
+		ext = ".webm"
+	case "audio/flac":
+		ext = ".flac"
+	}
+	return "audio" + ext
+}
@@ -0,0 +1,75 @@
+package speech
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWhisperTranscriber_Transcribe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/transcriptions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("unexpected auth header %q", got)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		if got := r.FormValue("model"); got != "whisper-1" {
+			t.Errorf("model = %q", got)
+		}
+		if got := r.FormValue("language"); got != "en" {
+			t.Errorf("language = %q", got)
+		}
+		f, hdr, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("form file: %v", err)
+		}
+		data, _ := io.ReadAll(f)
+		if string(data) != "OggS" || hdr.Filename != "audio.ogg" {
+			t.Errorf("unexpected file %q (%s)", data, hdr.Filename)
+		}
+		_, _ = w.Write([]byte(`{"text":"  hello world \n"}`))
+	}))
+	defer srv.Close()
+
+	tr := NewWhisperTranscriber(srv.URL+"/v1/", "sk-test", "", "en", 5*time.Second)
+	got, err := tr.Transcribe(context.Background(), &Audio{Data: []byte("OggS"), MIMEType: "audio/ogg"})
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if got != "hello world" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestWhisperTranscriber_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	tr := NewWhisperTranscriber(srv.URL, "", "base", "", 5*time.Second)
+	if _, err := tr.Transcribe(context.Background(), &Audio{Data: []byte("x")}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestAudioFileName(t *testing.T) {
+	cases := map[string]*Audio{
+		"voice.opus": {FileName: "voice.opus", MIMEType: "audio/ogg"},
+		"audio.mp3":  {MIMEType: "audio/mpeg"},
+		"audio.wav":  {FileName: "noext", MIMEType: "audio/wav; codecs=1"},
+		"audio.ogg":  {},
+	}
+	for want, audio := range cases {
+		if got := audioFileName(audio); got != want {
+			t.Errorf("audioFileName(%+v) = %q, want %q", audio, got, want)
+		}
+	}
+}
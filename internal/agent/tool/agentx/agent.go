@@ -11,6 +11,7 @@ import (
 	"github.com/cloudwego/eino/schema"
 
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/sandbox"
 )
 
 // isSubpath reports whether child is under parent (or equal to parent).
@@ -31,17 +32,23 @@ const (
 // AgentTool delegates tasks to external CLI agents (Claude Code, Codex).
 type AgentTool struct {
 	workspace string
+	executor  sandbox.Executor
 	backends  map[string]Backend
 	sessions  *SessionManager
 }
 
-func NewAgentTool(workspace string) *AgentTool {
+func NewAgentTool(workspace string, executor ...sandbox.Executor) *AgentTool {
+	var one sandbox.Executor
+	if len(executor) > 0 {
+		one = executor[0]
+	}
 	backends := map[string]Backend{
 		"claude-code": &ClaudeCodeBackend{},
 		"codex":       &CodexBackend{},
 	}
 	return &AgentTool{
 		workspace: workspace,
+		executor:  one,
 		backends:  backends,
 		sessions:  NewSessionManager(maxSessions),
 	}
@@ -52,6 +59,8 @@ func (t *AgentTool) Description() string {
 	return "Delegate coding tasks to CLI agents (Claude Code or Codex). Supports creating sessions, sending follow-up messages, checking status, and managing session lifecycle."
 }
 
+func (t *AgentTool) ParallelSafe() bool { return false }
+
 func (t *AgentTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -148,6 +157,7 @@ func (t *AgentTool) executeCreate(ctx context.Context, args map[string]interface
 		WorkingDir:   workingDir,
 		SystemPrompt: gconv.To[string](args["system_prompt"]),
 		MaxTurns:     gconv.To[int](args["max_turns"]),
+		Executor:     t.executor,
 	}
 
 	sess, err := t.sessions.CreateWithLimit(backendName, workingDir)
@@ -231,6 +241,7 @@ func (t *AgentTool) executeSend(ctx context.Context, args map[string]interface{}
 		Prompt:     prompt,
 		WorkingDir: sess.WorkingDir,
 		ResumeID:   cliSessionID,
+		Executor:   t.executor,
 	}
 
 	async := gconv.To[bool](args["async"])
@@ -4,10 +4,12 @@ import (
 	"context"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
 	"sync/atomic"
 
 	"github.com/tgifai/friday/internal/pkg/iobuf"
+	"github.com/tgifai/friday/internal/security/sandbox"
 )
 
 // Backend abstracts CLI differences between Claude Code and Codex.
@@ -27,7 +29,28 @@ type RunRequest struct {
 	WorkingDir   string
 	SystemPrompt string
 	MaxTurns     int
-	ResumeID     string // CLI-native session ID for --resume
+	ResumeID     string           // CLI-native session ID for --resume
+	Executor     sandbox.Executor // sandbox the CLI runs in; nil runs it directly
+}
+
+// newCommand prepares a CLI invocation, through the request's sandbox when
+// it has one.
+func newCommand(ctx context.Context, req *RunRequest, program string, args ...string) (*exec.Cmd, error) {
+	if req.Executor != nil {
+		return sandbox.NewCommand(ctx, req.Executor, &sandbox.ExecRequest{
+			WorkingDir: req.WorkingDir,
+			Command: sandbox.Command{
+				Display: strings.Join(append([]string{program}, args...), " "),
+				Program: program,
+				Args:    args,
+			},
+		})
+	}
+	cmd := exec.CommandContext(ctx, program, args...)
+	if req.WorkingDir != "" {
+		cmd.Dir = req.WorkingDir
+	}
+	return cmd, nil
 }
 
 // RunResult holds the output of a completed CLI invocation.
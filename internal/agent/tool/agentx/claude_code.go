@@ -71,9 +71,9 @@ func (b *ClaudeCodeBackend) ParseOutput(raw string, exitCode int) *RunResult {
 
 func (b *ClaudeCodeBackend) Run(ctx context.Context, req *RunRequest) (*RunResult, error) {
 	args := b.buildArgs(req)
-	cmd := exec.CommandContext(ctx, "claude", args...)
-	if req.WorkingDir != "" {
-		cmd.Dir = req.WorkingDir
+	cmd, err := newCommand(ctx, req, "claude", args...)
+	if err != nil {
+		return nil, fmt.Errorf("claude-code run: %w", err)
 	}
 
 	stdout := iobuf.NewLimitedBuffer(maxOutputBytes)
@@ -82,7 +82,7 @@ func (b *ClaudeCodeBackend) Run(ctx context.Context, req *RunRequest) (*RunResul
 	cmd.Stderr = stderr
 
 	exitCode := 0
-	err := cmd.Run()
+	err = cmd.Run()
 	if err != nil {
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
@@ -97,9 +97,9 @@ func (b *ClaudeCodeBackend) Run(ctx context.Context, req *RunRequest) (*RunResul
 
 func (b *ClaudeCodeBackend) Start(ctx context.Context, req *RunRequest) (*Process, error) {
 	args := b.buildArgs(req)
-	cmd := exec.CommandContext(ctx, "claude", args...)
-	if req.WorkingDir != "" {
-		cmd.Dir = req.WorkingDir
+	cmd, err := newCommand(ctx, req, "claude", args...)
+	if err != nil {
+		return nil, fmt.Errorf("claude-code start: %w", err)
 	}
 
 	stdout := iobuf.NewLimitedBuffer(maxOutputBytes)
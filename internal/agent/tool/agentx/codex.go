@@ -104,9 +104,9 @@ func (b *CodexBackend) ParseOutput(raw string, exitCode int) *RunResult {
 
 func (b *CodexBackend) Run(ctx context.Context, req *RunRequest) (*RunResult, error) {
 	args := b.buildArgs(req)
-	cmd := exec.CommandContext(ctx, "codex", args...)
-	if req.WorkingDir != "" {
-		cmd.Dir = req.WorkingDir
+	cmd, err := newCommand(ctx, req, "codex", args...)
+	if err != nil {
+		return nil, fmt.Errorf("codex run: %w", err)
 	}
 
 	stdout := iobuf.NewLimitedBuffer(maxOutputBytes)
@@ -114,7 +114,7 @@ func (b *CodexBackend) Run(ctx context.Context, req *RunRequest) (*RunResult, er
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
-	err := cmd.Run()
+	err = cmd.Run()
 	exitCode := 0
 	if err != nil {
 		var exitErr *exec.ExitError
@@ -131,9 +131,9 @@ func (b *CodexBackend) Run(ctx context.Context, req *RunRequest) (*RunResult, er
 
 func (b *CodexBackend) Start(ctx context.Context, req *RunRequest) (*Process, error) {
 	args := b.buildArgs(req)
-	cmd := exec.CommandContext(ctx, "codex", args...)
-	if req.WorkingDir != "" {
-		cmd.Dir = req.WorkingDir
+	cmd, err := newCommand(ctx, req, "codex", args...)
+	if err != nil {
+		return nil, fmt.Errorf("codex start: %w", err)
 	}
 
 	stdoutBuf := iobuf.NewLimitedBuffer(maxOutputBytes)
@@ -27,6 +27,8 @@ func (t *BrowserTool) Description() string {
 	return "Browser automation tool with stealth anti-detection. Supports navigation, element interaction, screenshots, content extraction, and JavaScript evaluation."
 }
 
+func (t *BrowserTool) ParallelSafe() bool { return false }
+
 func (t *BrowserTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -0,0 +1,147 @@
+package browserx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+	"time"
+
+	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/egress"
+)
+
+const egressDialTimeout = 10 * time.Second
+
+// egressProxy is a loopback HTTP proxy that one browser session sends all
+// of its traffic through. It connects with the session's egress policy,
+// so the address a host is checked at is the address connected to, and a
+// host cannot rebind to a blocked address between the check and the
+// connection. Chromium never resolves the hosts itself.
+type egressProxy struct {
+	policy   *egress.Policy
+	listener net.Listener
+	server   *http.Server
+	forward  *httputil.ReverseProxy
+
+	mu      sync.Mutex
+	tunnels map[net.Conn]struct{} // hijacked CONNECT connections, closed on stop
+}
+
+// startEgressProxy listens on a loopback port and serves until stop.
+func startEgressProxy(policy *egress.Policy) (*egressProxy, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	// The transport serves a single policy, so its connections can be
+	// pooled.
+	transport := egress.Transport()
+	transport.DialContext = policy.DialContext
+	transport.DisableKeepAlives = false
+
+	p := &egressProxy{
+		policy:   policy,
+		listener: ln,
+		tunnels:  make(map[net.Conn]struct{}),
+	}
+	p.forward = &httputil.ReverseProxy{
+		Rewrite:      func(*httputil.ProxyRequest) {}, // proxy requests carry the absolute URL
+		Transport:    transport,
+		ErrorHandler: p.fail,
+	}
+	p.server = &http.Server{Handler: p, ReadHeaderTimeout: egressDialTimeout}
+	go func() { _ = p.server.Serve(ln) }()
+	return p, nil
+}
+
+// addr is the proxy's host:port, for Chromium's --proxy-server.
+func (p *egressProxy) addr() string {
+	return p.listener.Addr().String()
+}
+
+func (p *egressProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodConnect:
+		p.tunnel(w, r)
+	case r.URL.IsAbs():
+		p.forward.ServeHTTP(w, r)
+	default:
+		http.Error(w, "not a proxy request", http.StatusBadRequest)
+	}
+}
+
+// tunnel serves CONNECT, which carries https and wss traffic.
+func (p *egressProxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), egressDialTimeout)
+	upstream, err := p.policy.DialContext(ctx, "tcp", r.Host)
+	cancel()
+	if err != nil {
+		p.fail(w, r, err)
+		return
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	client, buf, err := hj.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	p.track(client, true)
+	defer p.track(client, false)
+	defer client.Close()
+	defer upstream.Close()
+
+	if _, err := io.WriteString(client, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(upstream, buf) // buf holds anything read past the request
+		if tc, ok := upstream.(*net.TCPConn); ok {
+			_ = tc.CloseWrite()
+		}
+		close(done)
+	}()
+	_, _ = io.Copy(client, upstream)
+	client.Close()
+	<-done
+}
+
+// fail answers a request the policy blocked, or that could not be
+// forwarded.
+func (p *egressProxy) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, egress.ErrBlocked) {
+		logs.Warn("[tool:browser] blocked request to %s: %v", r.Host, err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadGateway)
+}
+
+func (p *egressProxy) track(conn net.Conn, add bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if add {
+		p.tunnels[conn] = struct{}{}
+	} else {
+		delete(p.tunnels, conn)
+	}
+}
+
+// stop closes the listener and every open connection.
+func (p *egressProxy) stop() {
+	_ = p.server.Close()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for conn := range p.tunnels {
+		_ = conn.Close()
+	}
+}
@@ -0,0 +1,76 @@
+package browserx
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/tgifai/friday/internal/security/egress"
+)
+
+// proxyClient returns a client that sends every request through p.
+func proxyClient(p *egressProxy) *http.Client {
+	proxyURL := &url.URL{Scheme: "http", Host: p.addr()}
+	return &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+}
+
+func TestEgressProxy(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { _, _ = io.WriteString(w, "ok") })
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+	tlsSrv := httptest.NewTLSServer(handler)
+	defer tlsSrv.Close()
+
+	for _, tc := range []struct {
+		name string
+		opts egress.Options
+		want int // status of the plain http request
+	}{
+		{"private blocked", egress.Options{}, http.StatusForbidden},
+		{"private allowed", egress.Options{AllowPrivate: true}, http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := egress.New(tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := startEgressProxy(policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer p.stop()
+			client := proxyClient(p)
+
+			resp, err := client.Get(plain.URL)
+			if err != nil {
+				t.Fatalf("http: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.want {
+				t.Errorf("http status %d, want %d", resp.StatusCode, tc.want)
+			}
+
+			// https goes through CONNECT, which fails outright when refused.
+			resp, err = client.Get(tlsSrv.URL)
+			switch {
+			case tc.want != http.StatusOK && err == nil:
+				resp.Body.Close()
+				t.Errorf("https reached a blocked address: %d", resp.StatusCode)
+			case tc.want == http.StatusOK && err != nil:
+				t.Errorf("https: %v", err)
+			case err == nil:
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if string(body) != "ok" {
+					t.Errorf("https body %q", body)
+				}
+			}
+		})
+	}
+}
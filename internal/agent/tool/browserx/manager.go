@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/egress"
 )
 
 // Session represents a browser instance with its active page.
@@ -17,6 +18,7 @@ type Session struct {
 	Browser   *rod.Browser
 	Page      *rod.Page
 	Headless  bool
+	proxy     *egressProxy // enforces the egress policy of the opening agent
 	CreatedAt time.Time
 	mu        sync.Mutex
 }
@@ -45,21 +47,29 @@ func newBrowserManager() *BrowserManager {
 	}
 }
 
-func (m *BrowserManager) openSession(headless bool) (*Session, error) {
+// openSession launches a browser whose requests go through policy.
+func (m *BrowserManager) openSession(policy *egress.Policy, headless bool) (*Session, error) {
 	if !headless {
 		if ok, reason := canRunHeaded(); !ok {
 			return nil, fmt.Errorf("headed mode not supported: %s. Use headless=true instead", reason)
 		}
 	}
 
-	l := newStealthLauncher(headless)
+	proxy, err := startEgressProxy(policy)
+	if err != nil {
+		return nil, fmt.Errorf("start egress proxy: %w", err)
+	}
+
+	l := newStealthLauncher(headless, proxy.addr())
 	controlURL, err := l.Launch()
 	if err != nil {
+		proxy.stop()
 		return nil, fmt.Errorf("launch browser: %w", err)
 	}
 
 	browser := rod.New().ControlURL(controlURL)
 	if err := browser.Connect(); err != nil {
+		proxy.stop()
 		return nil, fmt.Errorf("connect to browser: %w", err)
 	}
 
@@ -68,11 +78,13 @@ func (m *BrowserManager) openSession(headless bool) (*Session, error) {
 	page, err := newStealthPage(browser)
 	if err != nil {
 		browser.Close()
+		proxy.stop()
 		return nil, fmt.Errorf("create stealth page: %w", err)
 	}
 
 	if err := setViewport(page); err != nil {
 		browser.Close()
+		proxy.stop()
 		return nil, fmt.Errorf("set viewport: %w", err)
 	}
 
@@ -84,6 +96,7 @@ func (m *BrowserManager) openSession(headless bool) (*Session, error) {
 		Page:      page,
 		Headless:  headless,
 		CreatedAt: time.Now(),
+		proxy:     proxy,
 	}
 
 	m.mu.Lock()
@@ -118,6 +131,7 @@ func (m *BrowserManager) closeSession(id string) error {
 	sess.mu.Lock()
 	defer sess.mu.Unlock()
 
+	defer sess.proxy.stop()
 	if err := sess.Browser.Close(); err != nil {
 		logs.Warn("[tool:browser] error closing session %s: %v", id, err)
 		return err
@@ -163,6 +177,7 @@ func (m *BrowserManager) shutdown() {
 		if err := sess.Browser.Close(); err != nil {
 			logs.Warn("[tool:browser] shutdown error for session %s: %v", id, err)
 		}
+		sess.proxy.stop()
 		sess.mu.Unlock()
 	}
 	m.sessions = make(map[string]*Session)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	neturl "net/url"
 	"strings"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/go-rod/rod/lib/proto"
 
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/egress"
 )
 
 const (
@@ -24,7 +26,7 @@ func (t *BrowserTool) opOpen(ctx context.Context, args map[string]interface{}) (
 		headless = gconv.To[bool](v)
 	}
 
-	sess, err := t.manager.openSession(headless)
+	sess, err := t.manager.openSession(egress.FromContext(ctx), headless)
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +62,14 @@ func (t *BrowserTool) opNavigate(ctx context.Context, args map[string]interface{
 		return nil, fmt.Errorf("url is required for navigate")
 	}
 
+	// Blocked requests are failed by the egress proxy anyway; checking here
+	// gives a clearer error than a failed navigation.
+	if parsed, err := neturl.Parse(url); err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") {
+		if err := egress.FromContext(ctx).CheckURL(ctx, parsed); err != nil {
+			return nil, err
+		}
+	}
+
 	waitLoad := true
 	if v, ok := args["wait_load"]; ok {
 		waitLoad = gconv.To[bool](v)
@@ -8,10 +8,17 @@ import (
 	"github.com/go-rod/stealth"
 )
 
-// newStealthLauncher creates a launcher with anti-detection Chrome flags.
-func newStealthLauncher(headless bool) *launcher.Launcher {
+// newStealthLauncher creates a launcher with anti-detection Chrome flags
+// that sends all traffic through the proxy at proxyAddr.
+func newStealthLauncher(headless bool, proxyAddr string) *launcher.Launcher {
 	l := launcher.New()
 
+	// Route everything, loopback included, through the egress proxy, and
+	// keep WebRTC from sending UDP around it.
+	l.Proxy("http://" + proxyAddr)
+	l.Set(flags.Flag("proxy-bypass-list"), "<-loopback>")
+	l.Set(flags.Flag("force-webrtc-ip-handling-policy"), "disable_non_proxied_udp")
+
 	if headless {
 		// Use new headless mode (Chrome 112+) which is harder to detect.
 		l.HeadlessNew(true)
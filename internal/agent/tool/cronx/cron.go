@@ -28,6 +28,8 @@ func (t *CronTool) Description() string {
 	return "Manage scheduled cron jobs: create, list, delete, or update periodic and one-shot tasks"
 }
 
+func (t *CronTool) ParallelSafe() bool { return false }
+
 func (t *CronTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -7,20 +7,23 @@ import (
 
 	"github.com/cloudwego/eino/schema"
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/fspolicy"
 )
 
 type DeleteTool struct {
 	guard *fsGuard
 }
 
-func NewDeleteTool(workspace string, allowedPaths []string) *DeleteTool {
-	return &DeleteTool{guard: newFSGuard(workspace, allowedPaths)}
+func NewDeleteTool(policy *fspolicy.Policy) *DeleteTool {
+	return &DeleteTool{guard: newFSGuard(policy)}
 }
 
 func (t *DeleteTool) Name() string { return "delete" }
 
 func (t *DeleteTool) Description() string { return "Delete a file in allowed paths" }
 
+func (t *DeleteTool) ParallelSafe() bool { return false }
+
 func (t *DeleteTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -37,13 +40,10 @@ func (t *DeleteTool) ToolInfo() *schema.ToolInfo {
 
 func (t *DeleteTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	path, _ := args["path"].(string)
-	absPath, err := t.guard.resolvePath(path)
+	absPath, err := t.guard.check(path, fspolicy.Write)
 	if err != nil {
 		return nil, err
 	}
-	if err := t.guard.checkPathAllowed(absPath); err != nil {
-		return nil, err
-	}
 	if err := os.Remove(absPath); err != nil {
 		return nil, fmt.Errorf("failed to delete file: %w", err)
 	}
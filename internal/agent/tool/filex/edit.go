@@ -10,14 +10,15 @@ import (
 	"github.com/cloudwego/eino/schema"
 
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/fspolicy"
 )
 
 type EditTool struct {
 	guard *fsGuard
 }
 
-func NewEditTool(workspace string, allowedPaths []string) *EditTool {
-	return &EditTool{guard: newFSGuard(workspace, allowedPaths)}
+func NewEditTool(policy *fspolicy.Policy) *EditTool {
+	return &EditTool{guard: newFSGuard(policy)}
 }
 
 func (t *EditTool) Name() string { return "edit" }
@@ -26,6 +27,8 @@ func (t *EditTool) Description() string {
 	return "Edit file by text replacement or line range replacement"
 }
 
+func (t *EditTool) ParallelSafe() bool { return false }
+
 func (t *EditTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -63,11 +66,11 @@ func (t *EditTool) ToolInfo() *schema.ToolInfo {
 
 func (t *EditTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	path, _ := args["path"].(string)
-	absPath, err := t.guard.resolvePath(path)
-	if err != nil {
+	if _, err := t.guard.checkRead(path); err != nil {
 		return nil, err
 	}
-	if err := t.guard.checkPathAllowed(absPath); err != nil {
+	absPath, err := t.guard.check(path, fspolicy.Write)
+	if err != nil {
 		return nil, err
 	}
 
@@ -83,6 +86,9 @@ func (t *EditTool) Execute(ctx context.Context, args map[string]interface{}) (in
 	if updated == original {
 		return nil, fmt.Errorf("edit made no changes")
 	}
+	if err := t.guard.policy.CheckWriteSize(path, int64(len(updated))); err != nil {
+		return nil, err
+	}
 	if err := os.WriteFile(absPath, []byte(updated), 0o644); err != nil {
 		return nil, fmt.Errorf("failed to write file: %w", err)
 	}
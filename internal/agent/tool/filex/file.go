@@ -8,18 +8,15 @@ import (
 
 	"github.com/cloudwego/eino/schema"
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/fspolicy"
 )
 
 type FileTool struct {
-	allowedPaths []string
-	workspace    string
+	guard *fsGuard
 }
 
-func NewFileTool(workspace string, allowedPaths []string) *FileTool {
-	return &FileTool{
-		allowedPaths: allowedPaths,
-		workspace:    workspace,
-	}
+func NewFileTool(policy *fspolicy.Policy) *FileTool {
+	return &FileTool{guard: newFSGuard(policy)}
 }
 
 func (t *FileTool) Name() string {
@@ -30,6 +27,8 @@ func (t *FileTool) Description() string {
 	return "File operations: read_file, write_file, list_dir, delete_file"
 }
 
+func (t *FileTool) ParallelSafe() bool { return false }
+
 func (t *FileTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: "file",
@@ -65,7 +64,7 @@ func (t *FileTool) Execute(ctx context.Context, args map[string]interface{}) (in
 
 				path, _ := args["path"].(string)
 				if path != "" {
-					absPath, err := t.resolvePath(path)
+					absPath, err := t.guard.policy.Resolve(path)
 					if err != nil {
 						return nil, err
 					}
@@ -109,15 +108,11 @@ func (t *FileTool) readFile(ctx context.Context, args map[string]interface{}) (i
 		return nil, fmt.Errorf("path is required")
 	}
 
-	absPath, err := t.resolvePath(path)
+	absPath, err := t.guard.checkRead(path)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := t.checkPathAllowed(absPath); err != nil {
-		return nil, err
-	}
-
 	content, err := os.ReadFile(absPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
@@ -143,15 +138,11 @@ func (t *FileTool) writeFile(ctx context.Context, args map[string]interface{}) (
 		return nil, fmt.Errorf("write_file: missing required parameter 'content'")
 	}
 
-	absPath, err := t.resolvePath(path)
+	absPath, err := t.guard.checkWrite(path, len(content))
 	if err != nil {
 		return nil, err
 	}
 
-	if err := t.checkPathAllowed(absPath); err != nil {
-		return nil, err
-	}
-
 	if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create parent directory: %w", err)
 	}
@@ -174,15 +165,11 @@ func (t *FileTool) listDir(ctx context.Context, args map[string]interface{}) (in
 		return nil, fmt.Errorf("path is required")
 	}
 
-	absPath, err := t.resolvePath(path)
+	absPath, err := t.guard.check(path, fspolicy.Read)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := t.checkPathAllowed(absPath); err != nil {
-		return nil, err
-	}
-
 	entries, err := os.ReadDir(absPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list directory: %w", err)
@@ -226,15 +213,11 @@ func (t *FileTool) deleteFile(ctx context.Context, args map[string]interface{})
 		return nil, fmt.Errorf("path is required")
 	}
 
-	absPath, err := t.resolvePath(path)
+	absPath, err := t.guard.check(path, fspolicy.Write)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := t.checkPathAllowed(absPath); err != nil {
-		return nil, err
-	}
-
 	if err := os.Remove(absPath); err != nil {
 		return nil, fmt.Errorf("failed to delete file: %w", err)
 	}
@@ -245,47 +228,3 @@ func (t *FileTool) deleteFile(ctx context.Context, args map[string]interface{})
 		"path":    path,
 	}, nil
 }
-
-func (t *FileTool) resolvePath(path string) (string, error) {
-
-	if filepath.IsAbs(path) {
-		return filepath.Clean(path), nil
-	}
-
-	if t.workspace != "" {
-		return filepath.Clean(filepath.Join(t.workspace, path)), nil
-	}
-
-	absPath, err := filepath.Abs(path)
-	if err != nil {
-		return "", fmt.Errorf("failed to resolve path: %w", err)
-	}
-
-	return absPath, nil
-}
-
-func (t *FileTool) checkPathAllowed(path string) error {
-
-	if len(t.allowedPaths) == 0 {
-		return nil
-	}
-
-	absPath, err := filepath.Abs(path)
-	if err != nil {
-		return fmt.Errorf("failed to resolve path: %w", err)
-	}
-
-	for _, allowed := range t.allowedPaths {
-		allowedAbs, err := filepath.Abs(allowed)
-		if err != nil {
-			continue
-		}
-
-		ok, relErr := isPathWithin(absPath, allowedAbs)
-		if relErr == nil && ok {
-			return nil
-		}
-	}
-
-	return fmt.Errorf("path not allowed: %s", path)
-}
@@ -1,87 +1,50 @@
 package filex
 
 import (
-	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
+
+	"github.com/tgifai/friday/internal/security/fspolicy"
 )
 
+// fsGuard checks the paths of file tool calls against the agent's
+// filesystem policy.
 type fsGuard struct {
-	workspace    string
-	allowedPaths []string
+	policy *fspolicy.Policy
 }
 
-func newFSGuard(workspace string, allowedPaths []string) *fsGuard {
-	return &fsGuard{
-		workspace:    workspace,
-		allowedPaths: allowedPaths,
-	}
+func newFSGuard(policy *fspolicy.Policy) *fsGuard {
+	return &fsGuard{policy: policy}
 }
 
-func (g *fsGuard) resolvePath(path string) (string, error) {
-	path = strings.TrimSpace(path)
-	if path == "" {
-		return "", fmt.Errorf("path is required")
-	}
-	if filepath.IsAbs(path) {
-		return filepath.Clean(path), nil
-	}
-	if g.workspace != "" {
-		return filepath.Clean(filepath.Join(g.workspace, path)), nil
-	}
-	absPath, err := filepath.Abs(path)
-	if err != nil {
-		return "", fmt.Errorf("failed to resolve path: %w", err)
-	}
-	return absPath, nil
+// check resolves path and returns the absolute path to use if access to
+// it is allowed.
+func (g *fsGuard) check(path string, access fspolicy.Access) (string, error) {
+	return g.policy.Check(path, access)
 }
 
-func (g *fsGuard) checkPathAllowed(path string) error {
-	if len(g.allowedPaths) == 0 {
-		return nil
-	}
-	absPath, err := filepath.Abs(path)
+// checkRead is check for reading a whole file, which must also fit in the
+// read limit.
+func (g *fsGuard) checkRead(path string) (string, error) {
+	absPath, err := g.check(path, fspolicy.Read)
 	if err != nil {
-		return fmt.Errorf("failed to resolve path: %w", err)
+		return "", err
 	}
-	for _, allowed := range g.allowedPaths {
-		allowedPath := strings.TrimSpace(allowed)
-		if allowedPath == "" {
-			continue
-		}
-		allowedAbs, err := filepath.Abs(allowedPath)
-		if err != nil {
-			continue
-		}
-		ok, err := isPathWithin(absPath, allowedAbs)
-		if err == nil && ok {
-			return nil
+	if info, err := os.Stat(absPath); err == nil {
+		if err := g.policy.CheckReadSize(path, info.Size()); err != nil {
+			return "", err
 		}
 	}
-	return fmt.Errorf("path not allowed: %s", path)
+	return absPath, nil
 }
 
-func isPathWithin(path string, root string) (bool, error) {
-	pathAbs, err := filepath.Abs(path)
+// checkWrite is check for writing size bytes to a file.
+func (g *fsGuard) checkWrite(path string, size int) (string, error) {
+	absPath, err := g.check(path, fspolicy.Write)
 	if err != nil {
-		return false, err
+		return "", err
 	}
-	rootAbs, err := filepath.Abs(root)
-	if err != nil {
-		return false, err
+	if err := g.policy.CheckWriteSize(path, int64(size)); err != nil {
+		return "", err
 	}
-	pathClean := filepath.Clean(pathAbs)
-	rootClean := filepath.Clean(rootAbs)
-	rel, err := filepath.Rel(rootClean, pathClean)
-	if err != nil {
-		return false, err
-	}
-	if rel == "." {
-		return true, nil
-	}
-	if strings.HasPrefix(rel, ".."+string(os.PathSeparator)) || rel == ".." {
-		return false, nil
-	}
-	return true, nil
+	return absPath, nil
 }
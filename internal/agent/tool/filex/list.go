@@ -8,20 +8,23 @@ import (
 
 	"github.com/cloudwego/eino/schema"
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/fspolicy"
 )
 
 type ListTool struct {
 	guard *fsGuard
 }
 
-func NewListTool(workspace string, allowedPaths []string) *ListTool {
-	return &ListTool{guard: newFSGuard(workspace, allowedPaths)}
+func NewListTool(policy *fspolicy.Policy) *ListTool {
+	return &ListTool{guard: newFSGuard(policy)}
 }
 
 func (t *ListTool) Name() string { return "list" }
 
 func (t *ListTool) Description() string { return "List files in an allowed directory" }
 
+func (t *ListTool) ParallelSafe() bool { return true }
+
 func (t *ListTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -38,13 +41,10 @@ func (t *ListTool) ToolInfo() *schema.ToolInfo {
 
 func (t *ListTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	path, _ := args["path"].(string)
-	absPath, err := t.guard.resolvePath(path)
+	absPath, err := t.guard.check(path, fspolicy.Read)
 	if err != nil {
 		return nil, err
 	}
-	if err := t.guard.checkPathAllowed(absPath); err != nil {
-		return nil, err
-	}
 	entries, err := os.ReadDir(absPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list directory: %w", err)
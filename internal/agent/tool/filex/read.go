@@ -7,20 +7,23 @@ import (
 
 	"github.com/cloudwego/eino/schema"
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/fspolicy"
 )
 
 type ReadTool struct {
 	guard *fsGuard
 }
 
-func NewReadTool(workspace string, allowedPaths []string) *ReadTool {
-	return &ReadTool{guard: newFSGuard(workspace, allowedPaths)}
+func NewReadTool(policy *fspolicy.Policy) *ReadTool {
+	return &ReadTool{guard: newFSGuard(policy)}
 }
 
 func (t *ReadTool) Name() string { return "read" }
 
 func (t *ReadTool) Description() string { return "Read file content from an allowed path" }
 
+func (t *ReadTool) ParallelSafe() bool { return true }
+
 func (t *ReadTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -37,13 +40,10 @@ func (t *ReadTool) ToolInfo() *schema.ToolInfo {
 
 func (t *ReadTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	path, _ := args["path"].(string)
-	absPath, err := t.guard.resolvePath(path)
+	absPath, err := t.guard.checkRead(path)
 	if err != nil {
 		return nil, err
 	}
-	if err := t.guard.checkPathAllowed(absPath); err != nil {
-		return nil, err
-	}
 	content, err := os.ReadFile(absPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
@@ -8,20 +8,23 @@ import (
 
 	"github.com/cloudwego/eino/schema"
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/fspolicy"
 )
 
 type WriteTool struct {
 	guard *fsGuard
 }
 
-func NewWriteTool(workspace string, allowedPaths []string) *WriteTool {
-	return &WriteTool{guard: newFSGuard(workspace, allowedPaths)}
+func NewWriteTool(policy *fspolicy.Policy) *WriteTool {
+	return &WriteTool{guard: newFSGuard(policy)}
 }
 
 func (t *WriteTool) Name() string { return "write" }
 
 func (t *WriteTool) Description() string { return "Write content to a file in allowed paths" }
 
+func (t *WriteTool) ParallelSafe() bool { return false }
+
 func (t *WriteTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -47,13 +50,10 @@ func (t *WriteTool) Execute(ctx context.Context, args map[string]interface{}) (i
 	if !ok {
 		return nil, fmt.Errorf("write: missing required parameter 'content'")
 	}
-	absPath, err := t.guard.resolvePath(path)
+	absPath, err := t.guard.checkWrite(path, len(content))
 	if err != nil {
 		return nil, err
 	}
-	if err := t.guard.checkPathAllowed(absPath); err != nil {
-		return nil, err
-	}
 	if err := os.MkdirAll(filepath.Dir(absPath), 0o755); err != nil {
 		return nil, fmt.Errorf("failed to create parent directory: %w", err)
 	}
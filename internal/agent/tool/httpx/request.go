@@ -13,7 +13,7 @@ import (
 	"github.com/cloudwego/eino/schema"
 
 	"github.com/tgifai/friday/internal/pkg/logs"
-	pkgutils "github.com/tgifai/friday/internal/pkg/utils"
+	"github.com/tgifai/friday/internal/security/egress"
 )
 
 const (
@@ -25,8 +25,9 @@ const (
 	userAgent       = "friday-httpx/1.0"
 )
 
-// isPrivateHost is the SSRF check function. Tests may override this.
-var isPrivateHost = pkgutils.IsPrivateHost
+// transport connects through the egress policy of each request's context,
+// which resolves and checks the host before connecting.
+var transport = egress.Transport()
 
 var allowedMethods = map[string]bool{
 	http.MethodGet:    true,
@@ -49,6 +50,8 @@ func (t *RequestTool) Description() string {
 	return "Make an HTTP request to an external API. Supports GET, POST, PUT, PATCH, DELETE methods."
 }
 
+func (t *RequestTool) ParallelSafe() bool { return false }
+
 func (t *RequestTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -101,8 +104,8 @@ func (t *RequestTool) Execute(ctx context.Context, args map[string]interface{})
 	if parsed.Scheme != "http" && parsed.Scheme != "https" {
 		return nil, fmt.Errorf("only http and https URLs are allowed")
 	}
-	if isPrivateHost(parsed.Hostname()) {
-		return nil, fmt.Errorf("access to private/internal addresses is not allowed")
+	if err := egress.FromContext(ctx).CheckHost(parsed.Hostname()); err != nil {
+		return nil, err
 	}
 
 	// Parse and validate method.
@@ -158,11 +161,12 @@ func (t *RequestTool) Execute(ctx context.Context, args map[string]interface{})
 			if len(via) >= maxRedirects {
 				return fmt.Errorf("too many redirects (max %d)", maxRedirects)
 			}
-			if isPrivateHost(r.URL.Hostname()) {
-				return fmt.Errorf("redirect to private address blocked")
+			if err := egress.FromContext(r.Context()).CheckHost(r.URL.Hostname()); err != nil {
+				return fmt.Errorf("redirect blocked: %w", err)
 			}
 			return nil
 		},
+		Transport: transport,
 	}
 
 	resp, err := client.Do(req)
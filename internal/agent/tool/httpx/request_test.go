@@ -8,13 +8,18 @@ import (
 	"testing"
 
 	"github.com/bytedance/sonic"
+
+	"github.com/tgifai/friday/internal/security/egress"
 )
 
 func disableSSRF(t *testing.T) {
 	t.Helper()
-	orig := isPrivateHost
-	isPrivateHost = func(string) bool { return false }
-	t.Cleanup(func() { isPrivateHost = orig })
+	p, err := egress.New(egress.Options{AllowPrivate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	egress.SetDefault(p)
+	t.Cleanup(func() { egress.SetDefault(nil) })
 }
 
 func TestRequestTool_Name(t *testing.T) {
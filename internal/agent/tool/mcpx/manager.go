@@ -4,14 +4,17 @@ import (
 	"context"
 	"fmt"
 	"sync"
+
+	"github.com/tgifai/friday/internal/security/sandbox"
 )
 
 const maxServers = 8
 
 // Manager manages connections to multiple MCP servers.
 type Manager struct {
-	servers map[string]*Server
-	mu      sync.RWMutex
+	servers  map[string]*Server
+	executor sandbox.Executor // sandbox of stdio servers; nil runs them directly
+	mu       sync.RWMutex
 }
 
 // NewManager creates a new connection pool mgr.
@@ -34,6 +37,7 @@ func (m *Manager) Connect(ctx context.Context, name string, cfg ServerConfig) er
 	}
 
 	srv := NewServer(name, cfg)
+	srv.executor = m.executor
 	if err := srv.Connect(ctx); err != nil {
 		return err
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/sandbox"
 )
 
 // MCPTool proxies tool calls to external MCP servers.
@@ -17,10 +18,15 @@ type MCPTool struct {
 	mgr *Manager
 }
 
-// NewMCPTool creates a new MCP tool instance.
-func NewMCPTool() *MCPTool {
+// NewMCPTool creates a new MCP tool instance. With an executor, stdio
+// servers run in that sandbox.
+func NewMCPTool(executor ...sandbox.Executor) *MCPTool {
+	mgr := NewManager()
+	if len(executor) > 0 {
+		mgr.executor = executor[0]
+	}
 	return &MCPTool{
-		mgr: NewManager(),
+		mgr: mgr,
 	}
 }
 
@@ -30,6 +36,8 @@ func (t *MCPTool) Description() string {
 	return "Connect to external MCP (Model Context Protocol) servers and call their tools. Supports stdio and HTTP transports. Use list_servers/list_tools to discover available tools, then call_tool to invoke them."
 }
 
+func (t *MCPTool) ParallelSafe() bool { return false }
+
 func (t *MCPTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
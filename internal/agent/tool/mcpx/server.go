@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/tgifai/friday/internal/security/sandbox"
 )
 
 // ServerStatus represents the connection state of an MCP server.
@@ -27,9 +30,10 @@ type Server struct {
 	Status ServerStatus
 	Error  string // last error message if status == "error"
 
-	client  *mcp.Client
-	session *mcp.ClientSession
-	mu      sync.RWMutex
+	client   *mcp.Client
+	session  *mcp.ClientSession
+	executor sandbox.Executor
+	mu       sync.RWMutex
 }
 
 // NewServer creates a Server instance (not yet connected).
@@ -76,14 +80,11 @@ func (s *Server) Connect(ctx context.Context) error {
 func (s *Server) buildTransport() (mcp.Transport, error) {
 	switch s.Config.Transport {
 	case "stdio":
-		cmd := exec.Command(s.Config.Command, s.Config.Args...)
-		cmd.Stderr = os.Stderr
-		if len(s.Config.Env) > 0 {
-			cmd.Env = os.Environ()
-			for k, v := range s.Config.Env {
-				cmd.Env = append(cmd.Env, k+"="+v)
-			}
+		cmd, err := s.stdioCommand()
+		if err != nil {
+			return nil, err
 		}
+		cmd.Stderr = os.Stderr
 		return &mcp.CommandTransport{Command: cmd}, nil
 	case "http":
 		return &mcp.StreamableClientTransport{Endpoint: s.Config.URL}, nil
@@ -92,6 +93,30 @@ func (s *Server) buildTransport() (mcp.Transport, error) {
 	}
 }
 
+// stdioCommand prepares the server process, in the sandbox when there is
+// one. The server outlives the connecting call, so it gets no context.
+func (s *Server) stdioCommand() (*exec.Cmd, error) {
+	env := make([]string, 0, len(s.Config.Env))
+	for k, v := range s.Config.Env {
+		env = append(env, k+"="+v)
+	}
+	if s.executor != nil {
+		return sandbox.NewCommand(context.Background(), s.executor, &sandbox.ExecRequest{
+			Command: sandbox.Command{
+				Display: strings.Join(append([]string{s.Config.Command}, s.Config.Args...), " "),
+				Program: s.Config.Command,
+				Args:    s.Config.Args,
+			},
+			Env: env,
+		})
+	}
+	cmd := exec.Command(s.Config.Command, s.Config.Args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	return cmd, nil
+}
+
 // ListTools returns the tools available on this server.
 func (s *Server) ListTools(ctx context.Context) ([]mcp.Tool, error) {
 	s.mu.RLock()
@@ -10,6 +10,7 @@ import (
 
 	"github.com/tgifai/friday/internal/channel"
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/redact"
 )
 
 type MessageTool struct{}
@@ -26,6 +27,8 @@ func (t *MessageTool) Description() string {
 	return "Send a message to a specific channel/chat"
 }
 
+func (t *MessageTool) ParallelSafe() bool { return false }
+
 func (t *MessageTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -67,6 +70,8 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]interface{})
 	if err != nil {
 		return nil, fmt.Errorf("channel not found: %s", chanID)
 	}
+	// Arguments reach tools with redacted secrets restored.
+	content = redact.Scrub(ctx, content)
 	if err := ch.SendMessage(ctx, chatID, content); err != nil {
 		return nil, fmt.Errorf("failed to send message: %w", err)
 	}
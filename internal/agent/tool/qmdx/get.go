@@ -26,6 +26,8 @@ func (t *GetTool) Description() string {
 	return "Retrieve a specific document from the local knowledge base by file path or document ID (e.g. #abc123). Use knowledge_search first to find relevant documents, then use this tool only when you need the full content."
 }
 
+func (t *GetTool) ParallelSafe() bool { return true }
+
 func (t *GetTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -30,6 +30,8 @@ func (t *SearchTool) Description() string {
 	return "Search the local knowledge base (markdown docs, notes, meeting transcripts) using hybrid BM25 + vector semantic search. Returns relevant snippets instead of full documents to save tokens."
 }
 
+func (t *SearchTool) ParallelSafe() bool { return true }
+
 func (t *SearchTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -2,7 +2,9 @@ package tool
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/bytedance/sonic"
@@ -11,6 +13,27 @@ import (
 	"github.com/tgifai/friday/internal/pkg/logs"
 )
 
+// ErrNotAllowed is returned for calls to tools the caller may not use.
+var ErrNotAllowed = errors.New("tool not available")
+
+// Filter decides whether a tool may be used in the current turn.
+type Filter func(name string) bool
+
+type filterCtxKey struct{}
+
+// WithFilter restricts the tools available to calls made with the returned
+// context.
+func WithFilter(ctx context.Context, allow Filter) context.Context {
+	return context.WithValue(ctx, filterCtxKey{}, allow)
+}
+
+// FilterFrom returns the tool filter in ctx, or nil when every tool is
+// available.
+func FilterFrom(ctx context.Context) Filter {
+	allow, _ := ctx.Value(filterCtxKey{}).(Filter)
+	return allow
+}
+
 type Registry struct {
 	tools map[string]Tool
 	mu    sync.RWMutex
@@ -60,6 +83,13 @@ func (r *Registry) Get(name string) (Tool, error) {
 	return tool, nil
 }
 
+// ParallelSafe reports whether calls to the named tool may run
+// concurrently. Unknown tools may not.
+func (r *Registry) ParallelSafe(name string) bool {
+	tool, err := r.Get(name)
+	return err == nil && tool.ParallelSafe()
+}
+
 func (r *Registry) List() []Tool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -84,7 +114,27 @@ func (r *Registry) ListToolInfos() []*schema.ToolInfo {
 	return toolInfos
 }
 
+// ListToolInfosFor returns the tools the caller in ctx may use, sorted by
+// name. Without a filter in ctx it returns every tool.
+func (r *Registry) ListToolInfosFor(ctx context.Context) []*schema.ToolInfo {
+	allow := FilterFrom(ctx)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	toolInfos := make([]*schema.ToolInfo, 0, len(r.tools))
+	for name, tool := range r.tools {
+		if allow == nil || allow(name) {
+			toolInfos = append(toolInfos, tool.ToolInfo())
+		}
+	}
+	sort.Slice(toolInfos, func(i, j int) bool { return toolInfos[i].Name < toolInfos[j].Name })
+	return toolInfos
+}
+
 func (r *Registry) Execute(ctx context.Context, toolName string, args map[string]interface{}) (interface{}, error) {
+	if allow := FilterFrom(ctx); allow != nil && !allow(toolName) {
+		return nil, fmt.Errorf("%w: %s is not available to this user", ErrNotAllowed, toolName)
+	}
 	tool, err := r.Get(toolName)
 	if err != nil {
 		return nil, err
@@ -120,6 +170,11 @@ type Tool interface {
 
 	Description() string
 
+	// ParallelSafe reports whether calls to the tool may run concurrently
+	// with other parallel-safe calls of the same model response: the tool
+	// has no side effects that another call could observe or depend on.
+	ParallelSafe() bool
+
 	ToolInfo() *schema.ToolInfo
 
 	Execute(ctx context.Context, args map[string]interface{}) (interface{}, error)
@@ -15,6 +15,7 @@ import (
 
 	"github.com/tgifai/friday/internal/pkg/iobuf"
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/fspolicy"
 	"github.com/tgifai/friday/internal/security/sandbox"
 )
 
@@ -27,6 +28,7 @@ type ExecTool struct {
 	workspace string
 	timeout   time.Duration
 	executor  sandbox.Executor
+	fsPolicy  *fspolicy.Policy
 }
 
 func NewExecTool(workspace string, executor ...sandbox.Executor) *ExecTool {
@@ -41,6 +43,13 @@ func NewExecTool(workspace string, executor ...sandbox.Executor) *ExecTool {
 	}
 }
 
+// WithFSPolicy makes working directories subject to the agent's
+// filesystem policy.
+func (t *ExecTool) WithFSPolicy(policy *fspolicy.Policy) *ExecTool {
+	t.fsPolicy = policy
+	return t
+}
+
 func (t *ExecTool) Name() string {
 	return "exec"
 }
@@ -49,6 +58,8 @@ func (t *ExecTool) Description() string {
 	return "Execute short-lived commands"
 }
 
+func (t *ExecTool) ParallelSafe() bool { return false }
+
 func (t *ExecTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -77,7 +88,10 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]interface{}) (in
 		return nil, err
 	}
 
-	workingDir := resolveWorkDir(t.workspace, args)
+	workingDir, err := checkWorkDir(t.workspace, t.fsPolicy, args)
+	if err != nil {
+		return nil, err
+	}
 	timeout := t.resolveTimeout(args)
 
 	var (
@@ -165,6 +179,27 @@ func resolveWorkDir(workspace string, args map[string]interface{}) string {
 	return wd
 }
 
+// checkWorkDir resolves the working directory of a call. With a
+// filesystem policy, a directory the policy does not allow is an error;
+// without one, resolveWorkDir applies.
+func checkWorkDir(workspace string, policy *fspolicy.Policy, args map[string]interface{}) (string, error) {
+	if policy == nil {
+		return resolveWorkDir(workspace, args), nil
+	}
+	wd, _ := args["working_dir"].(string)
+	if strings.TrimSpace(wd) == "" {
+		wd = workspace
+	}
+	if wd == "" {
+		return "", nil
+	}
+	dir, err := policy.Check(wd, fspolicy.Read)
+	if err != nil {
+		return "", fmt.Errorf("working_dir: %w", err)
+	}
+	return dir, nil
+}
+
 func (t *ExecTool) resolveTimeout(args map[string]interface{}) time.Duration {
 	timeout := t.timeout
 	if timeoutSec := gconv.To[float64](args["timeout"]); timeoutSec > 0 {
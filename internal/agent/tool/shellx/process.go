@@ -15,6 +15,8 @@ import (
 	"github.com/cloudwego/eino/schema"
 
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/fspolicy"
+	"github.com/tgifai/friday/internal/security/sandbox"
 )
 
 const (
@@ -26,6 +28,8 @@ const (
 
 type ProcessTool struct {
 	workspace string
+	executor  sandbox.Executor
+	fsPolicy  *fspolicy.Policy
 
 	mu    sync.RWMutex
 	procs map[string]*managedProcess
@@ -50,13 +54,25 @@ type managedProcess struct {
 	waitErr     string
 }
 
-func NewProcessTool(workspace string) *ProcessTool {
+func NewProcessTool(workspace string, executor ...sandbox.Executor) *ProcessTool {
+	var one sandbox.Executor
+	if len(executor) > 0 {
+		one = executor[0]
+	}
 	return &ProcessTool{
 		workspace: workspace,
+		executor:  one,
 		procs:     make(map[string]*managedProcess),
 	}
 }
 
+// WithFSPolicy makes working directories subject to the agent's
+// filesystem policy.
+func (t *ProcessTool) WithFSPolicy(policy *fspolicy.Policy) *ProcessTool {
+	t.fsPolicy = policy
+	return t
+}
+
 func (t *ProcessTool) Name() string {
 	return "process"
 }
@@ -65,6 +81,8 @@ func (t *ProcessTool) Description() string {
 	return "Manage long-running background processes"
 }
 
+func (t *ProcessTool) ParallelSafe() bool { return false }
+
 func (t *ProcessTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -130,12 +148,14 @@ func (t *ProcessTool) startProcess(ctx context.Context, args map[string]interfac
 		return nil, err
 	}
 
-	workingDir := resolveWorkDir(t.workspace, args)
-	cmd := commandNoContext(parsedCmd)
-	if workingDir != "" {
-		cmd.Dir = workingDir
+	workingDir, err := checkWorkDir(t.workspace, t.fsPolicy, args)
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := t.newCommand(parsedCmd, workingDir)
+	if err != nil {
+		return nil, err
 	}
-	setCommandProcessGroup(cmd)
 
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
@@ -181,6 +201,29 @@ func (t *ProcessTool) startProcess(ctx context.Context, args map[string]interfac
 	}, nil
 }
 
+// newCommand prepares a process, through the sandbox when there is one.
+// It outlives the call that starts it, so it gets no context.
+func (t *ProcessTool) newCommand(parsedCmd *parsedCommand, workingDir string) (*exec.Cmd, error) {
+	if t.executor != nil {
+		return sandbox.NewCommand(context.Background(), t.executor, &sandbox.ExecRequest{
+			Workspace:  t.workspace,
+			WorkingDir: workingDir,
+			Command: sandbox.Command{
+				Display:  parsedCmd.display,
+				Program:  parsedCmd.program,
+				Args:     parsedCmd.argv,
+				UseShell: parsedCmd.useShell,
+			},
+		})
+	}
+	cmd := commandNoContext(parsedCmd)
+	if workingDir != "" {
+		cmd.Dir = workingDir
+	}
+	setCommandProcessGroup(cmd)
+	return cmd, nil
+}
+
 func (t *ProcessTool) statusProcess(args map[string]interface{}) (interface{}, error) {
 	proc, err := t.getProcessByArgs(args)
 	if err != nil {
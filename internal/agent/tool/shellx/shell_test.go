@@ -2,6 +2,7 @@ package shellx
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"path/filepath"
@@ -9,6 +10,9 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/tgifai/friday/internal/security/fspolicy"
+	"github.com/tgifai/friday/internal/security/sandbox"
 )
 
 func TestParseCommandArgSupportsStringAndSlice(t *testing.T) {
@@ -246,6 +250,35 @@ func TestResolveWorkDir(t *testing.T) {
 	})
 }
 
+func TestCheckWorkDirWithPolicy(t *testing.T) {
+	workspace, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := fspolicy.New(fspolicy.Options{
+		Base:      workspace,
+		ReadWrite: []string{"."},
+		Deny:      []string{".git"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := checkWorkDir(workspace, policy, map[string]interface{}{})
+	if err != nil || got != workspace {
+		t.Fatalf("default working dir = %q, %v", got, err)
+	}
+	got, err = checkWorkDir(workspace, policy, map[string]interface{}{"working_dir": "sub"})
+	if err != nil || got != filepath.Join(workspace, "sub") {
+		t.Fatalf("relative working dir = %q, %v", got, err)
+	}
+	for _, wd := range []string{"/", ".git", "../"} {
+		if _, err := checkWorkDir(workspace, policy, map[string]interface{}{"working_dir": wd}); !errors.Is(err, fspolicy.ErrDenied) {
+			t.Fatalf("working dir %q error = %v, want ErrDenied", wd, err)
+		}
+	}
+}
+
 func TestProcessToolActiveLimit(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("unix-focused")
@@ -327,3 +360,50 @@ func TestProcessToolKill(t *testing.T) {
 		time.Sleep(50 * time.Millisecond)
 	}
 }
+
+// runOnlyExecutor can run commands to completion but not keep them running.
+type runOnlyExecutor struct{}
+
+func (runOnlyExecutor) Execute(context.Context, *sandbox.ExecRequest) (*sandbox.ExecResult, error) {
+	return &sandbox.ExecResult{}, nil
+}
+
+func TestProcessToolSandboxed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process lifecycle test is unix-focused")
+	}
+
+	ws := t.TempDir()
+	tl := NewProcessTool(ws, sandbox.NewLocalExecutor(ws))
+	out, err := tl.Execute(context.Background(), map[string]interface{}{
+		"action":  "start",
+		"command": "pwd; sleep 1",
+	})
+	if err != nil {
+		t.Fatalf("start through the sandbox failed: %v", err)
+	}
+	id := out.(map[string]interface{})["process_id"]
+	deadline := time.Now().Add(4 * time.Second)
+	for {
+		logOut, err := tl.Execute(context.Background(), map[string]interface{}{"action": "log", "process_id": id})
+		if err != nil {
+			t.Fatalf("log failed: %v", err)
+		}
+		logRes := logOut.(map[string]interface{})
+		if strings.Contains(logRes["stdout"].(string), filepath.Base(ws)) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("sandboxed process did not run in the workspace, log=%+v", logRes)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	tl = NewProcessTool(ws, runOnlyExecutor{})
+	if _, err := tl.Execute(context.Background(), map[string]interface{}{
+		"action":  "start",
+		"command": "sleep 1",
+	}); err == nil || !strings.Contains(err.Error(), "long-running") {
+		t.Fatalf("start with a run-only sandbox = %v, want an error", err)
+	}
+}
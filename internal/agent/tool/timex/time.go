@@ -24,6 +24,8 @@ func (t *TimeTool) Description() string {
 	return "Get the current date and time. Use this tool to avoid hallucinating dates, times, or weekdays."
 }
 
+func (t *TimeTool) ParallelSafe() bool { return true }
+
 func (t *TimeTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -16,7 +16,7 @@ import (
 	"github.com/cloudwego/eino/schema"
 
 	"github.com/tgifai/friday/internal/pkg/logs"
-	pkgutils "github.com/tgifai/friday/internal/pkg/utils"
+	"github.com/tgifai/friday/internal/security/egress"
 )
 
 const (
@@ -38,13 +38,15 @@ func NewFetchTool() *FetchTool {
 				if len(via) >= fetchMaxRedirs {
 					return fmt.Errorf("too many redirects (max %d)", fetchMaxRedirs)
 				}
-				if pkgutils.IsPrivateHost(req.URL.Hostname()) {
-					return fmt.Errorf("redirect to private address blocked")
+				if err := egress.FromContext(req.Context()).CheckHost(req.URL.Hostname()); err != nil {
+					return fmt.Errorf("redirect blocked: %w", err)
 				}
 				return nil
 			},
-			Timeout:   fetchTimeout,
-			Transport: newCompressedTransport(&http.Transport{ForceAttemptHTTP2: true}),
+			Timeout: fetchTimeout,
+			// Connections go through the egress policy of the calling
+			// agent, so every redirect hop is resolved and checked too.
+			Transport: newCompressedTransport(egress.Transport()),
 		},
 	}
 }
@@ -55,6 +57,8 @@ func (t *FetchTool) Description() string {
 	return "Fetch a URL and extract its main content as markdown. Supports HTML pages (via readability extraction), JSON endpoints, and Cloudflare Browser Rendering for JS-heavy pages."
 }
 
+func (t *FetchTool) ParallelSafe() bool { return true }
+
 func (t *FetchTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
@@ -107,8 +111,10 @@ func (t *FetchTool) Execute(ctx context.Context, args map[string]interface{}) (i
 	if parsed.Scheme != "http" && parsed.Scheme != "https" {
 		return nil, fmt.Errorf("only http and https URLs are allowed")
 	}
-	if pkgutils.IsPrivateHost(parsed.Hostname()) {
-		return nil, fmt.Errorf("access to private/internal addresses is not allowed")
+	// Addresses are checked when connecting; the domain lists apply to
+	// Cloudflare rendering as well.
+	if err := egress.FromContext(ctx).CheckHost(parsed.Hostname()); err != nil {
+		return nil, err
 	}
 
 	// If render_js is requested and Cloudflare renderer is available, use it.
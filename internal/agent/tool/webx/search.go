@@ -45,6 +45,8 @@ func (t *SearchTool) Description() string {
 	return "Search the web. Returns titles, URLs, and snippets for the top results."
 }
 
+func (t *SearchTool) ParallelSafe() bool { return true }
+
 func (t *SearchTool) ToolInfo() *schema.ToolInfo {
 	return &schema.ToolInfo{
 		Name: t.Name(),
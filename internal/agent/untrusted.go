@@ -0,0 +1,189 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/tgifai/friday/internal/agent/session"
+	"github.com/tgifai/friday/internal/agent/tool"
+	"github.com/tgifai/friday/internal/channel"
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/pkg/utils"
+	"github.com/tgifai/friday/internal/security/taint"
+)
+
+const (
+	// trustPendingMetaKey holds the tool last refused for untrusted content,
+	// waiting for /trust; trustApprovedMetaKey the tool /trust allowed for
+	// the next turn.
+	trustPendingMetaKey  = "trust_pending"
+	trustApprovedMetaKey = "trust_approved"
+
+	// taintExtraKey marks tool messages carrying untrusted content with
+	// their taint level.
+	taintExtraKey = "taint"
+)
+
+// untrustedTurn is the taint state of one turn.
+type untrustedTurn struct {
+	policy  taint.Policy
+	tracker taint.Tracker
+	msg     *channel.Message
+
+	mu       sync.Mutex
+	approved string // tool the user allowed once with /trust
+}
+
+// takeApproval consumes the user's one-time approval of name.
+func (t *untrustedTurn) takeApproval(name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.approved != name {
+		return false
+	}
+	t.approved = ""
+	return true
+}
+
+type untrustedCtxKey struct{}
+
+// withUntrustedTurn starts taint tracking for the turn of msg. Untrusted
+// results of earlier turns are still in the prompt, so the turn starts
+// with the taint recorded on them in the session history. A tool the user
+// approved with /trust is taken from the session and valid for this turn
+// only.
+func withUntrustedTurn(ctx context.Context, cfg config.UntrustedConfig, sess *session.Session, msg *channel.Message) context.Context {
+	turn := &untrustedTurn{policy: untrustedPolicy(cfg), msg: msg}
+	for _, m := range sess.History() {
+		if level, _ := m.Extra[taintExtraKey].(string); m.Role == schema.Tool && level != "" {
+			turn.tracker.Mark(m.ToolName, taint.ParseLevel(level))
+		}
+	}
+	if turn.approved = sess.GetMeta(trustApprovedMetaKey); turn.approved != "" {
+		sess.SetMeta(trustApprovedMetaKey, "")
+	}
+	return context.WithValue(ctx, untrustedCtxKey{}, turn)
+}
+
+func untrustedTurnFrom(ctx context.Context) *untrustedTurn {
+	turn, _ := ctx.Value(untrustedCtxKey{}).(*untrustedTurn)
+	return turn
+}
+
+func untrustedPolicy(cfg config.UntrustedConfig) taint.Policy {
+	p := taint.Policy{
+		Sources:  cfg.Sources,
+		Restrict: cfg.Restrict,
+		Action:   cfg.Action,
+		Trigger:  taint.LevelUntrusted,
+	}
+	if len(p.Sources) == 0 {
+		p.Sources = taint.DefaultSources
+	}
+	if len(p.Restrict) == 0 {
+		p.Restrict = taint.DefaultRestrict
+	}
+	if p.Action == "" {
+		p.Action = consts.UntrustedActionDeny
+	}
+	if cfg.Trigger == consts.UntrustedTriggerSuspicious {
+		p.Trigger = taint.LevelSuspicious
+	}
+	return p
+}
+
+// guardUntrusted refuses call when the turn has taken in untrusted content
+// and the policy restricts the tool. With the approve action the user is
+// asked to allow it with /trust.
+func (ag *Agent) guardUntrusted(ctx context.Context, call *schema.ToolCall) error {
+	turn := untrustedTurnFrom(ctx)
+	if turn == nil {
+		return nil
+	}
+	name := call.Function.Name
+	level := turn.tracker.Level()
+	if !turn.policy.Restricts(name, level) {
+		return nil
+	}
+	sources := strings.Join(turn.tracker.Sources(), ", ")
+
+	if turn.policy.Action == consts.UntrustedActionApprove {
+		if turn.takeApproval(name) {
+			logs.CtxInfo(ctx, "[agent:%s] %s allowed after %s content from %s: approved by user", ag.id, name, level, sources)
+			return nil
+		}
+		ag.requestTrust(ctx, turn, call, sources)
+		logs.CtxInfo(ctx, "[agent:%s] %s held for approval after %s content from %s", ag.id, name, level, sources)
+		return fmt.Errorf("%w: %s needs the user's approval because this turn read untrusted content from %s; the user was asked to send /trust, after which they can ask again",
+			tool.ErrNotAllowed, name, sources)
+	}
+
+	logs.CtxInfo(ctx, "[agent:%s] %s denied after %s content from %s", ag.id, name, level, sources)
+	return fmt.Errorf("%w: %s is disabled for the rest of this turn because it read untrusted content from %s",
+		tool.ErrNotAllowed, name, sources)
+}
+
+// requestTrust records call's tool as waiting for /trust and tells the user
+// directly, so injected content cannot talk the model out of asking.
+func (ag *Agent) requestTrust(ctx context.Context, turn *untrustedTurn, call *schema.ToolCall, sources string) {
+	sess := session.ExtractFromCtx(ctx)
+	if sess == nil || turn.msg == nil {
+		return
+	}
+	sess.SetMeta(trustPendingMetaKey, call.Function.Name)
+
+	ch, err := channel.Get(turn.msg.ChannelID)
+	if err != nil {
+		return
+	}
+	text := fmt.Sprintf("⚠️ I wanted to run %s (%s) after reading content from %s, which may try to manipulate me.\nIf you expected this, send /trust and ask again; %s will be allowed once.",
+		call.Function.Name, utils.Truncate80(call.Function.Arguments), sources, call.Function.Name)
+	if err := ch.SendMessage(ctx, turn.msg.ChatID, text, channel.WithThread(turn.msg.ThreadID), channel.WithOrigin(turn.msg.ID)); err != nil {
+		logs.CtxWarn(ctx, "[agent:%s] send trust request failed: %v", ag.id, err)
+	}
+}
+
+// fenceUntrusted wraps the result of an untrusted source tool in a
+// delimited block, marks the turn as tainted and returns the taint level.
+// Results of other tools are returned unchanged with an empty level.
+func (ag *Agent) fenceUntrusted(ctx context.Context, call *schema.ToolCall, content string, callErr error) (string, string) {
+	turn := untrustedTurnFrom(ctx)
+	name := call.Function.Name
+	if turn == nil || !turn.policy.Untrusted(name) || errors.Is(callErr, tool.ErrNotAllowed) {
+		return content, ""
+	}
+
+	findings := taint.Detect(content)
+	level := taint.LevelUntrusted
+	if len(findings) > 0 {
+		level = taint.LevelSuspicious
+		logs.CtxWarn(ctx, "[agent:%s] possible prompt injection in %s result (call_id=%s): %s",
+			ag.id, name, call.ID, strings.Join(findings, ", "))
+	}
+	turn.tracker.Mark(name, level)
+	return taint.Wrap(name, content, findings), level.String()
+}
+
+// TrustTool allows the tool last held for approval to run once in the next
+// turn of the chat. It backs the /trust command.
+func (ag *Agent) TrustTool(ctx context.Context, msg *channel.Message) (string, error) {
+	sess := ag.sessionFor(msg)
+	name := sess.GetMeta(trustPendingMetaKey)
+	if name == "" {
+		return "Nothing is waiting for approval.", nil
+	}
+	sess.SetMeta(trustPendingMetaKey, "")
+	sess.SetMeta(trustApprovedMetaKey, name)
+	if err := ag.sessMgr.Save(sess); err != nil {
+		return "", fmt.Errorf("save session: %w", err)
+	}
+	logs.CtxInfo(ctx, "[agent:%s] user %s approved %s for the next turn of %s", ag.id, msg.UserID, name, sess.SessionKey)
+	return fmt.Sprintf("OK, %s may run once in your next message, even after reading untrusted content.", name), nil
+}
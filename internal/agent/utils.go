@@ -8,19 +8,28 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cloudwego/eino/schema"
 
 	"github.com/tgifai/friday/internal/channel"
+	"github.com/tgifai/friday/internal/provider"
 )
 
 // buildUserMessage constructs a schema.Message from a channel message.
-func buildUserMessage(msg *channel.Message) *schema.Message {
+// provType decides whether documents are sent as native file parts or as
+// extracted text.
+func buildUserMessage(msg *channel.Message, provType provider.Type) *schema.Message {
 	timePrefix := "msg time: " + time.Now().Format(time.RFC3339) + "\n"
+	text := timePrefix + renderAmbientContext(msg.Ambient) + renderReplyContext(msg.ReplyTo) + msg.Content
 
-	if len(msg.Attachments) == 0 {
-		return &schema.Message{Role: schema.User, Content: timePrefix + msg.Content}
+	var replyAttachments []channel.Attachment
+	if msg.ReplyTo != nil {
+		replyAttachments = msg.ReplyTo.Attachments
+	}
+	if len(msg.Attachments) == 0 && len(replyAttachments) == 0 {
+		return &schema.Message{Role: schema.User, Content: text}
 	}
 
 	var parts []schema.MessageInputPart
@@ -28,38 +37,28 @@ func buildUserMessage(msg *channel.Message) *schema.Message {
 	// Always prepend a time text part so the LLM knows when this message arrived.
 	parts = append(parts, schema.MessageInputPart{
 		Type: schema.ChatMessagePartTypeText,
-		Text: timePrefix + msg.Content,
+		Text: text,
 	})
 
-	for _, att := range msg.Attachments {
-		b64 := base64.StdEncoding.EncodeToString(att.Data)
-		switch att.Type {
-		case channel.AttachmentImage:
-			parts = append(parts, schema.MessageInputPart{
-				Type: schema.ChatMessagePartTypeImageURL,
-				Image: &schema.MessageInputImage{
-					MessagePartCommon: schema.MessagePartCommon{
-						Base64Data: &b64,
-						MIMEType:   att.MIMEType,
-					},
-					Detail: schema.ImageURLDetailAuto,
-				},
-			})
-		case channel.AttachmentVoice:
-			// Most LLM providers (Anthropic, Volcengine, etc.) do not support
-			// audio_url content parts. Instead of sending an unsupported type
-			// that would cause the entire request to fail, we add a text note
-			// indicating an audio message was received.
-			name := att.FileName
-			if name == "" {
-				name = "audio"
-			}
+	docBudget := maxDocumentTotalRunes
+	if len(replyAttachments) > 0 {
+		parts = append(parts, schema.MessageInputPart{
+			Type: schema.ChatMessagePartTypeText,
+			Text: "[Attachments of the replied-to message]",
+		})
+		for _, att := range replyAttachments {
+			parts = appendAttachmentParts(parts, att, provType, &docBudget)
+		}
+		if len(msg.Attachments) > 0 {
 			parts = append(parts, schema.MessageInputPart{
 				Type: schema.ChatMessagePartTypeText,
-				Text: fmt.Sprintf("[Audio attachment received: %s (%s), but audio input is not supported by the current model]", name, att.MIMEType),
+				Text: "[Attachments of the current message]",
 			})
 		}
 	}
+	for _, att := range msg.Attachments {
+		parts = appendAttachmentParts(parts, att, provType, &docBudget)
+	}
 
 	return &schema.Message{
 		Role:                  schema.User,
@@ -67,6 +66,110 @@ func buildUserMessage(msg *channel.Message) *schema.Message {
 	}
 }
 
+// appendAttachmentParts renders one attachment as LLM input parts.
+func appendAttachmentParts(parts []schema.MessageInputPart, att channel.Attachment, provType provider.Type, docBudget *int) []schema.MessageInputPart {
+	switch att.Type {
+	case channel.AttachmentImage:
+		b64 := base64.StdEncoding.EncodeToString(att.Data)
+		parts = append(parts, schema.MessageInputPart{
+			Type: schema.ChatMessagePartTypeImageURL,
+			Image: &schema.MessageInputImage{
+				MessagePartCommon: schema.MessagePartCommon{
+					Base64Data: &b64,
+					MIMEType:   att.MIMEType,
+				},
+				Detail: schema.ImageURLDetailAuto,
+			},
+		})
+	case channel.AttachmentVoice:
+		// Most LLM providers (Anthropic, Volcengine, etc.) do not support
+		// audio_url content parts. Instead of sending an unsupported type
+		// that would cause the entire request to fail, we add a text note
+		// indicating an audio message was received.
+		name := att.FileName
+		if name == "" {
+			name = "audio"
+		}
+		parts = append(parts, schema.MessageInputPart{
+			Type: schema.ChatMessagePartTypeText,
+			Text: fmt.Sprintf("[Audio attachment received: %s (%s), but audio input is not supported by the current model]", name, att.MIMEType),
+		})
+	case channel.AttachmentFile:
+		parts = append(parts, buildFileParts(att, provType, docBudget)...)
+	}
+	return parts
+}
+
+// maxReplyContextRunes caps the replied-to text rendered into the prompt.
+const maxReplyContextRunes = 2000
+
+// renderReplyContext describes the replied-to message as a quoted block
+// placed before the user's text. Returns "" when rc is nil.
+func renderReplyContext(rc *channel.ReplyContext) string {
+	if rc == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	switch {
+	case rc.FromBot:
+		b.WriteString("[Replying to your earlier message")
+	case rc.Author != "":
+		fmt.Fprintf(&b, "[Replying to a message from %s", rc.Author)
+	case rc.UserID != "":
+		fmt.Fprintf(&b, "[Replying to a message from user %s", rc.UserID)
+	default:
+		b.WriteString("[Replying to an earlier message")
+	}
+	if rc.MessageID != "" {
+		fmt.Fprintf(&b, " (id %s)", rc.MessageID)
+	}
+	b.WriteString("]\n")
+
+	if content := strings.TrimSpace(rc.Content); content != "" {
+		content, truncated := truncateRunes(content, maxReplyContextRunes)
+		for _, line := range strings.Split(content, "\n") {
+			b.WriteString("> ")
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+		if truncated {
+			b.WriteString("> …\n")
+		}
+	}
+	if quote := strings.TrimSpace(rc.Quote); quote != "" {
+		fmt.Fprintf(&b, "[Quoted part: %q]\n", quote)
+	}
+	return b.String()
+}
+
+// maxAmbientMessageRunes caps each overheard group message in the prompt.
+const maxAmbientMessageRunes = 500
+
+// renderAmbientContext lists recent group messages that were not addressed
+// to the agent, so it can follow the conversation it was pulled into.
+func renderAmbientContext(msgs []channel.AmbientMessage) string {
+	if len(msgs) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("[Recent group messages not addressed to you, oldest first]\n")
+	for _, m := range msgs {
+		author := m.Author
+		if author == "" {
+			author = "user " + m.UserID
+		}
+		content, truncated := truncateRunes(strings.ReplaceAll(strings.TrimSpace(m.Content), "\n", " "), maxAmbientMessageRunes)
+		if truncated {
+			content += "…"
+		}
+		fmt.Fprintf(&b, "- %s %s: %s\n", m.Time.Format("15:04"), author, content)
+	}
+	b.WriteString("[End of group messages]\n")
+	return b.String()
+}
+
 // defaultShell returns the name of the current user's shell.
 func defaultShell() string {
 	if s := os.Getenv("SHELL"); s != "" {
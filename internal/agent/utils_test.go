@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/tgifai/friday/internal/channel"
+	"github.com/tgifai/friday/internal/provider"
+)
+
+func TestRenderReplyContext(t *testing.T) {
+	if got := renderReplyContext(nil); got != "" {
+		t.Errorf("nil context rendered %q", got)
+	}
+
+	got := renderReplyContext(&channel.ReplyContext{
+		MessageID: "42",
+		Author:    "Alice",
+		Content:   "first line\nsecond line",
+		Quote:     "second",
+	})
+	want := "[Replying to a message from Alice (id 42)]\n> first line\n> second line\n[Quoted part: \"second\"]\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	got = renderReplyContext(&channel.ReplyContext{FromBot: true, Author: "Friday", Content: "done"})
+	if !strings.HasPrefix(got, "[Replying to your earlier message]") {
+		t.Errorf("bot reply rendered %q", got)
+	}
+}
+
+func TestBuildUserMessage_ReplyAttachments(t *testing.T) {
+	msg := &channel.Message{
+		Content: "what is this?",
+		ReplyTo: &channel.ReplyContext{
+			Author:      "Bob",
+			Attachments: []channel.Attachment{{Type: channel.AttachmentImage, Data: []byte{1}, MIMEType: "image/png"}},
+		},
+	}
+
+	out := buildUserMessage(msg, provider.OpenAI)
+	if len(out.UserInputMultiContent) != 3 {
+		t.Fatalf("got %d parts, want 3", len(out.UserInputMultiContent))
+	}
+	first := out.UserInputMultiContent[0]
+	if !strings.Contains(first.Text, "[Replying to a message from Bob]") || !strings.HasSuffix(first.Text, "what is this?") {
+		t.Errorf("unexpected text part %q", first.Text)
+	}
+	if out.UserInputMultiContent[2].Type != schema.ChatMessagePartTypeImageURL {
+		t.Errorf("reply image not rendered: %+v", out.UserInputMultiContent[2])
+	}
+}
+
+func TestRenderAmbientContext(t *testing.T) {
+	if got := renderAmbientContext(nil); got != "" {
+		t.Errorf("empty ambient rendered %q", got)
+	}
+
+	at := time.Date(2026, 1, 2, 9, 30, 0, 0, time.Local)
+	got := renderAmbientContext([]channel.AmbientMessage{
+		{Author: "Alice", Content: "lunch at noon?\nor later", Time: at},
+		{UserID: "7", Content: "noon works", Time: at.Add(time.Minute)},
+	})
+	want := "[Recent group messages not addressed to you, oldest first]\n" +
+		"- 09:30 Alice: lunch at noon? or later\n" +
+		"- 09:31 user 7: noon works\n" +
+		"[End of group messages]\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,175 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tgifai/friday/internal/agent/session"
+	"github.com/tgifai/friday/internal/agent/speech"
+	"github.com/tgifai/friday/internal/channel"
+	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/pkg/logs"
+)
+
+const transcriptPrefix = "[Voice message transcript]"
+
+// transcribeVoice replaces voice attachments with their transcript, appended
+// to msg.Content so it reaches the LLM as plain user text and is kept in the
+// session history. Voice notes in the replied-to message are transcribed into
+// the reply context the same way. Attachments that fail to transcribe are
+// left in place and fall back to the "audio not supported" note in
+// buildUserMessage.
+func (ag *Agent) transcribeVoice(ctx context.Context, msg *channel.Message) {
+	if ag.transcriber == nil {
+		return
+	}
+	msg.Content = ag.transcribeInto(ctx, msg.Content, &msg.Attachments)
+	if msg.ReplyTo != nil {
+		msg.ReplyTo.Content = ag.transcribeInto(ctx, msg.ReplyTo.Content, &msg.ReplyTo.Attachments)
+	}
+}
+
+// transcribeInto transcribes the voice attachments in atts, removes the ones
+// that succeeded and returns content with the transcripts appended.
+func (ag *Agent) transcribeInto(ctx context.Context, content string, atts *[]channel.Attachment) string {
+	if len(*atts) == 0 {
+		return content
+	}
+
+	var transcripts []string
+	kept := (*atts)[:0]
+	for _, att := range *atts {
+		if att.Type != channel.AttachmentVoice || len(att.Data) == 0 {
+			kept = append(kept, att)
+			continue
+		}
+		text, err := ag.transcriber.Transcribe(ctx, &speech.Audio{
+			Data:     att.Data,
+			MIMEType: att.MIMEType,
+			FileName: att.FileName,
+		})
+		if err != nil {
+			logs.CtxWarn(ctx, "[agent:%s] transcribe voice via %s: %v", ag.id, ag.transcriber.Name(), err)
+			kept = append(kept, att)
+			continue
+		}
+		if text == "" {
+			text = "(no speech detected)"
+		}
+		logs.CtxDebug(ctx, "[agent:%s] transcribed voice (%d bytes) via %s: %d chars",
+			ag.id, len(att.Data), ag.transcriber.Name(), len(text))
+		transcripts = append(transcripts, transcriptPrefix+" "+text)
+	}
+	*atts = kept
+
+	if len(transcripts) == 0 {
+		return content
+	}
+	if content = strings.TrimSpace(content); content != "" {
+		transcripts = append([]string{content}, transcripts...)
+	}
+	return strings.Join(transcripts, "\n\n")
+}
+
+// voiceReplyMetaKey stores the per-chat override of voice.tts.reply.
+const voiceReplyMetaKey = "voice_reply"
+
+const defaultVoiceMaxChars = 1500
+
+func hasVoice(msg *channel.Message) bool {
+	for _, att := range msg.Attachments {
+		if att.Type == channel.AttachmentVoice {
+			return true
+		}
+	}
+	return false
+}
+
+// voiceReplyMode resolves the effective reply mode for a session: the chat
+// override set via /voice wins over the agent default.
+func (ag *Agent) voiceReplyMode(sess *session.Session) string {
+	if mode := sess.GetMeta(voiceReplyMetaKey); mode != "" {
+		return mode
+	}
+	if ag.ttsCfg.Reply != "" {
+		return ag.ttsCfg.Reply
+	}
+	return consts.VoiceReplyInbound
+}
+
+// maybeVoiceReply synthesizes a voice rendition of resp when the reply mode
+// asks for it and the channel can deliver voice. Code blocks and long tables
+// stay text; replies with too much prose to listen to are left as text.
+func (ag *Agent) maybeVoiceReply(ctx context.Context, sess *session.Session, msg *channel.Message, resp *channel.Response, inboundVoice bool) {
+	if ag.synthesizer == nil || resp.Content == "" {
+		return
+	}
+	switch ag.voiceReplyMode(sess) {
+	case consts.VoiceReplyAlways:
+	case consts.VoiceReplyInbound:
+		if !inboundVoice {
+			return
+		}
+	default:
+		return
+	}
+	ch, err := channel.Get(msg.ChannelID)
+	if err != nil {
+		return
+	}
+	if _, ok := ch.(channel.VoiceSender); !ok {
+		return
+	}
+
+	spoken, textBlocks := speech.SplitSpeakable(resp.Content)
+	maxChars := ag.ttsCfg.MaxChars
+	if maxChars <= 0 {
+		maxChars = defaultVoiceMaxChars
+	}
+	if spoken == "" || utf8.RuneCountInString(spoken) > maxChars {
+		return
+	}
+
+	audio, err := ag.synthesizer.Synthesize(ctx, spoken)
+	if err != nil {
+		logs.CtxWarn(ctx, "[agent:%s] synthesize voice reply via %s: %v", ag.id, ag.synthesizer.Name(), err)
+		return
+	}
+	resp.Voice = &channel.VoiceReply{
+		Audio: channel.Attachment{
+			Type:     channel.AttachmentVoice,
+			Data:     audio.Data,
+			MIMEType: audio.MIMEType,
+			FileName: audio.FileName,
+		},
+		Text: strings.Join(textBlocks, "\n\n"),
+	}
+}
+
+// SetVoiceReply updates the voice reply mode for the message's chat. An empty
+// mode reports the current setting; "default" removes the chat override.
+func (ag *Agent) SetVoiceReply(ctx context.Context, msg *channel.Message, mode string) (string, error) {
+	if ag.synthesizer == nil {
+		return "Voice replies are not configured for this agent (agents.<id>.voice.tts).", nil
+	}
+
+	sess := ag.sessionFor(msg)
+	switch mode {
+	case "":
+		return fmt.Sprintf("Voice replies: %s", ag.voiceReplyMode(sess)), nil
+	case "default":
+		sess.SetMeta(voiceReplyMetaKey, "")
+	case consts.VoiceReplyOff, consts.VoiceReplyInbound, consts.VoiceReplyAlways:
+		sess.SetMeta(voiceReplyMetaKey, mode)
+	default:
+		return "", fmt.Errorf("unknown voice reply mode %q", mode)
+	}
+
+	if err := ag.sessMgr.Save(sess); err != nil {
+		return "", fmt.Errorf("save session: %w", err)
+	}
+	logs.CtxInfo(ctx, "[agent:%s] voice reply mode for %s set to %q", ag.id, sess.SessionKey, mode)
+	return fmt.Sprintf("Voice replies: %s", ag.voiceReplyMode(sess)), nil
+}
@@ -0,0 +1,99 @@
+package channel
+
+import (
+	"sync"
+	"time"
+)
+
+// AmbientMessage is a group message the bot saw but was not addressed to.
+type AmbientMessage struct {
+	UserID  string
+	Author  string
+	Content string
+	Time    time.Time
+}
+
+// AmbientBuffer keeps a bounded rolling window of unaddressed group messages
+// per chat, so the agent can catch up on the conversation when it is finally
+// mentioned.
+//
+// Privacy: the buffer lives in memory only and is lost on restart. It holds
+// text, never media; entries expire after maxAge and each chat keeps at most
+// maxMessages. Drain hands the entries to the agent and forgets them, so a
+// message is injected into a session at most once.
+type AmbientBuffer struct {
+	maxMessages int
+	maxAge      time.Duration
+
+	mu        sync.Mutex
+	chats     map[string][]AmbientMessage
+	lastSweep time.Time
+}
+
+func NewAmbientBuffer(maxMessages int, maxAge time.Duration) *AmbientBuffer {
+	return &AmbientBuffer{
+		maxMessages: maxMessages,
+		maxAge:      maxAge,
+		chats:       make(map[string][]AmbientMessage),
+		lastSweep:   time.Now(),
+	}
+}
+
+// Add records an unaddressed message for chatID, evicting the oldest entries
+// beyond the per-chat limit.
+func (b *AmbientBuffer) Add(chatID string, m AmbientMessage) {
+	if m.Content == "" {
+		return
+	}
+	if m.Time.IsZero() {
+		m.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := append(b.chats[chatID], m)
+	if over := len(entries) - b.maxMessages; over > 0 {
+		entries = append(entries[:0:0], entries[over:]...)
+	}
+	b.chats[chatID] = entries
+
+	// Drop chats that went quiet so the map does not grow without bound.
+	if now := time.Now(); now.Sub(b.lastSweep) >= b.maxAge {
+		for id, msgs := range b.chats {
+			if kept := b.unexpired(msgs, now); len(kept) == 0 {
+				delete(b.chats, id)
+			} else {
+				b.chats[id] = kept
+			}
+		}
+		b.lastSweep = now
+	}
+}
+
+// Drain returns the last n unexpired messages of chatID, oldest first, and
+// clears the chat's buffer.
+func (b *AmbientBuffer) Drain(chatID string, n int) []AmbientMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := b.unexpired(b.chats[chatID], time.Now())
+	delete(b.chats, chatID)
+	if n > 0 && len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return entries
+}
+
+// unexpired returns the suffix of msgs younger than maxAge.
+func (b *AmbientBuffer) unexpired(msgs []AmbientMessage, now time.Time) []AmbientMessage {
+	for i, m := range msgs {
+		if now.Sub(m.Time) < b.maxAge {
+			return msgs[i:]
+		}
+	}
+	return nil
+}
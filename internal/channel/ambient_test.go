@@ -0,0 +1,33 @@
+package channel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAmbientBuffer_KeepsLatest(t *testing.T) {
+	b := NewAmbientBuffer(3, time.Hour)
+	for _, text := range []string{"a", "b", "c", "d"} {
+		b.Add("chat", AmbientMessage{Content: text})
+	}
+
+	got := b.Drain("chat", 2)
+	if len(got) != 2 || got[0].Content != "c" || got[1].Content != "d" {
+		t.Fatalf("Drain = %+v, want [c d]", got)
+	}
+	if again := b.Drain("chat", 2); again != nil {
+		t.Errorf("second Drain = %+v, want nil", again)
+	}
+}
+
+func TestAmbientBuffer_Expires(t *testing.T) {
+	b := NewAmbientBuffer(10, time.Minute)
+	b.Add("chat", AmbientMessage{Content: "old", Time: time.Now().Add(-2 * time.Minute)})
+	b.Add("chat", AmbientMessage{Content: "new"})
+	b.Add("other", AmbientMessage{Content: "elsewhere"})
+
+	got := b.Drain("chat", 0)
+	if len(got) != 1 || got[0].Content != "new" {
+		t.Fatalf("Drain = %+v, want [new]", got)
+	}
+}
@@ -39,18 +39,60 @@ type Attachment struct {
 	Data     []byte
 	MIMEType string // e.g. "image/jpeg", "audio/ogg"
 	FileName string
+	// LocalPath is the workspace copy of a file attachment, set by the agent
+	// once the upload has been saved so tools can operate on it.
+	LocalPath string
 }
 
+// MessageEvent tells edits and deletions apart from new messages.
+type MessageEvent string
+
+const (
+	EventEdited  MessageEvent = "edited"  // the user edited an earlier message; Content is the new text
+	EventDeleted MessageEvent = "deleted" // the user deleted or recalled an earlier message
+)
+
 type Message struct {
 	ID          string
 	ChannelID   string // ChannelID
 	ChannelType Type
 	UserID      string
 	ChatID      string
+	// ThreadID identifies a forum topic (Telegram) or thread (Lark) inside
+	// the chat. Empty for messages outside threads.
+	ThreadID    string
 	Content     string
 	SessionKey  string
 	Metadata    map[string]string
 	Attachments []Attachment
+	// ReplyTo is the message the user replied to or quoted, if any.
+	ReplyTo *ReplyContext
+	// Event is set when the message updates an earlier one with the same ID;
+	// empty for new messages.
+	Event MessageEvent
+	// Ambient holds recent group messages that were not addressed to the
+	// bot, oldest first. The gateway sets it from an AmbientSource after the
+	// security check.
+	Ambient []AmbientMessage
+	// Role is the sender's role in the channel (owner, admin, member,
+	// guest), resolved by the gateway after the security check. Empty for
+	// messages the system generates itself, such as cron jobs.
+	Role string
+}
+
+// ReplyContext describes the earlier message a user replied to, so the agent
+// can resolve references like "this" or "that one".
+type ReplyContext struct {
+	MessageID string
+	UserID    string
+	Author    string // display name of the original sender, if known
+	// FromBot is true when the user replied to one of the bot's own messages.
+	FromBot bool
+	Content string // text or caption of the replied-to message
+	// Quote is the excerpt the user explicitly highlighted, when the platform
+	// supports partial quotes (e.g. Telegram).
+	Quote       string
+	Attachments []Attachment
 }
 
 type Response struct {
@@ -61,6 +103,17 @@ type Response struct {
 	Metadata map[string]string
 	Model    string
 	Provider string
+	// Voice is an optional spoken rendition of Content. Channels that
+	// implement VoiceSender deliver it in place of Content.
+	Voice *VoiceReply
+}
+
+// VoiceReply is a synthesized voice answer. Text carries the parts of the
+// reply that were not spoken (code blocks, long tables) and is sent as a
+// regular message after the voice note.
+type VoiceReply struct {
+	Audio Attachment
+	Text  string
 }
 
 type ChatAction string
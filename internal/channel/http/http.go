@@ -23,6 +23,8 @@ const (
 	maxImageSize = 3 * 1024 * 1024
 	// maxVoiceSize is the upper bound for inline audio (1 MB raw).
 	maxVoiceSize = 1 * 1024 * 1024
+	// maxFileSize is the upper bound for inline documents (10 MB raw).
+	maxFileSize = 10 * 1024 * 1024
 	// responseTimeout is how long the HTTP handler waits for the agent reply.
 	responseTimeout = 5 * time.Minute
 )
@@ -39,7 +41,7 @@ type inboundRequest struct {
 }
 
 type inboundAttachment struct {
-	Type     string `json:"type"`      // "image" or "voice"
+	Type     string `json:"type"`      // "image", "voice" or "file"
 	Data     string `json:"data"`      // base64-encoded binary
 	MIMEType string `json:"mime_type"` // e.g. "image/jpeg"
 	FileName string `json:"file_name,omitempty"`
@@ -263,7 +265,7 @@ func (h *HTTP) decodeAttachments(in []inboundAttachment) ([]channel.Attachment,
 	for _, a := range in {
 		attType := channel.AttachmentType(a.Type)
 		switch attType {
-		case channel.AttachmentImage, channel.AttachmentVoice:
+		case channel.AttachmentImage, channel.AttachmentVoice, channel.AttachmentFile:
 		default:
 			return nil, fmt.Errorf("unsupported attachment type: %s", a.Type)
 		}
@@ -283,6 +285,10 @@ func (h *HTTP) decodeAttachments(in []inboundAttachment) ([]channel.Attachment,
 			if len(data) > maxVoiceSize {
 				continue
 			}
+		case channel.AttachmentFile:
+			if len(data) > maxFileSize {
+				continue
+			}
 		}
 
 		out = append(out, channel.Attachment{
@@ -27,6 +27,32 @@ type CommandRegistrar interface {
 	DeleteCommands(ctx context.Context) error
 }
 
+// VoiceSender is an opt-in interface for channels that can deliver audio
+// as a native voice message (e.g. Telegram sendVoice, Lark audio messages).
+type VoiceSender interface {
+	SendVoice(ctx context.Context, chatID string, audio Attachment, opts ...SendOption) error
+}
+
+// MessageRetractor is an opt-in interface for channels that can delete the
+// messages the bot sent in answer to an inbound message (see WithOrigin).
+type MessageRetractor interface {
+	RetractReplies(ctx context.Context, chatID string, originID string) error
+}
+
+// MemberChecker is an opt-in interface for channels that can tell whether a
+// user belongs to a group chat (e.g. Telegram getChatMember). ACL rules with
+// owner_present use it.
+type MemberChecker interface {
+	IsChatMember(ctx context.Context, chatID string, userID string) (bool, error)
+}
+
+// AmbientSource is an opt-in interface for channels that overhear group
+// messages not addressed to the bot. The gateway drains it only once it has
+// accepted a message, so a rejected sender does not consume the context.
+type AmbientSource interface {
+	DrainAmbient(chatID string, threadID string) []AmbientMessage
+}
+
 // Channel defines a runtime adapter between Friday and a chat platform.
 // Implementations are responsible for receiving inbound events and sending
 // outbound responses for a specific channel provider (for example Telegram).
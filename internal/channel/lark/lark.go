@@ -1,6 +1,7 @@
 package lark
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -29,9 +30,31 @@ const (
 	maxImageSize = 3 * 1024 * 1024
 	// maxVoiceSize is the upper bound for downloading voice/audio (1 MB).
 	maxVoiceSize = 1 * 1024 * 1024
+	// maxFileSize is the upper bound for downloading files (10 MB).
+	maxFileSize = 10 * 1024 * 1024
 )
 
-var _ channel.Channel = (*Lark)(nil)
+var (
+	_ channel.Channel          = (*Lark)(nil)
+	_ channel.MessageRetractor = (*Lark)(nil)
+)
+
+// maxTrackedMessages bounds the per-message bookkeeping used for edits and
+// recalls.
+const maxTrackedMessages = 1000
+
+// eventMessageUpdated is the "message edited" event of the Lark/Feishu open
+// platform event list (event_type im.message.updated_v1). SDK v3.5.3 has no
+// typed handler for it; its payload mirrors im.message.receive_v1.
+const eventMessageUpdated = "im.message.updated_v1"
+
+// inboundRef remembers where an inbound message came from, since recall
+// events only carry the message and chat IDs.
+type inboundRef struct {
+	userID   string
+	chatID   string
+	threadID string
+}
 
 type Lark struct {
 	id      string
@@ -46,6 +69,14 @@ type Lark struct {
 
 	// ws mode
 	wsClient *larkws.Client // nil in webhook mode
+
+	// threadRoots maps a thread ID to its root message ID so that replies
+	// without an explicit target can still be posted into the thread.
+	threadRoots sync.Map
+	// inbound remembers the sender of recent messages for recall events.
+	inbound *channel.Recent[inboundRef]
+	// sent maps an inbound message ID to the bot messages answering it.
+	sent *channel.Recent[[]string]
 }
 
 func NewChannel(chanId string, chCfg *config.ChannelConfig) (channel.Channel, error) {
@@ -63,18 +94,15 @@ func NewChannel(chanId string, chCfg *config.ChannelConfig) (channel.Channel, er
 	)
 
 	l := &Lark{
-		id:     chanId,
-		config: *cfg,
-		client: client,
+		id:      chanId,
+		config:  *cfg,
+		client:  client,
+		inbound: channel.NewRecent[inboundRef](maxTrackedMessages),
+		sent:    channel.NewRecent[[]string](maxTrackedMessages),
 	}
 
 	// Both modes share the same event dispatcher and message handler.
-	eventDispatcher := dispatcher.NewEventDispatcher(cfg.VerificationToken, cfg.EncryptKey)
-	eventDispatcher.InitConfig(
-		larkevent.WithLogger(larkLogger),
-		larkevent.WithLogLevel(larkLogLevel),
-	)
-	eventDispatcher.OnP2MessageReceiveV1(l.onMessageReceive)
+	eventDispatcher := l.newEventDispatcher(larkLogger, larkLogLevel)
 
 	switch strings.ToLower(cfg.Mode) {
 	case "ws":
@@ -91,6 +119,20 @@ func NewChannel(chanId string, chCfg *config.ChannelConfig) (channel.Channel, er
 	return l, nil
 }
 
+// newEventDispatcher routes the events the channel subscribes to to their
+// handlers.
+func (l *Lark) newEventDispatcher(logger larkcore.Logger, level larkcore.LogLevel) *dispatcher.EventDispatcher {
+	d := dispatcher.NewEventDispatcher(l.config.VerificationToken, l.config.EncryptKey)
+	d.InitConfig(
+		larkevent.WithLogger(logger),
+		larkevent.WithLogLevel(level),
+	)
+	d.OnP2MessageReceiveV1(l.onMessageReceive)
+	d.OnP2MessageRecalledV1(l.onMessageRecalled)
+	d.OnCustomizedEvent(eventMessageUpdated, l.onMessageUpdated)
+	return d
+}
+
 // Routes implements channel.RouteProvider.
 func (l *Lark) Routes() []channel.Route {
 	if l.wsClient != nil {
@@ -185,16 +227,57 @@ func (l *Lark) SendMessage(ctx context.Context, chatID, content string, opts ...
 	}
 	serialized, _ := sonic.MarshalString(post)
 
-	o := channel.ApplySendOptions(opts)
+	return l.send(ctx, chatID, larkim.MsgTypePost, serialized, channel.ApplySendOptions(opts))
+}
+
+// SendVoice implements channel.VoiceSender. The clip is uploaded as an opus
+// file and posted as an audio message; Lark only plays Ogg/Opus audio.
+func (l *Lark) SendVoice(ctx context.Context, chatID string, audio channel.Attachment, opts ...channel.SendOption) error {
+	if audio.MIMEType != "" && audio.MIMEType != "audio/ogg" && audio.MIMEType != "audio/opus" {
+		return fmt.Errorf("lark audio messages require ogg/opus, got %s", audio.MIMEType)
+	}
+
+	fileName := audio.FileName
+	if fileName == "" {
+		fileName = "voice.opus"
+	}
+	upload, err := l.client.Im.File.Create(ctx,
+		larkim.NewCreateFileReqBuilder().
+			Body(larkim.NewCreateFileReqBodyBuilder().
+				FileType(larkim.FileTypeOpus).
+				FileName(fileName).
+				File(bytes.NewReader(audio.Data)).
+				Build()).
+			Build())
+	if err != nil {
+		return fmt.Errorf("lark upload audio: %w", err)
+	}
+	if !upload.Success() || upload.Data == nil || upload.Data.FileKey == nil {
+		return fmt.Errorf("lark upload audio failed: code=%d msg=%s", upload.Code, upload.Msg)
+	}
+
+	content, _ := sonic.MarshalString(map[string]string{"file_key": *upload.Data.FileKey})
+	return l.send(ctx, chatID, larkim.MsgTypeAudio, content, channel.ApplySendOptions(opts))
+}
 
-	// Use Reply API when replying to a specific message.
-	if o.ReplyToMsgID != "" {
+// send posts a message, using the Reply API when replying to a specific message
+// or posting into a thread.
+func (l *Lark) send(ctx context.Context, chatID, msgType, content string, o channel.SendOptions) error {
+	replyTo := o.ReplyToMsgID
+	if replyTo == "" && o.ThreadID != "" {
+		if root, ok := l.threadRoots.Load(o.ThreadID); ok {
+			replyTo = root.(string)
+		}
+	}
+
+	if replyTo != "" {
 		resp, err := l.client.Im.Message.Reply(ctx,
 			larkim.NewReplyMessageReqBuilder().
-				MessageId(o.ReplyToMsgID).
+				MessageId(replyTo).
 				Body(larkim.NewReplyMessageReqBodyBuilder().
-					MsgType(larkim.MsgTypePost).
-					Content(serialized).
+					MsgType(msgType).
+					Content(content).
+					ReplyInThread(o.ThreadID != "").
 					Build()).
 				Build())
 		if err != nil {
@@ -203,6 +286,9 @@ func (l *Lark) SendMessage(ctx context.Context, chatID, content string, opts ...
 		if !resp.Success() {
 			return fmt.Errorf("lark reply message failed: code=%d msg=%s", resp.Code, resp.Msg)
 		}
+		if resp.Data != nil {
+			l.trackSent(o.OriginID, resp.Data.MessageId)
+		}
 		return nil
 	}
 
@@ -210,9 +296,9 @@ func (l *Lark) SendMessage(ctx context.Context, chatID, content string, opts ...
 		larkim.NewCreateMessageReqBuilder().
 			ReceiveIdType(larkim.ReceiveIdTypeChatId).
 			Body(larkim.NewCreateMessageReqBodyBuilder().
-				MsgType(larkim.MsgTypePost).
+				MsgType(msgType).
 				ReceiveId(chatID).
-				Content(serialized).
+				Content(content).
 				Build()).
 			Build())
 	if err != nil {
@@ -221,9 +307,38 @@ func (l *Lark) SendMessage(ctx context.Context, chatID, content string, opts ...
 	if !resp.Success() {
 		return fmt.Errorf("lark send message failed: code=%d msg=%s", resp.Code, resp.Msg)
 	}
+	if resp.Data != nil {
+		l.trackSent(o.OriginID, resp.Data.MessageId)
+	}
 	return nil
 }
 
+// trackSent remembers a message sent in answer to originID.
+func (l *Lark) trackSent(originID string, messageID *string) {
+	if originID == "" || messageID == nil || *messageID == "" {
+		return
+	}
+	l.sent.Update(originID, func(ids []string) []string {
+		return append(ids, *messageID)
+	})
+}
+
+// RetractReplies implements channel.MessageRetractor by recalling the bot
+// messages sent in answer to originID.
+func (l *Lark) RetractReplies(ctx context.Context, _ string, originID string) error {
+	ids, _ := l.sent.Take(originID)
+	var errs []error
+	for _, id := range ids {
+		resp, err := l.client.Im.Message.Delete(ctx, larkim.NewDeleteMessageReqBuilder().MessageId(id).Build())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("lark delete message %s: %w", id, err))
+		} else if !resp.Success() {
+			errs = append(errs, fmt.Errorf("lark delete message %s failed: code=%d msg=%s", id, resp.Code, resp.Msg))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (l *Lark) SendChatAction(_ context.Context, _ string, _ channel.ChatAction) error {
 	return channel.ErrUnsupportedOperation
 }
@@ -296,16 +411,76 @@ func (l *Lark) RegisterMessageHandler(handler func(ctx context.Context, msg *cha
 // prevent subsequent messages from being processed, which is the root cause
 // of the "Lark WS gets stuck" symptom.
 func (l *Lark) onMessageReceive(_ context.Context, event *larkim.P2MessageReceiveV1) error {
+	if event.Event == nil {
+		return nil
+	}
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 		defer cancel()
-		l.handleMessage(ctx, event)
+		l.handleMessage(ctx, event.Event, "")
 	}()
 	return nil
 }
 
-func (l *Lark) handleMessage(ctx context.Context, event *larkim.P2MessageReceiveV1) {
-	msg := event.Event.Message
+// onMessageUpdated handles message edits. Like onMessageReceive it returns
+// immediately and processes the edit in the background.
+func (l *Lark) onMessageUpdated(_ context.Context, req *larkevent.EventReq) error {
+	var event struct {
+		Event *larkim.P2MessageReceiveV1Data `json:"event"`
+	}
+	if err := sonic.Unmarshal(req.Body, &event); err != nil {
+		return fmt.Errorf("decode %s: %w", eventMessageUpdated, err)
+	}
+	if event.Event == nil {
+		return nil
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+		l.handleMessage(ctx, event.Event, channel.EventEdited)
+	}()
+	return nil
+}
+
+// onMessageRecalled forwards recalls so the message leaves session history.
+func (l *Lark) onMessageRecalled(ctx context.Context, event *larkim.P2MessageRecalledV1) error {
+	if event.Event == nil || event.Event.MessageId == nil {
+		return nil
+	}
+	messageID := *event.Event.MessageId
+	ref, ok := l.inbound.Take(messageID)
+	if !ok {
+		// Not a message we handled recently.
+		return nil
+	}
+
+	chatID := ref.chatID
+	if event.Event.ChatId != nil && *event.Event.ChatId != "" {
+		chatID = *event.Event.ChatId
+	}
+
+	l.mu.RLock()
+	handler := l.handler
+	l.mu.RUnlock()
+	if handler == nil {
+		return nil
+	}
+	return handler(ctx, &channel.Message{
+		ID:          messageID,
+		ChannelID:   l.id,
+		ChannelType: channel.Lark,
+		UserID:      ref.userID,
+		ChatID:      chatID,
+		ThreadID:    ref.threadID,
+		Metadata:    map[string]string{},
+		Event:       channel.EventDeleted,
+	})
+}
+
+// handleMessage normalizes a received or edited message and forwards it to
+// the registered handler. event is empty for new messages.
+func (l *Lark) handleMessage(ctx context.Context, data *larkim.P2MessageReceiveV1Data, event channel.MessageEvent) {
+	msg := data.Message
 	if msg == nil || msg.MessageId == nil {
 		return
 	}
@@ -315,28 +490,138 @@ func (l *Lark) handleMessage(ctx context.Context, event *larkim.P2MessageReceive
 		msgType = *msg.MessageType
 	}
 
-	var content string
-	var attachments []channel.Attachment
+	content, attachments, ok := l.parseContent(ctx, *msg.MessageId, msgType, msg.Content, msg.Mentions)
+	if !ok {
+		return
+	}
 
+	if content == "" && len(attachments) == 0 {
+		return
+	}
+
+	var userID string
+	if data.Sender != nil && data.Sender.SenderId != nil && data.Sender.SenderId.OpenId != nil {
+		userID = *data.Sender.SenderId.OpenId
+	}
+
+	var chatID string
+	if msg.ChatId != nil {
+		chatID = *msg.ChatId
+	}
+
+	metadata := map[string]string{}
+	if msg.MessageType != nil {
+		metadata["message_type"] = *msg.MessageType
+	}
+	if msg.ChatType != nil {
+		metadata["chat_type"] = *msg.ChatType
+	}
+
+	var threadID string
+	if msg.ThreadId != nil && *msg.ThreadId != "" {
+		threadID = *msg.ThreadId
+		root := *msg.MessageId
+		if msg.RootId != nil && *msg.RootId != "" {
+			root = *msg.RootId
+		}
+		l.threadRoots.Store(threadID, root)
+	}
+
+	channelMsg := &channel.Message{
+		ID:          *msg.MessageId,
+		ChannelID:   l.id,
+		ChannelType: channel.Lark,
+		UserID:      userID,
+		ChatID:      chatID,
+		ThreadID:    threadID,
+		Content:     content,
+		Metadata:    metadata,
+		Attachments: attachments,
+	}
+	if msg.ParentId != nil && *msg.ParentId != "" {
+		channelMsg.ReplyTo = l.fetchReplyContext(ctx, *msg.ParentId)
+	}
+	channelMsg.Event = event
+	l.inbound.Set(channelMsg.ID, inboundRef{userID: userID, chatID: chatID, threadID: threadID})
+
+	l.mu.RLock()
+	handler := l.handler
+	l.mu.RUnlock()
+
+	if handler != nil {
+		if err := handler(ctx, channelMsg); err != nil {
+			logs.CtxError(ctx, "[channel:lark] error handling message: %v", err)
+		}
+	}
+}
+
+// fetchReplyContext loads the parent message of a reply (or thread reply)
+// so the agent can see what the user is responding to. Failures are logged
+// and yield a context that only carries the message ID.
+func (l *Lark) fetchReplyContext(ctx context.Context, parentID string) *channel.ReplyContext {
+	rc := &channel.ReplyContext{MessageID: parentID}
+
+	resp, err := l.client.Im.Message.Get(ctx, larkim.NewGetMessageReqBuilder().MessageId(parentID).Build())
+	if err != nil {
+		logs.CtxWarn(ctx, "[channel:lark] get parent message %s: %v", parentID, err)
+		return rc
+	}
+	if !resp.Success() || resp.Data == nil || len(resp.Data.Items) == 0 {
+		logs.CtxWarn(ctx, "[channel:lark] get parent message %s failed: code=%d msg=%s", parentID, resp.Code, resp.Msg)
+		return rc
+	}
+
+	parent := resp.Data.Items[0]
+	if parent.Deleted != nil && *parent.Deleted {
+		rc.Content = "(message was recalled)"
+		return rc
+	}
+	if sender := parent.Sender; sender != nil {
+		if sender.SenderType != nil && *sender.SenderType == "app" {
+			rc.FromBot = sender.Id != nil && *sender.Id == l.config.AppID
+		}
+		if sender.Id != nil && !rc.FromBot {
+			rc.UserID = *sender.Id
+		}
+	}
+
+	var msgType string
+	if parent.MsgType != nil {
+		msgType = *parent.MsgType
+	}
+	var raw *string
+	if parent.Body != nil {
+		raw = parent.Body.Content
+	}
+	if content, attachments, ok := l.parseContent(ctx, parentID, msgType, raw, nil); ok {
+		rc.Content = content
+		rc.Attachments = attachments
+	}
+	return rc
+}
+
+// parseContent converts a Lark message body into text and downloaded
+// attachments. ok is false for unsupported or malformed messages.
+func (l *Lark) parseContent(ctx context.Context, messageID, msgType string, raw *string, mentions []*larkim.MentionEvent) (content string, attachments []channel.Attachment, ok bool) {
 	switch msgType {
 	case "text":
-		text, err := extractText(msg.Content)
+		text, err := extractText(raw)
 		if err != nil {
 			logs.CtxWarn(ctx, "[channel:lark] failed to extract text: %v", err)
-			return
+			return "", nil, false
 		}
 		// Strip @mention placeholders (e.g. @_user_1) from group messages.
-		content = stripMentionPlaceholders(text, msg.Mentions)
+		content = stripMentionPlaceholders(text, mentions)
 
 	case "post":
-		text, imageKeys, err := extractPost(msg.Content)
+		text, imageKeys, err := extractPost(raw)
 		if err != nil {
 			logs.CtxWarn(ctx, "[channel:lark] failed to extract post: %v", err)
-			return
+			return "", nil, false
 		}
-		content = stripMentionPlaceholders(text, msg.Mentions)
+		content = stripMentionPlaceholders(text, mentions)
 		for _, key := range imageKeys {
-			att, dlErr := l.downloadResource(ctx, *msg.MessageId, key, "image", channel.AttachmentImage, "image/png")
+			att, dlErr := l.downloadResource(ctx, messageID, key, "image", channel.AttachmentImage, "image/png")
 			if dlErr != nil {
 				logs.CtxWarn(ctx, "[channel:lark] download post image: %v", dlErr)
 			} else if att != nil {
@@ -345,12 +630,12 @@ func (l *Lark) handleMessage(ctx context.Context, event *larkim.P2MessageReceive
 		}
 
 	case "image":
-		imageKey, err := extractKey(msg.Content, "image_key")
+		imageKey, err := extractKey(raw, "image_key")
 		if err != nil {
 			logs.CtxWarn(ctx, "[channel:lark] failed to extract image_key: %v", err)
-			return
+			return "", nil, false
 		}
-		att, err := l.downloadResource(ctx, *msg.MessageId, imageKey, "image", channel.AttachmentImage, "image/png")
+		att, err := l.downloadResource(ctx, messageID, imageKey, "image", channel.AttachmentImage, "image/png")
 		if err != nil {
 			logs.CtxWarn(ctx, "[channel:lark] download image: %v", err)
 		} else if att != nil {
@@ -358,12 +643,12 @@ func (l *Lark) handleMessage(ctx context.Context, event *larkim.P2MessageReceive
 		}
 
 	case "audio":
-		fileKey, err := extractKey(msg.Content, "file_key")
+		fileKey, err := extractKey(raw, "file_key")
 		if err != nil {
 			logs.CtxWarn(ctx, "[channel:lark] failed to extract audio file_key: %v", err)
-			return
+			return "", nil, false
 		}
-		att, err := l.downloadResource(ctx, *msg.MessageId, fileKey, "file", channel.AttachmentVoice, "audio/ogg")
+		att, err := l.downloadResource(ctx, messageID, fileKey, "file", channel.AttachmentVoice, "audio/ogg")
 		if err != nil {
 			logs.CtxWarn(ctx, "[channel:lark] download audio: %v", err)
 		} else if att != nil {
@@ -371,12 +656,12 @@ func (l *Lark) handleMessage(ctx context.Context, event *larkim.P2MessageReceive
 		}
 
 	case "file":
-		fileKey, err := extractKey(msg.Content, "file_key")
+		fileKey, err := extractKey(raw, "file_key")
 		if err != nil {
 			logs.CtxWarn(ctx, "[channel:lark] failed to extract file key: %v", err)
-			return
+			return "", nil, false
 		}
-		att, err := l.downloadResource(ctx, *msg.MessageId, fileKey, "file", channel.AttachmentFile, "application/octet-stream")
+		att, err := l.downloadResource(ctx, messageID, fileKey, "file", channel.AttachmentFile, "application/octet-stream")
 		if err != nil {
 			logs.CtxWarn(ctx, "[channel:lark] download file: %v", err)
 		} else if att != nil {
@@ -385,51 +670,10 @@ func (l *Lark) handleMessage(ctx context.Context, event *larkim.P2MessageReceive
 
 	default:
 		logs.CtxDebug(ctx, "[channel:lark] ignoring message type: %s", msgType)
-		return
-	}
-
-	if content == "" && len(attachments) == 0 {
-		return
-	}
-
-	var userID string
-	if event.Event.Sender != nil && event.Event.Sender.SenderId != nil && event.Event.Sender.SenderId.OpenId != nil {
-		userID = *event.Event.Sender.SenderId.OpenId
-	}
-
-	var chatID string
-	if msg.ChatId != nil {
-		chatID = *msg.ChatId
-	}
-
-	metadata := map[string]string{}
-	if msg.MessageType != nil {
-		metadata["message_type"] = *msg.MessageType
-	}
-	if msg.ChatType != nil {
-		metadata["chat_type"] = *msg.ChatType
+		return "", nil, false
 	}
 
-	channelMsg := &channel.Message{
-		ID:          *msg.MessageId,
-		ChannelID:   l.id,
-		ChannelType: channel.Lark,
-		UserID:      userID,
-		ChatID:      chatID,
-		Content:     content,
-		Metadata:    metadata,
-		Attachments: attachments,
-	}
-
-	l.mu.RLock()
-	handler := l.handler
-	l.mu.RUnlock()
-
-	if handler != nil {
-		if err := handler(ctx, channelMsg); err != nil {
-			logs.CtxError(ctx, "[channel:lark] error handling message: %v", err)
-		}
-	}
+	return content, attachments, true
 }
 
 // downloadResource downloads a message resource (image or file) from Lark.
@@ -457,6 +701,8 @@ func (l *Lark) downloadResource(ctx context.Context, messageID, fileKey, resType
 	switch attType {
 	case channel.AttachmentImage:
 		limit = maxImageSize
+	case channel.AttachmentFile:
+		limit = maxFileSize
 	default:
 		limit = maxVoiceSize
 	}
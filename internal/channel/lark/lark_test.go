@@ -0,0 +1,44 @@
+package lark
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkevent "github.com/larksuite/oapi-sdk-go/v3/event"
+
+	"github.com/tgifai/friday/internal/channel"
+)
+
+func TestEventDispatcher_MessageUpdated(t *testing.T) {
+	l := &Lark{
+		id:      "lark",
+		config:  Config{VerificationToken: "token"},
+		inbound: channel.NewRecent[inboundRef](maxTrackedMessages),
+		sent:    channel.NewRecent[[]string](maxTrackedMessages),
+	}
+	got := make(chan *channel.Message, 1)
+	_ = l.RegisterMessageHandler(func(_ context.Context, msg *channel.Message) error {
+		got <- msg
+		return nil
+	})
+
+	// A schema 2.0 event as the open platform delivers it for an edit.
+	body := `{"schema":"2.0","header":{"event_id":"e1","token":"token","event_type":"im.message.updated_v1"},
+		"event":{"sender":{"sender_id":{"open_id":"ou_1"}},"message":{"message_id":"om_1","chat_id":"oc_1",
+		"chat_type":"p2p","message_type":"text","content":"{\"text\":\"fixed typo\"}"}}}`
+	d := l.newEventDispatcher(larkcore.NewEventLogger(), larkcore.LogLevelError)
+	if resp := d.Handle(context.Background(), &larkevent.EventReq{Body: []byte(body)}); resp.StatusCode != 200 {
+		t.Fatalf("dispatch status %d: %s", resp.StatusCode, resp.Body)
+	}
+
+	select {
+	case msg := <-got:
+		if msg.Event != channel.EventEdited || msg.ID != "om_1" || msg.Content != "fixed typo" || msg.UserID != "ou_1" {
+			t.Errorf("edit delivered as %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("edit event not delivered")
+	}
+}
@@ -3,6 +3,10 @@ package channel
 // SendOptions holds optional parameters for SendMessage.
 type SendOptions struct {
 	ReplyToMsgID string
+	ThreadID     string
+	// OriginID is the inbound message this send answers. Channels that
+	// implement MessageRetractor remember sent messages under it.
+	OriginID string
 }
 
 // SendOption is a functional option for SendMessage.
@@ -15,6 +19,21 @@ func WithReplyTo(msgID string) SendOption {
 	}
 }
 
+// WithThread posts the message into a forum topic or thread of the chat.
+func WithThread(threadID string) SendOption {
+	return func(o *SendOptions) {
+		o.ThreadID = threadID
+	}
+}
+
+// WithOrigin marks the message as part of the answer to inbound message msgID,
+// so it can be retracted if that message is edited.
+func WithOrigin(msgID string) SendOption {
+	return func(o *SendOptions) {
+		o.OriginID = msgID
+	}
+}
+
 // ApplySendOptions builds a SendOptions from the given options.
 func ApplySendOptions(opts []SendOption) SendOptions {
 	var o SendOptions
@@ -0,0 +1,65 @@
+package channel
+
+import "sync"
+
+// Recent is a small map for per-message bookkeeping that keeps at most max
+// keys, evicting the oldest first.
+type Recent[V any] struct {
+	mu    sync.Mutex
+	max   int
+	m     map[string]V
+	order []string
+}
+
+func NewRecent[V any](max int) *Recent[V] {
+	return &Recent[V]{max: max, m: make(map[string]V)}
+}
+
+func (r *Recent[V]) Get(key string) (V, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.m[key]
+	return v, ok
+}
+
+// Update sets key to fn(current value), where current is the zero value for
+// unknown keys.
+func (r *Recent[V]) Update(key string, fn func(V) V) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cur, ok := r.m[key]
+	r.m[key] = fn(cur)
+	if ok {
+		return
+	}
+	r.order = append(r.order, key)
+	for len(r.m) > r.max && len(r.order) > 0 {
+		oldest := r.order[0]
+		r.order = r.order[1:]
+		delete(r.m, oldest)
+	}
+	// Keys removed by Take linger in order; rebuild it once they dominate.
+	if len(r.order) > 2*r.max {
+		order := make([]string, 0, len(r.m))
+		for _, k := range r.order {
+			if _, live := r.m[k]; live {
+				order = append(order, k)
+			}
+		}
+		r.order = order
+	}
+}
+
+func (r *Recent[V]) Set(key string, v V) {
+	r.Update(key, func(V) V { return v })
+}
+
+// Take removes key and returns its value.
+func (r *Recent[V]) Take(key string) (V, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.m[key]
+	delete(r.m, key)
+	return v, ok
+}
@@ -26,13 +26,19 @@ type pendingMediaGroup struct {
 	firstMessageID int
 	// mentioned tracks whether any update in the group had an @bot mention.
 	mentioned bool
+	// replyTo and quote carry the reply context of the album, if any.
+	replyTo *models.Message
+	quote   *models.TextQuote
+	// threadID and isTopic identify the forum topic the album was posted in.
+	threadID int
+	isTopic  bool
 }
 
 // mediaGroupAggregator buffers incoming media-group updates and flushes
 // them as a single batch after a debounce window.
 type mediaGroupAggregator struct {
-	mu     sync.Mutex
-	groups map[string]*pendingMediaGroup // key: MediaGroupID
+	mu      sync.Mutex
+	groups  map[string]*pendingMediaGroup // key: MediaGroupID
 	onFlush func(g *pendingMediaGroup)    // called when debounce fires
 }
 
@@ -57,6 +63,8 @@ func (a *mediaGroupAggregator) add(msg *models.Message, mentioned bool) {
 			chat:           msg.Chat,
 			from:           msg.From,
 			firstMessageID: msg.ID,
+			threadID:       msg.MessageThreadID,
+			isTopic:        msg.IsTopicMessage,
 		}
 		a.groups[groupID] = pg
 	}
@@ -67,6 +75,11 @@ func (a *mediaGroupAggregator) add(msg *models.Message, mentioned bool) {
 		pg.captionEntities = msg.CaptionEntities
 	}
 
+	if msg.ReplyToMessage != nil && pg.replyTo == nil {
+		pg.replyTo = msg.ReplyToMessage
+		pg.quote = msg.Quote
+	}
+
 	// Track mention across all updates in the group.
 	if mentioned {
 		pg.mentioned = true
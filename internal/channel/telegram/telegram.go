@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -25,16 +26,24 @@ const (
 	maxImageSize int64 = 3 * 1024 * 1024
 	// maxVoiceSize is the upper bound for downloading voice/audio (1 MB).
 	maxVoiceSize int64 = 1 * 1024 * 1024
+	// maxFileSize is the upper bound for downloading documents (10 MB).
+	maxFileSize int64 = 10 * 1024 * 1024
 	// typingInterval is how often the typing indicator is refreshed.
 	// Telegram's typing status expires after ~5 seconds.
 	typingInterval = 3 * time.Second
 )
 
 var (
-	_ channel.Channel           = (*Telegram)(nil)
-	_ channel.CommandRegistrar  = (*Telegram)(nil)
+	_ channel.Channel          = (*Telegram)(nil)
+	_ channel.CommandRegistrar = (*Telegram)(nil)
+	_ channel.MessageRetractor = (*Telegram)(nil)
+	_ channel.MemberChecker    = (*Telegram)(nil)
 )
 
+// maxTrackedOrigins bounds how many answered messages keep their reply IDs
+// for retraction.
+const maxTrackedOrigins = 1000
+
 type Telegram struct {
 	id          string
 	config      Config
@@ -43,9 +52,14 @@ type Telegram struct {
 	botUserID   int64  // bot user ID for text_mention matching
 	handler     func(ctx context.Context, msg *channel.Message) error
 	mediaGroups *mediaGroupAggregator
-	mu          sync.RWMutex
-	ctx         context.Context
-	cancel      context.CancelFunc
+	// ambient buffers unmentioned group messages; nil when listening is off.
+	ambient       *channel.AmbientBuffer
+	ambientInject int
+	// sent maps "chatID:originID" to the bot messages answering it.
+	sent   *channel.Recent[[]int]
+	mu     sync.RWMutex
+	ctx    context.Context
+	cancel context.CancelFunc
 
 	// webhook mode
 	webhookPath    string          // empty in polling mode
@@ -65,8 +79,18 @@ func NewChannel(chanId string, chCfg *config.ChannelConfig) (channel.Channel, er
 		config: *cfg,
 		ctx:    ctx,
 		cancel: cancel,
+		sent:   channel.NewRecent[[]int](maxTrackedOrigins),
 	}
 	tg.mediaGroups = newMediaGroupAggregator(tg.flushMediaGroup)
+	if amb := chCfg.Ambient; amb.Enabled {
+		maxAge, err := time.ParseDuration(amb.MaxAge)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("parse ambient max_age: %w", err)
+		}
+		tg.ambient = channel.NewAmbientBuffer(amb.MaxMessages, maxAge)
+		tg.ambientInject = amb.Inject
+	}
 
 	opts := []bot.Option{
 		bot.WithDefaultHandler(tg.handleUpdate),
@@ -231,13 +255,16 @@ func (c *Telegram) SendMessage(ctx context.Context, chatID string, content strin
 		}
 	}
 
+	threadID := parseThreadID(o.ThreadID)
+
 	entityText, entities := convertMarkdownEntities(content)
 	if entityText == "" {
 		entityText = content
 	}
 
-	_, err = c.bot.SendMessage(ctx, &bot.SendMessageParams{
+	sent, err := c.bot.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID:          chatIDInt,
+		MessageThreadID: threadID,
 		Text:            entityText,
 		Entities:        entities,
 		ReplyParameters: replyParams,
@@ -245,16 +272,103 @@ func (c *Telegram) SendMessage(ctx context.Context, chatID string, content strin
 
 	if err != nil {
 		logs.CtxWarn(ctx, "[channel:telegram] HTML parse failed, falling back to plain text: %v", err)
-		_, err = c.bot.SendMessage(ctx, &bot.SendMessageParams{
+		sent, err = c.bot.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID:          chatIDInt,
+			MessageThreadID: threadID,
 			Text:            content,
 			ReplyParameters: replyParams,
 		})
 	}
+	if err == nil {
+		c.trackSent(chatID, o.OriginID, sent)
+	}
 
 	return err
 }
 
+// SendVoice implements channel.VoiceSender. Telegram renders OGG/Opus (and
+// MP3/M4A) uploads sent through sendVoice as playable voice notes.
+func (c *Telegram) SendVoice(ctx context.Context, chatID string, audio channel.Attachment, opts ...channel.SendOption) error {
+	chatIDInt, err := strconv.ParseInt(chatID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid chat ID: %w", err)
+	}
+
+	o := channel.ApplySendOptions(opts)
+
+	var replyParams *models.ReplyParameters
+	if o.ReplyToMsgID != "" {
+		if msgIDInt, err := strconv.Atoi(o.ReplyToMsgID); err == nil {
+			replyParams = &models.ReplyParameters{MessageID: msgIDInt}
+		}
+	}
+
+	fileName := audio.FileName
+	if fileName == "" {
+		fileName = "voice.ogg"
+	}
+	sent, err := c.bot.SendVoice(ctx, &bot.SendVoiceParams{
+		ChatID:          chatIDInt,
+		MessageThreadID: parseThreadID(o.ThreadID),
+		Voice:           &models.InputFileUpload{Filename: fileName, Data: bytes.NewReader(audio.Data)},
+		ReplyParameters: replyParams,
+	})
+	if err != nil {
+		return fmt.Errorf("telegram send voice: %w", err)
+	}
+	c.trackSent(chatID, o.OriginID, sent)
+	return nil
+}
+
+// trackSent remembers a message sent in answer to originID.
+func (c *Telegram) trackSent(chatID, originID string, sent *models.Message) {
+	if originID == "" || sent == nil {
+		return
+	}
+	c.sent.Update(chatID+":"+originID, func(ids []int) []int {
+		return append(ids, sent.ID)
+	})
+}
+
+// RetractReplies implements channel.MessageRetractor.
+func (c *Telegram) RetractReplies(ctx context.Context, chatID string, originID string) error {
+	ids, ok := c.sent.Take(chatID + ":" + originID)
+	if !ok || len(ids) == 0 {
+		return nil
+	}
+	chatIDInt, err := strconv.ParseInt(chatID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid chat ID: %w", err)
+	}
+	if _, err := c.bot.DeleteMessages(ctx, &bot.DeleteMessagesParams{ChatID: chatIDInt, MessageIDs: ids}); err != nil {
+		return fmt.Errorf("telegram delete messages: %w", err)
+	}
+	return nil
+}
+
+// IsChatMember implements channel.MemberChecker.
+func (c *Telegram) IsChatMember(ctx context.Context, chatID string, userID string) (bool, error) {
+	chatIDInt, err := strconv.ParseInt(chatID, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid chat ID: %w", err)
+	}
+	userIDInt, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid user ID: %w", err)
+	}
+	member, err := c.bot.GetChatMember(ctx, &bot.GetChatMemberParams{ChatID: chatIDInt, UserID: userIDInt})
+	if err != nil {
+		return false, fmt.Errorf("telegram get chat member: %w", err)
+	}
+	switch member.Type {
+	case models.ChatMemberTypeOwner, models.ChatMemberTypeAdministrator, models.ChatMemberTypeMember:
+		return true, nil
+	case models.ChatMemberTypeRestricted:
+		return member.Restricted != nil && member.Restricted.IsMember, nil
+	}
+	return false, nil
+}
+
 func (c *Telegram) SendChatAction(ctx context.Context, chatID string, action channel.ChatAction) error {
 	chatIDInt, err := strconv.ParseInt(chatID, 10, 64)
 	if err != nil {
@@ -355,10 +469,14 @@ func (c *Telegram) RegisterMessageHandler(handler func(ctx context.Context, msg
 }
 
 // handleUpdate is the default handler for all incoming Telegram updates.
-// It normalizes text, photo, voice, and audio messages into a channel.Message
+// It normalizes text, photo, voice, audio, and document messages into a channel.Message
 // and forwards them to the registered handler.
 func (c *Telegram) handleUpdate(ctx context.Context, b *bot.Bot, update *models.Update) {
 	msg := update.Message
+	var event channel.MessageEvent
+	if msg == nil && update.EditedMessage != nil {
+		msg, event = update.EditedMessage, channel.EventEdited
+	}
 	if msg == nil || msg.From == nil {
 		return
 	}
@@ -369,6 +487,9 @@ func (c *Telegram) handleUpdate(ctx context.Context, b *bot.Bot, update *models.
 
 	// In group/supergroup chats, only process messages that mention the bot.
 	if c.botUsername != "" && isGroupChat(msg.Chat.Type) && !mentioned {
+		if event != "" {
+			return
+		}
 		// For media groups, a later update in the same group might carry the
 		// caption with the @mention. We cannot know yet, so we must still
 		// check if this belongs to an existing pending group.
@@ -387,22 +508,25 @@ func (c *Telegram) handleUpdate(ctx context.Context, b *bot.Bot, update *models.
 			}
 			// Fall through to add it to the existing mentioned group.
 		} else {
+			c.listen(msg, msg.Caption)
 			return
 		}
 	}
 
-	// Media group: aggregate multiple photo updates into one message.
-	if msg.MediaGroupID != "" {
+	// Media group: aggregate multiple photo updates into one message. An
+	// edited album caption is handled like a single message.
+	if msg.MediaGroupID != "" && event == "" {
 		c.mediaGroups.add(msg, mentioned)
 		return
 	}
 
 	// --- single message path ---
-	c.processSingleUpdate(ctx, b, msg)
+	c.processSingleUpdate(ctx, b, msg, event)
 }
 
-// processSingleUpdate handles a non-media-group update.
-func (c *Telegram) processSingleUpdate(ctx context.Context, b *bot.Bot, msg *models.Message) {
+// processSingleUpdate handles a non-media-group update. event is set for
+// edits of an earlier message.
+func (c *Telegram) processSingleUpdate(ctx context.Context, b *bot.Bot, msg *models.Message, event channel.MessageEvent) {
 	// Determine textual content: prefer Text, fall back to Caption.
 	content := msg.Text
 	if content == "" {
@@ -414,7 +538,22 @@ func (c *Telegram) processSingleUpdate(ctx context.Context, b *bot.Bot, msg *mod
 		content = c.stripBotMention(content)
 	}
 
-	// Extract attachments from media fields.
+	attachments := c.extractAttachments(ctx, msg)
+
+	// Drop the message if there is no text and no attachment.
+	if content == "" && len(attachments) == 0 {
+		return
+	}
+
+	channelMsg := c.buildChannelMessage(msg, content, attachments)
+	channelMsg.ReplyTo = c.buildReplyContext(ctx, msg)
+	channelMsg.Event = event
+	c.dispatchMessage(ctx, b, msg.Chat.ID, channelMsg)
+}
+
+// extractAttachments downloads the media carried by a message (photo, voice,
+// audio, document). Failed or oversized downloads are skipped.
+func (c *Telegram) extractAttachments(ctx context.Context, msg *models.Message) []channel.Attachment {
 	var attachments []channel.Attachment
 
 	if len(msg.Photo) > 0 {
@@ -444,13 +583,131 @@ func (c *Telegram) processSingleUpdate(ctx context.Context, b *bot.Bot, msg *mod
 		}
 	}
 
-	// Drop the message if there is no text and no attachment.
-	if content == "" && len(attachments) == 0 {
+	if msg.Document != nil {
+		att, err := c.extractDocument(ctx, msg.Document)
+		if err != nil {
+			logs.CtxWarn(ctx, "[channel:telegram] download document: %v", err)
+		} else if att != nil {
+			attachments = append(attachments, *att)
+		}
+	}
+
+	return attachments
+}
+
+// buildReplyContext captures the message being replied to, including its
+// media, and any partial quote the user highlighted. Returns nil for
+// messages that are not replies.
+func (c *Telegram) buildReplyContext(ctx context.Context, msg *models.Message) *channel.ReplyContext {
+	reply := msg.ReplyToMessage
+	if isTopicRoot(msg, reply) {
+		// Telegram sets every message of a forum topic as a reply to the
+		// service message that created the topic.
+		reply = nil
+	}
+	if reply == nil && msg.Quote == nil {
+		return nil
+	}
+
+	rc := &channel.ReplyContext{}
+	if msg.Quote != nil {
+		rc.Quote = msg.Quote.Text
+	}
+	if reply == nil {
+		return rc
+	}
+
+	rc.MessageID = strconv.Itoa(reply.ID)
+	rc.Content = reply.Text
+	if rc.Content == "" {
+		rc.Content = reply.Caption
+	}
+	if reply.From != nil {
+		rc.UserID = strconv.FormatInt(reply.From.ID, 10)
+		rc.FromBot = reply.From.ID == c.botUserID
+		rc.Author = displayName(reply.From)
+	} else if reply.SenderChat != nil {
+		rc.Author = reply.SenderChat.Title
+	}
+	rc.Attachments = c.extractAttachments(ctx, reply)
+	return rc
+}
+
+// isTopicRoot reports whether reply is the service message that opened the
+// forum topic of msg rather than a message the user replied to.
+func isTopicRoot(msg, reply *models.Message) bool {
+	return reply != nil && (reply.ForumTopicCreated != nil || msg.IsTopicMessage && reply.ID == msg.MessageThreadID)
+}
+
+// listen records an unmentioned group message in the ambient buffer. Media is
+// never downloaded; it is only described by a short placeholder.
+func (c *Telegram) listen(msg *models.Message, caption string) {
+	if c.ambient == nil || msg.From == nil || msg.From.IsBot {
 		return
 	}
 
-	channelMsg := c.buildChannelMessage(msg, content, attachments)
-	c.dispatchMessage(ctx, b, msg.Chat.ID, channelMsg)
+	content := strings.TrimSpace(msg.Text)
+	if content == "" {
+		content = strings.TrimSpace(caption)
+	}
+	// Commands in groups are usually meant for other bots.
+	if strings.HasPrefix(content, "/") {
+		return
+	}
+	switch {
+	case len(msg.Photo) > 0:
+		content = strings.TrimSpace("[photo] " + content)
+	case msg.Voice != nil, msg.Audio != nil:
+		content = strings.TrimSpace("[voice message] " + content)
+	case msg.Document != nil:
+		content = strings.TrimSpace(fmt.Sprintf("[file: %s] %s", msg.Document.FileName, content))
+	case msg.Sticker != nil:
+		content = "[sticker " + msg.Sticker.Emoji + "]"
+	}
+	if content == "" {
+		return
+	}
+
+	c.ambient.Add(ambientKey(strconv.FormatInt(msg.Chat.ID, 10), topicID(msg)), channel.AmbientMessage{
+		UserID:  strconv.FormatInt(msg.From.ID, 10),
+		Author:  displayName(msg.From),
+		Content: content,
+	})
+}
+
+// DrainAmbient implements channel.AmbientSource.
+func (c *Telegram) DrainAmbient(chatID string, threadID string) []channel.AmbientMessage {
+	if c.ambient == nil {
+		return nil
+	}
+	return c.ambient.Drain(ambientKey(chatID, threadID), c.ambientInject)
+}
+
+// ambientKey buffers each forum topic apart from the rest of its chat.
+func ambientKey(chatID, threadID string) string {
+	if threadID == "" {
+		return chatID
+	}
+	return chatID + "/" + threadID
+}
+
+// topicID returns the forum topic a message belongs to, or "" outside
+// topics.
+func topicID(msg *models.Message) string {
+	if !msg.IsTopicMessage || msg.MessageThreadID == 0 {
+		return ""
+	}
+	return strconv.Itoa(msg.MessageThreadID)
+}
+
+// displayName renders a Telegram user as "First Last", falling back to
+// "@username".
+func displayName(u *models.User) string {
+	name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	if name == "" && u.Username != "" {
+		name = "@" + u.Username
+	}
+	return name
 }
 
 // flushMediaGroup is called by the aggregator after the debounce window.
@@ -461,6 +718,7 @@ func (c *Telegram) flushMediaGroup(pg *pendingMediaGroup) {
 	// In group chats, drop the entire group if no update had an @mention.
 	if c.botUsername != "" && isGroupChat(pg.chat.Type) && !pg.mentioned {
 		logs.CtxDebug(ctx, "[channel:telegram] media group dropped: no bot mention")
+		c.listen(&models.Message{Chat: pg.chat, From: pg.from}, fmt.Sprintf("[%d photos] %s", len(pg.photos), pg.caption))
 		return
 	}
 
@@ -523,12 +781,17 @@ func (c *Telegram) flushMediaGroup(pg *pendingMediaGroup) {
 
 	// Build a synthetic models.Message for buildChannelMessage.
 	syntheticMsg := &models.Message{
-		ID:   pg.firstMessageID,
-		Chat: pg.chat,
-		From: pg.from,
+		ID:              pg.firstMessageID,
+		Chat:            pg.chat,
+		From:            pg.from,
+		ReplyToMessage:  pg.replyTo,
+		Quote:           pg.quote,
+		MessageThreadID: pg.threadID,
+		IsTopicMessage:  pg.isTopic,
 	}
 
 	channelMsg := c.buildChannelMessage(syntheticMsg, content, attachments)
+	channelMsg.ReplyTo = c.buildReplyContext(ctx, syntheticMsg)
 	channelMsg.Metadata["media_group"] = "true"
 
 	logs.CtxInfo(ctx, "[channel:telegram] media group flushed: %d photos, caption=%q",
@@ -558,12 +821,20 @@ func (c *Telegram) buildChannelMessage(msg *models.Message, content string, atta
 		userID = strconv.FormatInt(msg.From.ID, 10)
 	}
 
+	// Only forum topics carry a meaningful thread; plain supergroups reuse
+	// message_thread_id for reply chains.
+	threadID := topicID(msg)
+	if threadID != "" {
+		metadata["topic_id"] = threadID
+	}
+
 	return &channel.Message{
 		ID:          messageID,
 		ChannelID:   c.id,
 		ChannelType: channel.Telegram,
 		UserID:      userID,
 		ChatID:      strconv.FormatInt(msg.Chat.ID, 10),
+		ThreadID:    threadID,
 		Content:     content,
 		Metadata:    metadata,
 		Attachments: attachments,
@@ -581,9 +852,10 @@ func (c *Telegram) dispatchMessage(ctx context.Context, b *bot.Bot, chatID int64
 			logs.CtxError(ctx, "[channel:telegram] error handling message: %v", err)
 			if b != nil {
 				_, _ = b.SendMessage(ctx, &bot.SendMessageParams{
-					ChatID:    chatID,
-					Text:      "Sorry, an error occurred while processing your message.",
-					ParseMode: models.ParseModeMarkdown,
+					ChatID:          chatID,
+					MessageThreadID: parseThreadID(channelMsg.ThreadID),
+					Text:            "Sorry, an error occurred while processing your message.",
+					ParseMode:       models.ParseModeMarkdown,
 				})
 			}
 		}
@@ -656,6 +928,29 @@ func (c *Telegram) extractAudio(ctx context.Context, a *models.Audio) (*channel.
 	}, nil
 }
 
+// extractDocument downloads a document (PDF, DOCX, source file, ...) if
+// within the size limit.
+func (c *Telegram) extractDocument(ctx context.Context, d *models.Document) (*channel.Attachment, error) {
+	if d.FileSize > maxFileSize {
+		logs.CtxDebug(ctx, "[channel:telegram] document too large (%d bytes), skipping", d.FileSize)
+		return nil, nil
+	}
+	data, err := c.downloadFile(ctx, d.FileID)
+	if err != nil {
+		return nil, err
+	}
+	mime := d.MimeType
+	if mime == "" {
+		mime = "application/octet-stream"
+	}
+	return &channel.Attachment{
+		Type:     channel.AttachmentFile,
+		Data:     data,
+		MIMEType: mime,
+		FileName: d.FileName,
+	}, nil
+}
+
 // downloadFile retrieves a file from the Telegram servers by file ID.
 func (c *Telegram) downloadFile(ctx context.Context, fileID string) ([]byte, error) {
 	file, err := c.bot.GetFile(ctx, &bot.GetFileParams{FileID: fileID})
@@ -665,6 +960,19 @@ func (c *Telegram) downloadFile(ctx context.Context, fileID string) ([]byte, err
 	return utils.DownloadFile(ctx, c.bot.FileDownloadLink(file))
 }
 
+// parseThreadID converts a channel thread ID into Telegram's
+// message_thread_id. Empty or invalid IDs map to 0 (no topic).
+func parseThreadID(threadID string) int {
+	if threadID == "" {
+		return 0
+	}
+	id, err := strconv.Atoi(threadID)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
 // isGroupChat returns true for group and supergroup chat types.
 func isGroupChat(chatType models.ChatType) bool {
 	return chatType == models.ChatTypeGroup || chatType == models.ChatTypeSupergroup
@@ -5,10 +5,12 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bytedance/sonic"
 
 	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/security/sandbox"
 )
 
 type (
@@ -19,6 +21,9 @@ type (
 		Agents    map[string]AgentConfig    `yaml:"agents"`
 		Channels  map[string]ChannelConfig  `yaml:"channels"`
 		Providers map[string]ProviderConfig `yaml:"providers"`
+		Usage     UsageConfig               `yaml:"usage,omitempty"`
+		Redact    RedactConfig              `yaml:"redact,omitempty"`
+		Egress    EgressConfig              `yaml:"egress,omitempty"`
 	}
 
 	GatewayConfig struct {
@@ -47,14 +52,58 @@ type (
 	}
 
 	AgentConfig struct {
-		ID        string             `yaml:"-"`
-		Name      string             `yaml:"name"`
-		Workspace string             `yaml:"workspace"`
-		Channels  []string           `yaml:"channels"`
-		Skills    []string           `yaml:"skills"`
-		Models    ModelsConfig       `yaml:"models"`
-		Config    AgentRuntimeConfig `yaml:"config"`
-		Session   SessionConfig      `yaml:"session"`
+		ID        string              `yaml:"-"`
+		Name      string              `yaml:"name"`
+		Workspace string              `yaml:"workspace"`
+		Channels  []string            `yaml:"channels"`
+		Skills    []string            `yaml:"skills"`
+		Models    ModelsConfig        `yaml:"models"`
+		Config    AgentRuntimeConfig  `yaml:"config"`
+		Session   SessionConfig       `yaml:"session"`
+		ToolRoles map[string][]string `yaml:"tool_roles,omitempty"` // key: tool name or "*"; roles allowed to use it
+		Voice     VoiceConfig         `yaml:"voice,omitempty"`
+		Security  AgentSecurityConfig `yaml:"security,omitempty"`
+	}
+
+	AgentSecurityConfig struct {
+		Untrusted  UntrustedConfig       `yaml:"untrusted,omitempty"`
+		Filesystem FilesystemConfig      `yaml:"filesystem,omitempty"`
+		Egress     EgressConfig          `yaml:"egress,omitempty"` // overrides the top-level egress policy field by field
+		Sandbox    sandbox.SandboxConfig `yaml:"sandbox,omitempty"`
+	}
+
+	// FilesystemConfig limits the paths the file tools may read and write
+	// and the working directories of exec and process. Relative paths are
+	// taken from the workspace.
+	FilesystemConfig struct {
+		ReadOnly      []string `yaml:"read_only,omitempty"`       // roots that may only be read
+		ReadWrite     []string `yaml:"read_write,omitempty"`      // roots that may be read and written; default the workspace
+		Deny          []string `yaml:"deny,omitempty"`            // globs denied under any root, e.g. **/.ssh/**, .env
+		MaxReadBytes  int64    `yaml:"max_read_bytes,omitempty"`  // largest file read; 0 means no cap
+		MaxWriteBytes int64    `yaml:"max_write_bytes,omitempty"` // largest file written; 0 means no cap
+		Symlinks      string   `yaml:"symlinks,omitempty"`        // follow (default) or refuse
+	}
+
+	// EgressConfig limits the hosts web_fetch, http_request and the browser
+	// may reach. Private, loopback and link-local addresses are blocked
+	// unless allow_private is set or they fall in allow_networks. A domain
+	// covers its subdomains; "*.example.com" covers only the subdomains.
+	EgressConfig struct {
+		Allow         []string `yaml:"allow,omitempty"`          // domains that may be reached; empty means any public host
+		Deny          []string `yaml:"deny,omitempty"`           // domains that may never be reached; wins over allow
+		AllowPrivate  *bool    `yaml:"allow_private,omitempty"`  // reach private addresses too; default false
+		AllowNetworks []string `yaml:"allow_networks,omitempty"` // CIDRs or addresses reachable though private, e.g. 10.0.5.0/24
+	}
+
+	// UntrustedConfig controls the handling of tool results that carry
+	// outside content (web pages, HTTP responses, MCP output). Such results
+	// are fenced off and scanned for prompt injection; once one is in the
+	// turn, the Restrict tools are denied or need the user's approval.
+	UntrustedConfig struct {
+		Sources  []string `yaml:"sources,omitempty"`  // tools with untrusted results; default web_fetch, web_search, browser, http_request, mcp
+		Restrict []string `yaml:"restrict,omitempty"` // tools restricted afterwards; default exec, process, agent, message, cronx, write, edit, delete, file, http_request, browser
+		Action   string   `yaml:"action,omitempty"`   // deny (default), approve or allow
+		Trigger  string   `yaml:"trigger,omitempty"`  // untrusted (default) or suspicious
 	}
 
 	ModelsConfig struct {
@@ -65,6 +114,13 @@ type (
 	AgentRuntimeConfig struct {
 		MaxIterations int     `yaml:"max_iterations"`
 		Temperature   float64 `yaml:"temperature"`
+		// ParallelToolCalls is how many tool calls of one model response
+		// may run at once; 0 means the default of 4, 1 runs them in turn.
+		ParallelToolCalls int `yaml:"parallel_tool_calls"`
+		// ShowModelErrors posts the error of each failed model to the chat
+		// and lists them when every model fails. Off by default, since
+		// provider errors are raw and may reveal endpoints or account details.
+		ShowModelErrors bool `yaml:"show_model_errors"`
 	}
 
 	SessionConfig struct {
@@ -75,18 +131,86 @@ type (
 		ReserveTokens    int    `yaml:"reserve_tokens"`     // tokens reserved for new messages + reply, default 20000
 	}
 
+	VoiceConfig struct {
+		STT STTConfig `yaml:"stt,omitempty"`
+		TTS TTSConfig `yaml:"tts,omitempty"`
+	}
+
+	STTConfig struct {
+		Backend  string `yaml:"backend"`  // whisper, provider; empty disables transcription
+		BaseURL  string `yaml:"base_url"` // whisper: OpenAI-compatible API root, e.g. http://127.0.0.1:8000/v1
+		APIKey   string `yaml:"api_key"`  // whisper: optional bearer token
+		Model    string `yaml:"model"`    // whisper: model name (default whisper-1); provider: provider_id:model_name
+		Language string `yaml:"language"` // optional ISO-639-1 hint, e.g. "en"
+		Timeout  int    `yaml:"timeout"`  // seconds, default 60
+	}
+
+	TTSConfig struct {
+		Backend  string   `yaml:"backend"`   // openai, command; empty disables voice replies
+		Reply    string   `yaml:"reply"`     // off, inbound (answer voice with voice), always; default inbound
+		BaseURL  string   `yaml:"base_url"`  // openai: API root, e.g. https://api.openai.com/v1
+		APIKey   string   `yaml:"api_key"`   // openai: optional bearer token
+		Model    string   `yaml:"model"`     // openai: model name (default tts-1)
+		Voice    string   `yaml:"voice"`     // openai: voice name (default alloy)
+		Command  []string `yaml:"command"`   // command: argv; reply text on stdin, audio on stdout
+		Format   string   `yaml:"format"`    // audio format produced: opus (default), mp3, wav
+		MaxChars int      `yaml:"max_chars"` // replies with more spoken text are sent as text, default 1500
+		Timeout  int      `yaml:"timeout"`   // seconds, default 60
+	}
+
 	ChannelConfig struct {
 		ID       string                      `yaml:"-"`
 		Type     string                      `yaml:"type"` // telegram, lark, discord, http
 		Enabled  bool                        `yaml:"enabled"`
 		ACL      map[string]ChannelACLConfig `yaml:"acl,omitempty"` // key: chatType:chatId
 		Security ChannelSecurityConfig       `yaml:"security,omitempty"`
+		Session  ChannelSessionConfig        `yaml:"session,omitempty"`
+		Ambient  ChannelAmbientConfig        `yaml:"ambient,omitempty"`
+		Roles    ChannelRolesConfig          `yaml:"roles,omitempty"`
 		Config   map[string]interface{}      `yaml:"config"`
 	}
 
+	ChannelSessionConfig struct {
+		Threads bool   `yaml:"threads"`         // give each forum topic / thread its own session
+		Scope   string `yaml:"scope,omitempty"` // chat (default), user_in_chat or user
+	}
+
+	// ChannelAmbientConfig enables passive listening in group chats: messages
+	// that do not mention the bot are kept in a bounded in-memory buffer per
+	// chat and forum topic, and the most recent ones are shown to the agent
+	// on the next mention the gateway accepts.
+	ChannelAmbientConfig struct {
+		Enabled     bool   `yaml:"enabled"`
+		MaxMessages int    `yaml:"max_messages,omitempty"` // buffered per chat or topic (default 50)
+		MaxAge      string `yaml:"max_age,omitempty"`      // discard older messages (default 1h)
+		Inject      int    `yaml:"inject,omitempty"`       // messages injected on mention (default 20)
+	}
+
+	// ChannelRolesConfig assigns a role (owner, admin, member, guest) to each
+	// user of the channel. Users without an entry get Default.
+	ChannelRolesConfig struct {
+		Default string            `yaml:"default,omitempty"` // owner when the channel has no users or paired role, member otherwise
+		Paired  string            `yaml:"paired,omitempty"`  // role recorded for users granted access by pairing
+		Users   map[string]string `yaml:"users,omitempty"`   // key: user ID
+	}
+
+	// ChannelACLConfig is the access rule for the chats matched by its key.
+	// Keys and user IDs may end in "*" to match by prefix; see the acl
+	// package for the evaluation order.
 	ChannelACLConfig struct {
-		Allow []string `yaml:"allow"`
-		Block []string `yaml:"block"`
+		Allow        []string            `yaml:"allow"`
+		Block        []string            `yaml:"block"`
+		Grants       map[string]ACLGrant `yaml:"grants,omitempty"`        // key: user ID; how allowed users were granted access
+		OwnerPresent bool                `yaml:"owner_present,omitempty"` // also allow everyone in group chats a channel owner belongs to
+		Reason       string              `yaml:"reason,omitempty"`        // reply sent to users this rule denies
+	}
+
+	// ACLGrant records when and how a user was added to an allow list.
+	// Users listed without a grant were added by editing the config.
+	ACLGrant struct {
+		At      time.Time `yaml:"at"`
+		Via     string    `yaml:"via"`               // pairing_code, owner_approval or cli
+		Expires time.Time `yaml:"expires,omitempty"` // access ends at this time; zero never expires
 	}
 
 	ChannelSecurityConfig struct {
@@ -94,6 +218,82 @@ type (
 		WelcomeWindow int                   `yaml:"welcome_window"`
 		MaxResp       int                   `yaml:"max_resp"`
 		CustomText    string                `yaml:"custom_text"`
+		ApprovalChat  string                `yaml:"approval_chat,omitempty"` // chat ID where pairing requests are sent for the owner to approve
+		GrantTTL      string                `yaml:"grant_ttl,omitempty"`     // access granted by pairing expires after this duration, e.g. 720h; empty never expires
+		RateLimit     RateLimitConfig       `yaml:"rate_limit,omitempty"`
+	}
+
+	// RateLimitConfig throttles messages per user, chat and channel with
+	// token buckets. Commands and agent turns are limited separately.
+	RateLimitConfig struct {
+		Turns    RateLimitScopes `yaml:"turns,omitempty"`
+		Commands RateLimitScopes `yaml:"commands,omitempty"`
+		Reply    string          `yaml:"reply,omitempty"` // "slow down" text; {wait} is replaced by the wait time
+	}
+
+	RateLimitScopes struct {
+		User    RateLimitRule `yaml:"user,omitempty"`
+		Chat    RateLimitRule `yaml:"chat,omitempty"`
+		Channel RateLimitRule `yaml:"channel,omitempty"`
+	}
+
+	RateLimitRule struct {
+		PerMinute float64 `yaml:"per_minute"` // refill rate; 0 disables the limit
+		Burst     int     `yaml:"burst"`      // bucket size; defaults to per_minute rounded up
+	}
+
+	// UsageConfig prices the token usage recorded in the usage ledger.
+	UsageConfig struct {
+		Currency string                `yaml:"currency,omitempty"` // label shown in reports (default USD)
+		Prices   map[string]ModelPrice `yaml:"prices,omitempty"`   // key: provider_id:model_name or model_name
+		Budget   BudgetConfig          `yaml:"budget,omitempty"`
+	}
+
+	// BudgetConfig caps token usage and cost per agent, channel and user.
+	// Scheduled turns (cron jobs, heartbeats, memory flushes) only count
+	// against the Scheduled budget of their agent.
+	BudgetConfig struct {
+		Agents    map[string]BudgetLimits `yaml:"agents,omitempty"`   // key: agent ID, "*" applies to every agent
+		Channels  map[string]BudgetLimits `yaml:"channels,omitempty"` // key: channel ID, "*" applies to every channel
+		Users     map[string]BudgetLimits `yaml:"users,omitempty"`    // key: channel_id:user_id, user_id or "*"
+		Scheduled BudgetLimits            `yaml:"scheduled,omitempty"`
+		WarnAt    float64                 `yaml:"warn_at,omitempty"`   // fraction of a budget that triggers a warning (default 0.8)
+		Action    string                  `yaml:"action,omitempty"`    // refuse (default) or downgrade
+		Downgrade string                  `yaml:"downgrade,omitempty"` // model used when downgrading; default the agent's last fallback
+		Reply     string                  `yaml:"reply,omitempty"`     // refusal text; {scope} and {period} are replaced
+	}
+
+	BudgetLimits struct {
+		Daily   BudgetLimit `yaml:"daily,omitempty"`
+		Monthly BudgetLimit `yaml:"monthly,omitempty"`
+	}
+
+	// BudgetLimit caps tokens, cost or both; zero disables a cap.
+	BudgetLimit struct {
+		Tokens int     `yaml:"tokens,omitempty"`
+		Cost   float64 `yaml:"cost,omitempty"`
+	}
+
+	// ModelPrice is the cost per million tokens.
+	ModelPrice struct {
+		Input       float64 `yaml:"input"`
+		Output      float64 `yaml:"output"`
+		CachedInput float64 `yaml:"cached_input,omitempty"` // defaults to input
+	}
+
+	// RedactConfig controls the scrubbing of secrets from chat messages,
+	// logs and sessions. Known credentials from providers, channels, voice
+	// settings and the secrets store are always covered, along with the
+	// built-in detectors not listed in Skip.
+	RedactConfig struct {
+		Disabled bool            `yaml:"disabled,omitempty"`
+		Skip     []string        `yaml:"skip,omitempty"` // built-in detectors to turn off, e.g. assignment
+		Patterns []RedactPattern `yaml:"patterns,omitempty"`
+	}
+
+	RedactPattern struct {
+		Name    string `yaml:"name"`
+		Pattern string `yaml:"pattern"` // regexp; with a capture group only the first group is redacted
 	}
 
 	ProviderConfig struct {
@@ -159,6 +359,24 @@ func (c *Config) UpdateByName(name string, value any) error {
 			next[k] = v
 		}
 		c.Agents = next
+	case "usage":
+		typed, ok := value.(*UsageConfig)
+		if !ok || typed == nil {
+			return fmt.Errorf("name 'usage' requires *UsageConfig")
+		}
+		c.Usage = *typed
+	case "redact":
+		typed, ok := value.(*RedactConfig)
+		if !ok || typed == nil {
+			return fmt.Errorf("name 'redact' requires *RedactConfig")
+		}
+		c.Redact = *typed
+	case "egress":
+		typed, ok := value.(*EgressConfig)
+		if !ok || typed == nil {
+			return fmt.Errorf("name 'egress' requires *EgressConfig")
+		}
+		c.Egress = *typed
 	case "channels":
 		typed, ok := value.(*map[string]ChannelConfig)
 		if !ok || typed == nil {
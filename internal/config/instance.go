@@ -2,6 +2,8 @@ package config
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -35,6 +37,10 @@ type InstanceManager struct {
 	cfg    *Config
 	// hash tracks the current in-memory config snapshot hash.
 	hash string
+	// diskHash is the digest of the file as last read or written, used to
+	// detect edits made by another process (e.g. the CLI while the gateway
+	// runs).
+	diskHash string
 
 	mu sync.RWMutex
 }
@@ -71,7 +77,7 @@ func (ins *InstanceManager) Load(path string) (*Config, error) {
 		}
 	}
 
-	cfg, err := loadConfigFile(path)
+	raw, cfg, err := readConfigFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -80,10 +86,42 @@ func (ins *InstanceManager) Load(path string) (*Config, error) {
 	ins.cfg = cfg
 	cfgHash := cfg.Hash()
 	ins.hash = cfgHash
+	ins.diskHash = fileDigest(raw)
 	ins.loaded = true
 	return cfg.Clone()
 }
 
+// Reload re-reads the config file if another process changed it since it
+// was last read or written, replacing the in-memory config. It reports
+// whether anything was reloaded.
+func (ins *InstanceManager) Reload() (bool, error) {
+	if ins == nil {
+		return false, fmt.Errorf("instance manager is nil")
+	}
+
+	ins.mu.Lock()
+	defer ins.mu.Unlock()
+
+	if !ins.loaded {
+		return false, fmt.Errorf("config is not loaded")
+	}
+	raw, err := os.ReadFile(ins.path)
+	if err != nil {
+		return false, fmt.Errorf("read config file: %w", err)
+	}
+	if fileDigest(raw) == ins.diskHash {
+		return false, nil
+	}
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return false, err
+	}
+	ins.cfg = cfg
+	ins.hash = cfg.Hash()
+	ins.diskHash = fileDigest(raw)
+	return true, nil
+}
+
 func (ins *InstanceManager) Apply(name string, value any) error {
 	return ins.ApplyWithCAS(name, value, "")
 }
@@ -150,39 +188,49 @@ func (ins *InstanceManager) Save() error {
 		return fmt.Errorf("config is not loaded")
 	}
 
-	savedHash, err := ins.saveConfig(ins.cfg)
+	savedHash, diskHash, err := ins.saveConfig(ins.cfg)
 	if err != nil {
 		return err
 	}
 	ins.hash = savedHash
+	ins.diskHash = diskHash
 	return nil
 }
 
-func (ins *InstanceManager) saveConfig(cfg *Config) (string, error) {
+func (ins *InstanceManager) saveConfig(cfg *Config) (string, string, error) {
 	if cfg == nil {
-		return "", fmt.Errorf("config cannot be nil")
+		return "", "", fmt.Errorf("config cannot be nil")
 	}
 
 	path := strings.TrimSpace(ins.path)
 	if path == "" {
-		return "", fmt.Errorf("config path is required")
+		return "", "", fmt.Errorf("config path is required")
 	}
 
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return "", fmt.Errorf("create config dir: %w", err)
+		return "", "", fmt.Errorf("create config dir: %w", err)
 	}
 
 	unlock, err := acquireFileLock(path+".lock", lockAcquireTimeout, lockStaleAfter)
 	if err != nil {
-		return "", fmt.Errorf("acquire config file lock: %w", err)
+		return "", "", fmt.Errorf("acquire config file lock: %w", err)
 	}
 	defer unlock()
 
+	// Refuse to overwrite edits another process saved after our last read.
+	if current, err := os.ReadFile(path); err == nil {
+		if ins.diskHash != "" && fileDigest(current) != ins.diskHash {
+			return "", "", fmt.Errorf("%w: %s was changed by another process", ErrConfigConflict, path)
+		}
+	} else if !os.IsNotExist(err) {
+		return "", "", fmt.Errorf("read config file: %w", err)
+	}
+
 	newHash := cfg.Hash()
 	raw, err := marshalConfigYAML(cfg)
 	if err != nil {
-		return "", fmt.Errorf("marshal config: %w", err)
+		return "", "", fmt.Errorf("marshal config: %w", err)
 	}
 
 	mode := os.FileMode(0o644)
@@ -191,20 +239,20 @@ func (ins *InstanceManager) saveConfig(cfg *Config) (string, error) {
 		mode = info.Mode().Perm()
 		hasPrev = true
 	} else if !os.IsNotExist(statErr) {
-		return "", fmt.Errorf("stat config file: %w", statErr)
+		return "", "", fmt.Errorf("stat config file: %w", statErr)
 	}
 
 	if hasPrev {
 		_, err := createBackup(path, mode)
 		if err != nil {
-			return "", err
+			return "", "", err
 		}
 		go cleanupOldBackups(path)
 	}
 
 	tmpFile, err := os.CreateTemp(dir, filepath.Base(path)+".tmp.*")
 	if err != nil {
-		return "", fmt.Errorf("create temp config file: %w", err)
+		return "", "", fmt.Errorf("create temp config file: %w", err)
 	}
 	tmpPath := tmpFile.Name()
 	cleanup := true
@@ -216,34 +264,41 @@ func (ins *InstanceManager) saveConfig(cfg *Config) (string, error) {
 
 	if _, err := tmpFile.Write(raw); err != nil {
 		_ = tmpFile.Close()
-		return "", fmt.Errorf("write temp config file: %w", err)
+		return "", "", fmt.Errorf("write temp config file: %w", err)
 	}
 	if err := tmpFile.Close(); err != nil {
-		return "", fmt.Errorf("close temp config file: %w", err)
+		return "", "", fmt.Errorf("close temp config file: %w", err)
 	}
 	if err := os.Chmod(tmpPath, mode); err != nil {
-		return "", fmt.Errorf("chmod temp config file: %w", err)
+		return "", "", fmt.Errorf("chmod temp config file: %w", err)
 	}
 
 	if err := os.Rename(tmpPath, path); err != nil {
-		return "", fmt.Errorf("replace config file: %w", err)
+		return "", "", fmt.Errorf("replace config file: %w", err)
 	}
 
 	cleanup = false
-	return newHash, nil
+	return newHash, fileDigest(raw), nil
 }
 
-func loadConfigFile(path string) (*Config, error) {
+func readConfigFile(path string) ([]byte, *Config, error) {
 	path = strings.TrimSpace(path)
 	if path == "" {
-		return nil, fmt.Errorf("config path is required")
+		return nil, nil, fmt.Errorf("config path is required")
 	}
 
 	raw, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("read config file: %w", err)
+		return nil, nil, fmt.Errorf("read config file: %w", err)
 	}
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return raw, cfg, nil
+}
 
+func parseConfig(raw []byte) (*Config, error) {
 	var cfg Config
 	if err := yaml.Unmarshal(raw, &cfg); err != nil {
 		return nil, fmt.Errorf("parse config yaml: %w", err)
@@ -255,6 +310,11 @@ func loadConfigFile(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+func fileDigest(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
 func Load(path string) (*Config, error) {
 	return defaultManager.Load(path)
 }
@@ -275,10 +335,64 @@ func Save() error {
 	return defaultManager.Save()
 }
 
+func Reload() (bool, error) {
+	return defaultManager.Reload()
+}
+
 func Hash() (string, error) {
 	return defaultManager.Hash()
 }
 
+const maxUpdateRetries = 3
+
+// Update applies fn to a copy of the config and persists the result with
+// ApplyWithCAS and Save. fn reports whether it changed anything; nothing is
+// saved otherwise. When another writer got there first — in memory or on
+// disk — the config is reloaded and fn is run again on the fresh copy.
+func Update(fn func(cfg *Config) (bool, error)) (bool, error) {
+	for attempt := 0; attempt < maxUpdateRetries; attempt++ {
+		cfg, err := Get()
+		if err != nil {
+			return false, err
+		}
+		expectedHash, err := Hash()
+		if err != nil {
+			return false, err
+		}
+
+		changed, err := fn(cfg)
+		if err != nil || !changed {
+			return false, err
+		}
+
+		if err := ApplyWithCAS("config", cfg, expectedHash); err != nil {
+			if errors.Is(err, ErrConfigConflict) {
+				continue
+			}
+			return false, fmt.Errorf("apply config update: %w", err)
+		}
+		if err := Save(); err != nil {
+			if errors.Is(err, ErrConfigConflict) {
+				if _, err := Reload(); err != nil {
+					return false, fmt.Errorf("reload config: %w", err)
+				}
+				continue
+			}
+			return false, fmt.Errorf("save config update: %w", err)
+		}
+		return true, nil
+	}
+
+	return false, fmt.Errorf("%w: update still conflicting after %d retries", ErrConfigConflict, maxUpdateRetries)
+}
+
+// LockFile takes the cross-process lock for path, the same kind of lock
+// Save holds while it writes the config file. Callers release it with the
+// returned func.
+func LockFile(path string) (func(), error) {
+	return acquireFileLock(path+".lock", lockAcquireTimeout, lockStaleAfter)
+}
+
 func acquireFileLock(lockPath string, timeout, staleAfter time.Duration) (func(), error) {
 	start := time.Now()
 	for {
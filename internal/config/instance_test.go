@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadExample(t *testing.T) (*InstanceManager, string) {
+	t.Helper()
+	raw, err := os.ReadFile("../../config.yaml.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ins := &InstanceManager{}
+	if _, err := ins.Load(path); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return ins, path
+}
+
+func TestInstanceManager_SaveDetectsExternalEdit(t *testing.T) {
+	gateway, path := loadExample(t)
+	cli := &InstanceManager{}
+	if _, err := cli.Load(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// The CLI saves first; the gateway's stale copy must not clobber it.
+	edited, _ := cli.Get()
+	edited.Logging.Level = "debug"
+	if err := cli.Apply("config", edited); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Save(); err != nil {
+		t.Fatalf("cli Save: %v", err)
+	}
+	if err := gateway.Save(); !errors.Is(err, ErrConfigConflict) {
+		t.Fatalf("gateway Save = %v, want ErrConfigConflict", err)
+	}
+
+	reloaded, err := gateway.Reload()
+	if err != nil || !reloaded {
+		t.Fatalf("Reload = %v, %v; want true", reloaded, err)
+	}
+	cfg, _ := gateway.Get()
+	if cfg.Logging.Level != "debug" {
+		t.Fatalf("reloaded logging.level = %q, want debug", cfg.Logging.Level)
+	}
+	if err := gateway.Save(); err != nil {
+		t.Fatalf("Save after Reload: %v", err)
+	}
+	if reloaded, _ := gateway.Reload(); reloaded {
+		t.Fatal("Reload after own Save reported a change")
+	}
+}
@@ -3,15 +3,28 @@ package config
 import (
 	"errors"
 	"fmt"
+	"math"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/provider"
+	"github.com/tgifai/friday/internal/security/egress"
 )
 
 const (
 	defaultPairingWelcomeWindowSec = 300
 	defaultPairingMaxResp          = 3
+
+	defaultAmbientMaxMessages = 50
+	defaultAmbientMaxAge      = "1h"
+	defaultAmbientInject      = 20
+
+	defaultUsageCurrency = "USD"
+	defaultBudgetWarnAt  = 0.8
 )
 
 // Validate .
@@ -53,6 +66,45 @@ func (c *Config) Validate() error {
 			return errors.New("agent id cannot be empty")
 		}
 		one.ID = agentID
+
+		if one.Config.ParallelToolCalls < 0 {
+			return fmt.Errorf("agents[%s].config.parallel_tool_calls cannot be negative", agentID)
+		}
+		if err := one.Voice.STT.Validate(); err != nil {
+			return fmt.Errorf("agents[%s].voice.stt validation failed: %w", agentID, err)
+		}
+		if err := one.Voice.TTS.Validate(); err != nil {
+			return fmt.Errorf("agents[%s].voice.tts validation failed: %w", agentID, err)
+		}
+		if err := one.Security.Untrusted.Validate(); err != nil {
+			return fmt.Errorf("agents[%s].security.untrusted validation failed: %w", agentID, err)
+		}
+		if err := one.Security.Filesystem.Validate(); err != nil {
+			return fmt.Errorf("agents[%s].security.filesystem validation failed: %w", agentID, err)
+		}
+		if err := one.Security.Egress.Validate(); err != nil {
+			return fmt.Errorf("agents[%s].security.egress validation failed: %w", agentID, err)
+		}
+		if err := one.Security.Sandbox.Validate(); err != nil {
+			return fmt.Errorf("agents[%s].security.sandbox validation failed: %w", agentID, err)
+		}
+		for name, roles := range one.ToolRoles {
+			for i, r := range roles {
+				r = normalizeRole(r)
+				if !validRole(r) {
+					return fmt.Errorf("agents[%s].tool_roles[%s]: invalid role %q", agentID, name, roles[i])
+				}
+				roles[i] = r
+			}
+		}
+		if len(one.ToolRoles) > 0 {
+			// CLI backends run their own tools, so tool_roles cannot limit them.
+			for _, spec := range append([]string{one.Models.Primary}, one.Models.Fallback...) {
+				if c.IsCLIModel(spec) {
+					return fmt.Errorf("agents[%s].tool_roles: model %s is a CLI backend, which runs its own tools and cannot apply tool_roles", agentID, spec)
+				}
+			}
+		}
 		normalizedAgents[agentID] = one
 	}
 	c.Agents = normalizedAgents
@@ -71,6 +123,168 @@ func (c *Config) Validate() error {
 		normalizedChannels[channelID] = one
 	}
 	c.Channels = normalizedChannels
+
+	if err := c.Usage.Validate(); err != nil {
+		return fmt.Errorf("usage validation failed: %w", err)
+	}
+	if err := c.Redact.Validate(); err != nil {
+		return fmt.Errorf("redact validation failed: %w", err)
+	}
+	if err := c.Egress.Validate(); err != nil {
+		return fmt.Errorf("egress validation failed: %w", err)
+	}
+	return nil
+}
+
+// IsCLIModel reports whether the provider of model spec (provider_id:model)
+// is a CLI backend.
+func (c *Config) IsCLIModel(spec string) bool {
+	providerID, _, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	p, ok := c.Providers[providerID]
+	return ok && strings.EqualFold(strings.TrimSpace(p.Type), string(provider.CLI))
+}
+
+func (c *STTConfig) Validate() error {
+	if c == nil {
+		return errors.New("stt config cannot be nil")
+	}
+
+	c.Backend = strings.ToLower(strings.TrimSpace(c.Backend))
+	c.BaseURL = strings.TrimRight(strings.TrimSpace(c.BaseURL), "/")
+	c.Model = strings.TrimSpace(c.Model)
+	if c.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+
+	switch c.Backend {
+	case "":
+		return nil
+	case consts.STTBackendWhisper:
+		if c.BaseURL == "" {
+			return errors.New("base_url is required when backend=whisper")
+		}
+	case consts.STTBackendProvider:
+		if providerID, modelName, ok := strings.Cut(c.Model, ":"); !ok || providerID == "" || modelName == "" {
+			return fmt.Errorf("model must be provider_id:model_name when backend=provider, got %q", c.Model)
+		}
+	default:
+		return fmt.Errorf("invalid backend: %s", c.Backend)
+	}
+	return nil
+}
+
+func (c *UntrustedConfig) Validate() error {
+	if c == nil {
+		return errors.New("untrusted config cannot be nil")
+	}
+
+	c.Action = strings.ToLower(strings.TrimSpace(c.Action))
+	c.Trigger = strings.ToLower(strings.TrimSpace(c.Trigger))
+	switch c.Action {
+	case "", consts.UntrustedActionDeny, consts.UntrustedActionApprove, consts.UntrustedActionAllow:
+	default:
+		return fmt.Errorf("invalid action: %s", c.Action)
+	}
+	switch c.Trigger {
+	case "", consts.UntrustedTriggerUntrusted, consts.UntrustedTriggerSuspicious:
+	default:
+		return fmt.Errorf("invalid trigger: %s", c.Trigger)
+	}
+	return nil
+}
+
+func (c *FilesystemConfig) Validate() error {
+	if c == nil {
+		return errors.New("filesystem config cannot be nil")
+	}
+
+	c.Symlinks = strings.ToLower(strings.TrimSpace(c.Symlinks))
+	switch c.Symlinks {
+	case "", consts.SymlinksFollow, consts.SymlinksRefuse:
+	default:
+		return fmt.Errorf("invalid symlinks: %s", c.Symlinks)
+	}
+	if c.MaxReadBytes < 0 || c.MaxWriteBytes < 0 {
+		return errors.New("size limits cannot be negative")
+	}
+	for _, glob := range c.Deny {
+		if strings.TrimSpace(glob) == "" {
+			return errors.New("deny globs cannot be empty")
+		}
+	}
+	return nil
+}
+
+func (c *EgressConfig) Validate() error {
+	if c == nil {
+		return errors.New("egress config cannot be nil")
+	}
+
+	for _, list := range [][]string{c.Allow, c.Deny} {
+		for i, domain := range list {
+			domain = strings.ToLower(strings.TrimSpace(domain))
+			if domain == "" || strings.ContainsAny(domain, "/:@ \t") || strings.Contains(strings.TrimPrefix(domain, "*."), "*") {
+				return fmt.Errorf("invalid domain %q: want a host name such as example.com or *.example.com", list[i])
+			}
+			list[i] = domain
+		}
+	}
+	for _, network := range c.AllowNetworks {
+		if _, err := egress.ParseNetwork(network); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *TTSConfig) Validate() error {
+	if c == nil {
+		return errors.New("tts config cannot be nil")
+	}
+
+	c.Backend = strings.ToLower(strings.TrimSpace(c.Backend))
+	c.Reply = strings.ToLower(strings.TrimSpace(c.Reply))
+	c.BaseURL = strings.TrimRight(strings.TrimSpace(c.BaseURL), "/")
+	c.Format = strings.ToLower(strings.TrimSpace(c.Format))
+	if c.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	if c.MaxChars < 0 {
+		return errors.New("max_chars must not be negative")
+	}
+
+	switch c.Backend {
+	case "":
+		return nil
+	case consts.TTSBackendOpenAI:
+		if c.BaseURL == "" {
+			return errors.New("base_url is required when backend=openai")
+		}
+	case consts.TTSBackendCommand:
+		if len(c.Command) == 0 || strings.TrimSpace(c.Command[0]) == "" {
+			return errors.New("command is required when backend=command")
+		}
+	default:
+		return fmt.Errorf("invalid backend: %s", c.Backend)
+	}
+
+	if c.Reply == "" {
+		c.Reply = consts.VoiceReplyInbound
+	}
+	switch c.Reply {
+	case consts.VoiceReplyOff, consts.VoiceReplyInbound, consts.VoiceReplyAlways:
+	default:
+		return fmt.Errorf("invalid reply: %s", c.Reply)
+	}
+
+	if c.Format == "" {
+		c.Format = "opus"
+	}
+	switch c.Format {
+	case "opus", "mp3", "wav":
+	default:
+		return fmt.Errorf("invalid format: %s", c.Format)
+	}
 	return nil
 }
 
@@ -79,10 +293,35 @@ func (c *ChannelConfig) Validate() error {
 		return errors.New("channel config cannot be nil")
 	}
 
+	c.Session.Scope = strings.ToLower(strings.TrimSpace(c.Session.Scope))
+	switch c.Session.Scope {
+	case "", consts.SessionScopeChat, consts.SessionScopeUserInChat, consts.SessionScopeUser:
+	default:
+		return fmt.Errorf("invalid session.scope: %s", c.Session.Scope)
+	}
+	if err := c.Ambient.Validate(); err != nil {
+		return fmt.Errorf("ambient: %w", err)
+	}
+	if err := c.Security.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("security.rate_limit: %w", err)
+	}
+	if err := c.Roles.Validate(); err != nil {
+		return fmt.Errorf("roles: %w", err)
+	}
+	c.Security.ApprovalChat = strings.TrimSpace(c.Security.ApprovalChat)
+	c.Security.GrantTTL = strings.TrimSpace(c.Security.GrantTTL)
+	if c.Security.GrantTTL != "" {
+		if ttl, err := time.ParseDuration(c.Security.GrantTTL); err != nil || ttl <= 0 {
+			return fmt.Errorf("invalid security.grant_ttl %q: must be a positive duration such as 720h", c.Security.GrantTTL)
+		}
+	}
+
 	securityEmpty := c.Security.Policy == "" &&
 		c.Security.WelcomeWindow == 0 &&
 		c.Security.MaxResp == 0 &&
-		strings.TrimSpace(c.Security.CustomText) == ""
+		strings.TrimSpace(c.Security.CustomText) == "" &&
+		c.Security.ApprovalChat == "" &&
+		c.Security.GrantTTL == ""
 	if securityEmpty && len(c.ACL) == 0 {
 		return nil
 	}
@@ -124,8 +363,11 @@ func (c *ChannelConfig) Validate() error {
 		if chatID == "" {
 			return errors.New("acl key cannot be empty")
 		}
-		if !strings.HasPrefix(chatID, "group:") && !strings.HasPrefix(chatID, "user:") {
-			return fmt.Errorf("acl key must start with group: or user:, got %s", chatID)
+		if chatID != "*" && !strings.HasPrefix(chatID, "group:") && !strings.HasPrefix(chatID, "user:") {
+			return fmt.Errorf("acl key must be * or start with group: or user:, got %s", chatID)
+		}
+		if err := validateACLPattern(chatID); err != nil {
+			return fmt.Errorf("acl key %s: %w", chatID, err)
 		}
 
 		normalizeList := func(in []string) []string {
@@ -154,8 +396,223 @@ func (c *ChannelConfig) Validate() error {
 
 		one.Allow = normalizeList(one.Allow)
 		one.Block = normalizeList(one.Block)
+		for _, userID := range append(slices.Clone(one.Allow), one.Block...) {
+			if err := validateACLPattern(userID); err != nil {
+				return fmt.Errorf("acl %s user %s: %w", chatID, userID, err)
+			}
+		}
+		one.Reason = strings.TrimSpace(one.Reason)
+		for userID := range one.Grants {
+			if !slices.Contains(one.Allow, userID) {
+				delete(one.Grants, userID)
+			}
+		}
+		if len(one.Grants) == 0 {
+			one.Grants = nil
+		}
 		normalized[chatID] = one
 	}
 	c.ACL = normalized
 	return nil
 }
+
+// validateACLPattern accepts exact IDs and prefix patterns: "*" may only
+// appear once, as the last character.
+func validateACLPattern(pattern string) error {
+	if i := strings.IndexByte(pattern, '*'); i >= 0 && i != len(pattern)-1 {
+		return errors.New("* is only allowed at the end")
+	}
+	return nil
+}
+
+func (c *ChannelAmbientConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.MaxMessages < 0 || c.Inject < 0 {
+		return errors.New("max_messages and inject must not be negative")
+	}
+	if c.MaxMessages == 0 {
+		c.MaxMessages = defaultAmbientMaxMessages
+	}
+	if c.Inject == 0 {
+		c.Inject = defaultAmbientInject
+	}
+	if c.Inject > c.MaxMessages {
+		c.Inject = c.MaxMessages
+	}
+	c.MaxAge = strings.TrimSpace(c.MaxAge)
+	if c.MaxAge == "" {
+		c.MaxAge = defaultAmbientMaxAge
+	}
+	if d, err := time.ParseDuration(c.MaxAge); err != nil || d <= 0 {
+		return fmt.Errorf("invalid max_age: %q", c.MaxAge)
+	}
+	return nil
+}
+
+func (c *RateLimitConfig) Validate() error {
+	c.Reply = strings.TrimSpace(c.Reply)
+	rules := map[string]*RateLimitRule{
+		"turns.user":       &c.Turns.User,
+		"turns.chat":       &c.Turns.Chat,
+		"turns.channel":    &c.Turns.Channel,
+		"commands.user":    &c.Commands.User,
+		"commands.chat":    &c.Commands.Chat,
+		"commands.channel": &c.Commands.Channel,
+	}
+	for name, r := range rules {
+		if r.PerMinute < 0 || r.Burst < 0 {
+			return fmt.Errorf("%s: per_minute and burst must not be negative", name)
+		}
+		if r.PerMinute > 0 && r.Burst == 0 {
+			r.Burst = int(math.Ceil(r.PerMinute))
+		}
+	}
+	return nil
+}
+
+func (c *UsageConfig) Validate() error {
+	c.Currency = strings.TrimSpace(c.Currency)
+	if c.Currency == "" {
+		c.Currency = defaultUsageCurrency
+	}
+	normalized := make(map[string]ModelPrice, len(c.Prices))
+	for key, price := range c.Prices {
+		model := strings.TrimSpace(key)
+		if model == "" {
+			return errors.New("price model cannot be empty")
+		}
+		if price.Input < 0 || price.Output < 0 || price.CachedInput < 0 {
+			return fmt.Errorf("prices[%s]: prices must not be negative", model)
+		}
+		if price.CachedInput == 0 {
+			price.CachedInput = price.Input
+		}
+		normalized[model] = price
+	}
+	c.Prices = normalized
+
+	if err := c.Budget.Validate(); err != nil {
+		return fmt.Errorf("budget: %w", err)
+	}
+	return nil
+}
+
+func (c *BudgetConfig) Validate() error {
+	c.Action = strings.ToLower(strings.TrimSpace(c.Action))
+	switch c.Action {
+	case "":
+		c.Action = consts.BudgetActionRefuse
+	case consts.BudgetActionRefuse, consts.BudgetActionDowngrade:
+	default:
+		return fmt.Errorf("invalid action: %q", c.Action)
+	}
+	c.Downgrade = strings.TrimSpace(c.Downgrade)
+	c.Reply = strings.TrimSpace(c.Reply)
+	if c.WarnAt < 0 || c.WarnAt >= 1 {
+		return fmt.Errorf("warn_at must be between 0 and 1, got %v", c.WarnAt)
+	}
+	if c.WarnAt == 0 {
+		c.WarnAt = defaultBudgetWarnAt
+	}
+
+	if err := c.Scheduled.Validate(); err != nil {
+		return fmt.Errorf("scheduled: %w", err)
+	}
+	for name, scopes := range map[string]*map[string]BudgetLimits{
+		"agents":   &c.Agents,
+		"channels": &c.Channels,
+		"users":    &c.Users,
+	} {
+		normalized := make(map[string]BudgetLimits, len(*scopes))
+		for key, limits := range *scopes {
+			id := strings.TrimSpace(key)
+			if id == "" {
+				return fmt.Errorf("%s: key cannot be empty", name)
+			}
+			if err := limits.Validate(); err != nil {
+				return fmt.Errorf("%s[%s]: %w", name, id, err)
+			}
+			normalized[id] = limits
+		}
+		*scopes = normalized
+	}
+	return nil
+}
+
+func (c *BudgetLimits) Validate() error {
+	for _, l := range []BudgetLimit{c.Daily, c.Monthly} {
+		if l.Tokens < 0 || l.Cost < 0 {
+			return errors.New("tokens and cost must not be negative")
+		}
+	}
+	return nil
+}
+
+func (c *ChannelRolesConfig) Validate() error {
+	normalized := make(map[string]string, len(c.Users))
+	for userID, role := range c.Users {
+		userID = strings.TrimSpace(userID)
+		if userID == "" {
+			return errors.New("user id cannot be empty")
+		}
+		if role = normalizeRole(role); !validRole(role) {
+			return fmt.Errorf("users[%s]: invalid role %q", userID, role)
+		}
+		normalized[userID] = role
+	}
+	c.Users = normalized
+
+	c.Default = normalizeRole(c.Default)
+	if c.Default != "" && !validRole(c.Default) {
+		return fmt.Errorf("invalid default role %q", c.Default)
+	}
+	c.Paired = normalizeRole(c.Paired)
+	if c.Paired != "" && !validRole(c.Paired) {
+		return fmt.Errorf("invalid paired role %q", c.Paired)
+	}
+	// Unlisted users are owners only while no roles are configured. Once
+	// users or a paired role are set, somebody must be owner explicitly,
+	// or the operator would lose owner commands on the first pairing.
+	if (len(c.Users) > 0 || c.Paired != "") && c.Default == "" {
+		hasOwner := false
+		for _, role := range c.Users {
+			hasOwner = hasOwner || role == consts.RoleOwner
+		}
+		if !hasOwner {
+			return errors.New("users or paired is set: list an owner in users or set a default role")
+		}
+	}
+	return nil
+}
+
+func normalizeRole(role string) string {
+	return strings.ToLower(strings.TrimSpace(role))
+}
+
+func validRole(role string) bool {
+	switch role {
+	case consts.RoleGuest, consts.RoleMember, consts.RoleAdmin, consts.RoleOwner:
+		return true
+	}
+	return false
+}
+
+func (c *RedactConfig) Validate() error {
+	if c == nil {
+		return errors.New("redact config cannot be nil")
+	}
+
+	for i, p := range c.Patterns {
+		p.Name = strings.TrimSpace(p.Name)
+		if p.Name == "" || strings.ContainsAny(p.Name, "]# \t") {
+			return fmt.Errorf("patterns[%d]: invalid name %q", i, p.Name)
+		}
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("patterns[%s]: %w", p.Name, err)
+		}
+		c.Patterns[i] = p
+	}
+	return nil
+}
@@ -7,3 +7,42 @@ const (
 	SecurityPolicySilent  SecurityPolicy = "silent"
 	SecurityPolicyCustom  SecurityPolicy = "custom"
 )
+
+// Roles assigned to users via channels.<id>.roles, from least to most
+// privileged. Commands and tools name the lowest role allowed to use them.
+const (
+	RoleGuest  = "guest"
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+	RoleOwner  = "owner"
+)
+
+// How a user came to be on a chat's allow list, recorded in
+// channels.<id>.acl.<chatKey>.grants.
+const (
+	GrantViaPairingCode   = "pairing_code"
+	GrantViaOwnerApproval = "owner_approval"
+	GrantViaCLI           = "cli"
+)
+
+// What happens when a restricted tool is called after untrusted content
+// entered the turn, configured in agents.<id>.security.untrusted.action.
+const (
+	UntrustedActionDeny    = "deny"
+	UntrustedActionApprove = "approve" // refuse, and let the user allow it once with /trust
+	UntrustedActionAllow   = "allow"
+)
+
+// When the restriction applies, configured in
+// agents.<id>.security.untrusted.trigger.
+const (
+	UntrustedTriggerUntrusted  = "untrusted"  // after any untrusted tool result
+	UntrustedTriggerSuspicious = "suspicious" // only after a result with likely prompt injection
+)
+
+// How file tools treat symlinks, configured in
+// agents.<id>.security.filesystem.symlinks.
+const (
+	SymlinksFollow = "follow" // resolve them and check the target
+	SymlinksRefuse = "refuse" // deny any path through a symlink
+)
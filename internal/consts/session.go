@@ -0,0 +1,8 @@
+package consts
+
+// Session scoping modes selectable via channels.<id>.session.scope.
+const (
+	SessionScopeChat       = "chat"         // one session per chat (default)
+	SessionScopeUserInChat = "user_in_chat" // one session per user within each chat
+	SessionScopeUser       = "user"         // one session per user across all chats of the channel
+)
@@ -0,0 +1,7 @@
+package consts
+
+// Actions taken when a usage budget is exhausted (usage.budget.action).
+const (
+	BudgetActionRefuse    = "refuse"    // reply with the budget notice instead of running the turn
+	BudgetActionDowngrade = "downgrade" // run the turn on the downgrade model only
+)
@@ -0,0 +1,21 @@
+package consts
+
+// Speech-to-text backends selectable via agents.<id>.voice.stt.backend.
+const (
+	STTBackendWhisper  = "whisper"
+	STTBackendProvider = "provider"
+)
+
+// Text-to-speech backends selectable via agents.<id>.voice.tts.backend.
+const (
+	TTSBackendOpenAI  = "openai"
+	TTSBackendCommand = "command"
+)
+
+// Voice reply modes, configured per agent (voice.tts.reply) and overridable
+// per chat with the /voice command.
+const (
+	VoiceReplyOff     = "off"
+	VoiceReplyInbound = "inbound" // reply by voice when the user sent a voice note
+	VoiceReplyAlways  = "always"
+)
@@ -59,6 +59,13 @@ func DailyMemoryFile(date time.Time) string {
 	return filepath.Join(WorkspaceMemoryDailyDir, date.Format("2006-01-02")+".md")
 }
 
+// WorkspaceUploadsDir holds files received from chat channels, grouped by day.
+const WorkspaceUploadsDir = "uploads"
+
+func UploadsDir(date time.Time) string {
+	return filepath.Join(WorkspaceUploadsDir, date.Format("2006-01-02"))
+}
+
 var WorkspaceMarkdownTemplates = map[string]string{
 	"AGENTS.md":        WorkspaceAgentsTemplate,
 	"SOUL.md":          WorkspaceSoulTemplate,
@@ -4,10 +4,15 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/tgifai/friday/internal/channel"
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
 	"github.com/tgifai/friday/internal/cronjob"
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/role"
+	"github.com/tgifai/friday/internal/usage"
 )
 
 // RegisterBuiltins registers all built-in commands on the hub.
@@ -26,16 +31,49 @@ func RegisterBuiltins(h *Hub) {
 		Name:        "/status",
 		Description: "Show current agent and session status",
 		Handler:     cmdStatus,
+		Role:        consts.RoleMember,
 	})
 	h.Register(&Command{
 		Name:        "/cronjob",
 		Description: "List all scheduled cron jobs",
 		Handler:     cmdCronjob,
+		Role:        consts.RoleAdmin,
 	})
 	h.Register(&Command{
 		Name:        "/new",
 		Description: "Clear current session and start a new conversation",
 		Handler:     cmdNew,
+		Role:        consts.RoleMember,
+	})
+	h.Register(&Command{
+		Name:        "/voice",
+		Description: "Voice replies for this chat: on, off, auto or default",
+		Handler:     cmdVoice,
+		Role:        consts.RoleMember,
+	})
+	h.Register(&Command{
+		Name:        "/trust",
+		Description: "Allow a tool held back after reading untrusted content, once",
+		Handler:     cmdTrust,
+		Role:        consts.RoleMember,
+	})
+	h.Register(&Command{
+		Name:        "/usage",
+		Description: "Show your token usage and cost: today, week or month",
+		Handler:     cmdUsage,
+		Role:        consts.RoleMember,
+	})
+	h.Register(&Command{
+		Name:        "/approve",
+		Description: "Approve a pairing request (owner, approval chat only)",
+		Handler:     cmdApprove,
+		Role:        consts.RoleOwner,
+	})
+	h.Register(&Command{
+		Name:        "/deny",
+		Description: "Deny a pairing request (owner, approval chat only)",
+		Handler:     cmdDeny,
+		Role:        consts.RoleOwner,
 	})
 }
 
@@ -43,10 +81,13 @@ func cmdStart(_ context.Context, _ HandlerDeps, _ *channel.Message) (string, err
 	return "Welcome! I'm Friday, your AI assistant. How can I help you today?", nil
 }
 
-func cmdHelp(_ context.Context, deps HandlerDeps, _ *channel.Message) (string, error) {
+func cmdHelp(_ context.Context, deps HandlerDeps, msg *channel.Message) (string, error) {
 	var b strings.Builder
 	b.WriteString("Available commands:\n")
 	for _, cmd := range deps.Commands().List() {
+		if !role.Allows(msg.Role, cmd.Role) {
+			continue
+		}
 		fmt.Fprintf(&b, "  %s - %s\n", cmd.Name, cmd.Description)
 	}
 	return b.String(), nil
@@ -87,3 +128,91 @@ func cmdNew(ctx context.Context, deps HandlerDeps, msg *channel.Message) (string
 	}
 	return ag.ResetSession(ctx, msg)
 }
+
+func cmdVoice(ctx context.Context, deps HandlerDeps, msg *channel.Message) (string, error) {
+	ag, err := deps.GetAgentByChannel(msg.ChannelID)
+	if err != nil {
+		return "", err
+	}
+
+	_, args, _ := deps.Commands().Match(msg.Content)
+	var mode string
+	switch strings.ToLower(args) {
+	case "":
+	case "on", "always":
+		mode = consts.VoiceReplyAlways
+	case "off":
+		mode = consts.VoiceReplyOff
+	case "auto", "inbound":
+		mode = consts.VoiceReplyInbound
+	case "default":
+		mode = "default"
+	default:
+		return "Usage: /voice [on|off|auto|default]\n" +
+			"  on - always reply with voice\n" +
+			"  off - always reply with text\n" +
+			"  auto - reply with voice when you send a voice message\n" +
+			"  default - use the agent setting", nil
+	}
+	return ag.SetVoiceReply(ctx, msg, mode)
+}
+
+func cmdTrust(ctx context.Context, deps HandlerDeps, msg *channel.Message) (string, error) {
+	ag, err := deps.GetAgentByChannel(msg.ChannelID)
+	if err != nil {
+		return "", err
+	}
+	return ag.TrustTool(ctx, msg)
+}
+
+func cmdUsage(_ context.Context, deps HandlerDeps, msg *channel.Message) (string, error) {
+	ag, err := deps.GetAgentByChannel(msg.ChannelID)
+	if err != nil {
+		return "", err
+	}
+
+	_, args, _ := deps.Commands().Match(msg.Content)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var since time.Time
+	switch period := strings.ToLower(args); period {
+	case "today":
+		since = today
+	case "week":
+		since = today.AddDate(0, 0, -6)
+	case "", "month":
+		since = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	default:
+		return "Usage: /usage [today|week|month]", nil
+	}
+
+	records, err := usage.Default().Query(usage.Filter{
+		Since:   since,
+		AgentID: ag.ID(),
+		UserID:  msg.UserID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "No usage recorded since " + since.Format("2006-01-02"), nil
+	}
+
+	currency := "USD"
+	if cfg, err := config.Get(); err == nil && cfg.Usage.Currency != "" {
+		currency = cfg.Usage.Currency
+	}
+
+	var b strings.Builder
+	total := usage.Total(usage.Summarize(records))
+	fmt.Fprintf(&b, "Usage since %s\n", since.Format("2006-01-02"))
+	fmt.Fprintf(&b, "Calls: %d\n", total.Calls)
+	fmt.Fprintf(&b, "Tokens: %s (prompt %s, completion %s)\n", usage.FormatTokens(total.TotalTokens),
+		usage.FormatTokens(total.PromptTokens), usage.FormatTokens(total.CompletionTokens))
+	fmt.Fprintf(&b, "Cost: %.4f %s\n", total.Cost, currency)
+	b.WriteString("\nBy model:\n")
+	for _, row := range usage.Summarize(records, usage.ByModel) {
+		fmt.Fprintf(&b, "  %s - %s tokens, %.4f %s\n", row.Keys[0], usage.FormatTokens(row.TotalTokens), row.Cost, currency)
+	}
+	return b.String(), nil
+}
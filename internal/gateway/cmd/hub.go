@@ -18,6 +18,8 @@ type AgentInfo interface {
 	ID() string
 	Name() string
 	ResetSession(ctx context.Context, msg *channel.Message) (string, error)
+	SetVoiceReply(ctx context.Context, msg *channel.Message, mode string) (string, error)
+	TrustTool(ctx context.Context, msg *channel.Message) (string, error)
 }
 
 // HandlerDeps is the dependency interface for command handlers, implemented
@@ -33,6 +35,7 @@ type Command struct {
 	Name        string      // e.g. "/start"
 	Description string      // short help text
 	Handler     HandlerFunc // execution logic
+	Role        string      // lowest role allowed to run it; empty means everyone
 }
 
 // Hub is a thread-safe registry that matches incoming message text against
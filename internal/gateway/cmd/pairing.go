@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tgifai/friday/internal/channel"
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/pairing"
+)
+
+const (
+	pairingApprovedText = "Your access request was approved. You can now use this bot."
+	pairingDeniedText   = "Your access request was declined."
+)
+
+func cmdApprove(ctx context.Context, deps HandlerDeps, msg *channel.Message) (string, error) {
+	return resolvePairing(ctx, deps, msg, true)
+}
+
+func cmdDeny(ctx context.Context, deps HandlerDeps, msg *channel.Message) (string, error) {
+	return resolvePairing(ctx, deps, msg, false)
+}
+
+// resolvePairing approves or denies a forwarded pairing request. It only
+// works in the channel's approval chat; without a request ID it lists the
+// pending requests. /approve takes an optional duration after the ID for a
+// time-limited grant.
+func resolvePairing(ctx context.Context, deps HandlerDeps, msg *channel.Message, approve bool) (string, error) {
+	cfg, err := config.Get()
+	if err != nil {
+		return "", err
+	}
+	chCfg, ok := cfg.Channels[msg.ChannelID]
+	if !ok || chCfg.Security.ApprovalChat == "" || chCfg.Security.ApprovalChat != msg.ChatID {
+		return "Pairing approval is not enabled in this chat.", nil
+	}
+
+	mgr := pairing.Get(pairing.GetKey(string(msg.ChannelType), msg.ChannelID))
+	_, args, _ := deps.Commands().Match(msg.Content)
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		pending, err := mgr.PendingRequests()
+		if err != nil {
+			return "", err
+		}
+		if len(pending) == 0 {
+			return "No pending pairing requests.", nil
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "Pending pairing requests (%d):\n", len(pending))
+		for _, req := range pending {
+			b.WriteString("\n")
+			b.WriteString(pairing.FormatRequest(req))
+			b.WriteString("\n")
+		}
+		return b.String(), nil
+	}
+
+	reqID := fields[0]
+	grant := config.ACLGrant{Via: consts.GrantViaOwnerApproval}
+	if approve && len(fields) > 1 {
+		ttl, err := time.ParseDuration(fields[1])
+		if err != nil || ttl <= 0 {
+			return fmt.Sprintf("Invalid duration %q; use e.g. 24h or 30m.", fields[1]), nil
+		}
+		grant.Expires = time.Now().Add(ttl)
+	}
+
+	req, err := mgr.ResolveRequest(reqID, approve)
+	if err != nil {
+		return fmt.Sprintf("Request %s: %v.", reqID, err), nil
+	}
+
+	notice := pairingDeniedText
+	if approve {
+		if _, err := mgr.GrantACL(req.ChatKey, req.UserID, grant); err != nil {
+			logs.CtxError(ctx, "[security] grant acl failed: %v", err)
+			return fmt.Sprintf("Approving %s failed: %v", req.ReqID, err), nil
+		}
+		notice = pairingApprovedText
+	}
+	logs.CtxInfo(ctx,
+		"[security] pairing_result channel_id=%s user_id=%s chat_key=%s req_id=%s success=%t reason=owner_decision",
+		msg.ChannelID, req.UserID, req.ChatKey, req.ReqID, approve,
+	)
+
+	if ch, err := channel.Get(msg.ChannelID); err == nil {
+		if err := ch.SendMessage(ctx, req.ChatID, notice, channel.WithThread(req.ThreadID)); err != nil {
+			logs.CtxWarn(ctx, "[security] notify pairing requester %s failed: %v", req.UserID, err)
+		}
+	}
+
+	if approve {
+		return fmt.Sprintf("Approved %s: user %s can now use %s.", req.ReqID, req.UserID, req.ChatKey), nil
+	}
+	return fmt.Sprintf("Denied %s: user %s was told the request was declined.", req.ReqID, req.UserID), nil
+}
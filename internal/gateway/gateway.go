@@ -3,6 +3,7 @@ package gateway
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -19,7 +20,6 @@ import (
 
 	"github.com/tgifai/friday/internal/agent"
 	"github.com/tgifai/friday/internal/agent/tool/browserx"
-	"github.com/tgifai/friday/internal/agent/session"
 	"github.com/tgifai/friday/internal/channel"
 	httpChannel "github.com/tgifai/friday/internal/channel/http"
 	"github.com/tgifai/friday/internal/channel/lark"
@@ -39,6 +39,18 @@ import (
 	"github.com/tgifai/friday/internal/provider/ollama"
 	"github.com/tgifai/friday/internal/provider/openai"
 	"github.com/tgifai/friday/internal/provider/qwen"
+	"github.com/tgifai/friday/internal/security/pairing"
+	"github.com/tgifai/friday/internal/security/ratelimit"
+	"github.com/tgifai/friday/internal/security/role"
+	"github.com/tgifai/friday/internal/security/secrets"
+)
+
+const (
+	// configReloadInterval is how often the config file is checked for
+	// edits made by other processes.
+	configReloadInterval = 5 * time.Second
+	// grantPruneInterval is how often expired ACL grants are removed.
+	grantPruneInterval = time.Minute
 )
 
 type Gateway struct {
@@ -46,7 +58,10 @@ type Gateway struct {
 	cmds       *cmd.Hub
 	security   *SecurityGuard
 	msgQueue   *MessageQueue
+	turns      *turnTracker
 	httpServer *hzServer.Hertz
+	// macOSChannel is set when the built-in macOS app channel was injected.
+	macOSChannel bool
 
 	runCtx    context.Context
 	runCancel context.CancelFunc
@@ -89,7 +104,8 @@ func NewGateway(cfg config.GatewayConfig) *Gateway {
 	gw := &Gateway{
 		httpServer: hzSvr,
 		cmds:       cmd.NewHub(),
-		security:   &SecurityGuard{},
+		security:   newSecurityGuard(ratelimit.New(filepath.Join(consts.FridayHomeDir(), rateLimitStatePath))),
+		turns:      newTurnTracker(),
 		msgQueue: newMessageQueue(QueueOptions{
 			LaneBuffer:    10,
 			MaxConcurrent: cfg.MaxConcurrentSessions,
@@ -108,9 +124,13 @@ func (gw *Gateway) Start(ctx context.Context) error {
 		return err
 	}
 
+	if err := initRedactor(gw.runCtx, cfg); err != nil {
+		return fmt.Errorf("init redactor: %w", err)
+	}
 	if err := gw.msgQueue.Init(gw.runCtx, gw.processMessage); err != nil {
 		return fmt.Errorf("init msg queue: %w", err)
 	}
+	gw.security.initRateLimiter(gw.runCtx)
 	if err := gw.initHTTPServer(gw.runCtx, cfg.Gateway); err != nil {
 		return fmt.Errorf("init http server: %w", err)
 	}
@@ -126,11 +146,52 @@ func (gw *Gateway) Start(ctx context.Context) error {
 
 	gw.cmds.SyncToChannels(gw.runCtx)
 
+	go gw.watchConfig(gw.runCtx)
 	go gw.httpServer.Spin()
 
 	return nil
 }
 
+// watchConfig picks up config edits saved by other processes, such as
+// `friday pairing`, so ACL and role changes apply without a restart.
+// Providers, agents and channels keep the settings they started with. It
+// also drops expired ACL grants from the config.
+func (gw *Gateway) watchConfig(ctx context.Context) {
+	ticker := time.NewTicker(configReloadInterval)
+	defer ticker.Stop()
+	prune := time.NewTicker(grantPruneInterval)
+	defer prune.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-prune.C:
+			n, err := pairing.PruneExpiredGrants(time.Now())
+			if err != nil {
+				logs.CtxWarn(ctx, "[security] prune expired grants: %v", err)
+			} else if n > 0 {
+				logs.CtxInfo(ctx, "[security] removed %d expired acl grant(s)", n)
+			}
+		case <-ticker.C:
+			reloaded, err := config.Reload()
+			if err != nil {
+				logs.CtxWarn(ctx, "[gateway] reload config: %v", err)
+				continue
+			}
+			if reloaded {
+				logs.CtxInfo(ctx, "[gateway] config changed on disk, reloaded")
+				// The reload replaced the in-memory config; restore the
+				// bindings that were never written to disk.
+				if gw.macOSChannel {
+					if err := gw.bindMacOSChannel(ctx); err != nil {
+						logs.CtxWarn(ctx, "[gateway] %v", err)
+					}
+				}
+			}
+		}
+	}
+}
+
 func (gw *Gateway) Stop(ctx context.Context) error {
 	gw.stopOnce.Do(func() {
 		if gw.runCancel != nil {
@@ -160,6 +221,8 @@ func (gw *Gateway) Stop(ctx context.Context) error {
 			logs.CtxWarn(ctx, "[gateway] shutdown http server error: %v", err)
 		}
 
+		gw.security.saveRateLimiter(ctx)
+
 		logs.CtxInfo(ctx, "[gateway] all resources stopped")
 	})
 	return nil
@@ -185,9 +248,12 @@ func (gw *Gateway) initProviders(ctx context.Context, providers map[string]confi
 }
 
 func newProvider(ctx context.Context, cfg config.ProviderConfig) (provider.Provider, error) {
-	cfgMap := make(map[string]interface{}, len(cfg.Config))
-	for k, v := range cfg.Config {
-		cfgMap[k] = v
+	cfgMap, err := secrets.ExpandMap(cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+	if cfgMap == nil {
+		cfgMap = make(map[string]interface{})
 	}
 
 	switch provider.Type(strings.ToLower(strings.TrimSpace(cfg.Type))) {
@@ -316,8 +382,19 @@ func (gw *Gateway) injectMacOSChannel(ctx context.Context) error {
 	if err := gw.registerChannel(ctx, consts.MacOSAppChannelID, chCfg); err != nil {
 		return err
 	}
+	gw.macOSChannel = true
+	if err := gw.bindMacOSChannel(ctx); err != nil {
+		return err
+	}
 
-	// Bind the built-in channel to all agents so they can receive messages from the app.
+	logs.CtxInfo(ctx, "[gateway] injected built-in macOS app channel #%s", consts.MacOSAppChannelID)
+	return nil
+}
+
+// bindMacOSChannel binds the built-in macOS app channel to all agents so
+// they can receive messages from the app. The binding lives in memory only
+// and is made again whenever the config is reloaded from disk.
+func (gw *Gateway) bindMacOSChannel(ctx context.Context) error {
 	cfg, err := config.Get()
 	if err != nil {
 		return fmt.Errorf("get config for macos channel binding: %w", err)
@@ -339,12 +416,16 @@ func (gw *Gateway) injectMacOSChannel(ctx context.Context) error {
 	if err := config.Apply("agents", &updated); err != nil {
 		logs.CtxWarn(ctx, "[gateway] failed to bind macos channel to agents: %v", err)
 	}
-
-	logs.CtxInfo(ctx, "[gateway] injected built-in macOS app channel #%s", consts.MacOSAppChannelID)
 	return nil
 }
 
 func newChannel(id string, cfg config.ChannelConfig) (channel.Channel, error) {
+	expanded, err := secrets.ExpandMap(cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Config = expanded
+
 	switch channel.Type(strings.ToLower(strings.TrimSpace(cfg.Type))) {
 	case channel.Telegram:
 		return telegram.NewChannel(id, &cfg)
@@ -381,11 +462,38 @@ func (gw *Gateway) Enqueue(ctx context.Context, msg *channel.Message) error {
 		if err != nil {
 			return err
 		}
-		msg.SessionKey = session.GenerateKey(ag.ID(), msg.ChannelType, msg.ChannelID, msg.ChatID)
+		msg.SessionKey = ag.SessionKeyFor(msg)
+	}
+
+	switch msg.Event {
+	case "":
+		if msg.ChannelType == channel.Type("cron") {
+			break
+		}
+		gw.turns.track(msg)
+	case channel.EventEdited:
+		if !gw.turns.supersede(msg) {
+			logs.CtxDebug(ctx, "[msg] ignoring edit of %s: not the latest turn of %s", msg.ID, msg.SessionKey)
+			return nil
+		}
+	case channel.EventDeleted:
+		gw.turns.supersede(msg)
 	}
 	return gw.msgQueue.Enqueue(ctx, msg)
 }
 
+// checkRate applies the channel's rate limits to a new message from an
+// accepted sender. Only senders the security check let through draw from
+// the shared buckets or get a "slow down" reply.
+func (gw *Gateway) checkRate(ctx context.Context, ch channel.Channel, msg *channel.Message, chCfg config.ChannelConfig) bool {
+	_, _, isCommand := gw.cmds.Match(msg.Content)
+	allowed, reply := gw.security.CheckRate(ctx, msg, chCfg, isCommand)
+	if reply != "" {
+		_ = ch.SendMessage(ctx, msg.ChatID, reply, channel.WithReplyTo(msg.ID), channel.WithThread(msg.ThreadID))
+	}
+	return allowed
+}
+
 func (gw *Gateway) processMessage(ctx context.Context, msg *channel.Message) error {
 	if msg == nil {
 		return fmt.Errorf("message cannot be nil")
@@ -412,6 +520,26 @@ func (gw *Gateway) processMessage(ctx context.Context, msg *channel.Message) err
 		return fmt.Errorf("channel %s not found: %w", msg.ChannelID, err)
 	}
 
+	// A recalled message only needs to leave the session history.
+	if msg.Event == channel.EventDeleted {
+		ag, err := gw.getAgentByChannel(msg.ChannelID)
+		if err != nil {
+			return err
+		}
+		ag.ForgetMessage(ctx, msg)
+		return nil
+	}
+
+	// Every path below ends the turn, so the tracker never keeps it running
+	// or marked as superseded.
+	turnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if !gw.turns.begin(msg, cancel) {
+		logs.CtxDebug(ctx, "[msg] skipping %s: superseded before it ran", msg.ID)
+		return nil
+	}
+	defer gw.turns.finish(msg)
+
 	// 1. Security check (ACL + pairing).
 	cfg, err := config.Get()
 	if err != nil {
@@ -421,21 +549,44 @@ func (gw *Gateway) processMessage(ctx context.Context, msg *channel.Message) err
 	if chCfgOK {
 		allowed, reply := gw.security.Check(ctx, msg, chCfg)
 		if reply != "" {
-			_ = ch.SendMessage(ctx, msg.ChatID, reply)
+			_ = ch.SendMessage(ctx, msg.ChatID, reply, channel.WithThread(msg.ThreadID))
 		}
 		if !allowed {
 			return nil
 		}
+		if msg.Event == "" && !gw.checkRate(ctx, ch, msg, chCfg) {
+			return nil
+		}
+	}
+	msg.Role = role.Resolve(chCfg.Roles, msg.UserID)
+
+	// An edit re-runs the turn: drop the earlier attempt from the session and
+	// retract what was already sent in reply.
+	if msg.Event == channel.EventEdited {
+		if ag, err := gw.getAgentByChannel(msg.ChannelID); err == nil {
+			ag.ForgetMessage(ctx, msg)
+		}
+		if r, ok := ch.(channel.MessageRetractor); ok {
+			if err := r.RetractReplies(ctx, msg.ChatID, msg.ID); err != nil {
+				logs.CtxWarn(ctx, "[msg] retract replies to %s failed: %v", msg.ID, err)
+			}
+		}
+		logs.CtxInfo(ctx, "[msg] re-running turn for edited message %s", msg.ID)
 	}
 
 	// 2. Command interception — bypass agent for built-in cmds.
 	if cmd, _, matched := gw.cmds.Match(msg.Content); matched {
+		if !role.Allows(msg.Role, cmd.Role) {
+			logs.CtxInfo(ctx, "[security] command %s denied: user %s has role %s, needs %s", cmd.Name, msg.UserID, msg.Role, cmd.Role)
+			_ = ch.SendMessage(ctx, msg.ChatID, "You don't have permission to use "+cmd.Name+".", channel.WithThread(msg.ThreadID))
+			return nil
+		}
 		reply, cmdErr := cmd.Handler(ctx, gw, msg)
 		if cmdErr != nil {
 			return fmt.Errorf("command %s failed: %w", cmd.Name, cmdErr)
 		}
 		if reply != "" {
-			_ = ch.SendMessage(ctx, msg.ChatID, reply)
+			_ = ch.SendMessage(ctx, msg.ChatID, reply, channel.WithThread(msg.ThreadID))
 		}
 		return nil
 	}
@@ -446,10 +597,18 @@ func (gw *Gateway) processMessage(ctx context.Context, msg *channel.Message) err
 		return err
 	}
 	ctx = context.WithValue(ctx, consts.CtxKeyAgentID, ag.ID())
+	turnCtx = context.WithValue(turnCtx, consts.CtxKeyAgentID, ag.ID())
+	if src, ok := ch.(channel.AmbientSource); ok {
+		msg.Ambient = src.DrainAmbient(msg.ChatID, msg.ThreadID)
+	}
 
 	stopWIP, _ := ch.WorkInProgress(ctx, msg.ChatID, msg.ID)
-	resp, err := ag.ProcessMessage(ctx, msg)
+	resp, err := ag.ProcessMessage(turnCtx, msg)
 	stopWIP()
+	if turnCtx.Err() != nil && ctx.Err() == nil {
+		logs.CtxInfo(ctx, "[msg] turn for %s cancelled: message was edited or recalled", msg.ID)
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("agent %s process message failed: %w", ag.ID(), err)
 	}
@@ -458,13 +617,32 @@ func (gw *Gateway) processMessage(ctx context.Context, msg *channel.Message) err
 		return nil
 	}
 
-	if err := ch.SendMessage(ctx, msg.ChatID, resp.Content, channel.WithReplyTo(msg.ID)); err != nil {
+	if err := gw.sendReply(ctx, ch, msg, resp); err != nil {
 		return fmt.Errorf("send reply via channel %s failed: %w", msg.ChannelID, err)
 	}
 	logs.CtxDebug(ctx, "[msg] -> (%s/%s#%s) %s", msg.ChannelType, msg.ChannelID, msg.ChatID, pkgutils.Truncate80(resp.Content))
 	return nil
 }
 
+// sendReply delivers an agent response. Voice replies go out as a voice note
+// followed by any unspoken text; if the voice upload fails the full text
+// reply is sent instead.
+func (gw *Gateway) sendReply(ctx context.Context, ch channel.Channel, msg *channel.Message, resp *channel.Response) error {
+	if resp.Voice != nil {
+		if vs, ok := ch.(channel.VoiceSender); ok {
+			err := vs.SendVoice(ctx, msg.ChatID, resp.Voice.Audio, channel.WithReplyTo(msg.ID), channel.WithThread(msg.ThreadID), channel.WithOrigin(msg.ID))
+			if err == nil {
+				if resp.Voice.Text == "" {
+					return nil
+				}
+				return ch.SendMessage(ctx, msg.ChatID, resp.Voice.Text, channel.WithThread(msg.ThreadID), channel.WithOrigin(msg.ID))
+			}
+			logs.CtxWarn(ctx, "[msg] send voice reply via %s failed, falling back to text: %v", msg.ChannelID, err)
+		}
+	}
+	return ch.SendMessage(ctx, msg.ChatID, resp.Content, channel.WithReplyTo(msg.ID), channel.WithThread(msg.ThreadID), channel.WithOrigin(msg.ID))
+}
+
 func (gw *Gateway) processCronMessage(ctx context.Context, msg *channel.Message) error {
 	agentID := msg.Metadata["agent_id"]
 	if agentID == "" {
@@ -0,0 +1,112 @@
+package gateway
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tgifai/friday/internal/channel"
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/role"
+)
+
+// ownerPresenceTTL is how long a membership lookup, or an owner's message
+// in a chat, is trusted.
+const ownerPresenceTTL = 10 * time.Minute
+
+// ownerPresence answers whether a channel owner belongs to a group chat,
+// for ACL rules with owner_present. Channels implementing
+// channel.MemberChecker are asked directly; for the others an owner counts
+// as present for ownerPresenceTTL after they last wrote in the chat. A
+// lookup that fails counts as absent.
+type ownerPresence struct {
+	mu      sync.Mutex
+	seen    map[string]time.Time     // channelID:chatID → when an owner last wrote
+	checked map[string]presenceCheck // channelID:chatID → cached membership lookup
+}
+
+type presenceCheck struct {
+	present bool
+	at      time.Time
+}
+
+func newOwnerPresence() *ownerPresence {
+	return &ownerPresence{
+		seen:    make(map[string]time.Time),
+		checked: make(map[string]presenceCheck),
+	}
+}
+
+// observe records a message from a channel owner in a group chat.
+func (p *ownerPresence) observe(msg *channel.Message, chCfg config.ChannelConfig, chatKey string) {
+	if !strings.HasPrefix(chatKey, "group:") || !isOwner(chCfg, msg.UserID) {
+		return
+	}
+	now := time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, at := range p.seen {
+		if now.Sub(at) >= ownerPresenceTTL {
+			delete(p.seen, key)
+		}
+	}
+	p.seen[msg.ChannelID+":"+msg.ChatID] = now
+}
+
+// lookup returns the acl.Request.OwnerPresent callback for msg's chat. The
+// lookup only runs if a rule needs it.
+func (p *ownerPresence) lookup(ctx context.Context, msg *channel.Message, chCfg config.ChannelConfig) func() bool {
+	return func() bool {
+		owners := role.Owners(chCfg.Roles)
+		if len(owners) == 0 {
+			return false
+		}
+		key := msg.ChannelID + ":" + msg.ChatID
+
+		p.mu.Lock()
+		if c, ok := p.checked[key]; ok && time.Since(c.at) < ownerPresenceTTL {
+			p.mu.Unlock()
+			return c.present
+		}
+		at, ok := p.seen[key]
+		seen := ok && time.Since(at) < ownerPresenceTTL
+		p.mu.Unlock()
+
+		ch, err := channel.Get(msg.ChannelID)
+		if err != nil {
+			return false
+		}
+		checker, ok := ch.(channel.MemberChecker)
+		if !ok {
+			return seen
+		}
+		present := false
+		for _, owner := range owners {
+			member, err := checker.IsChatMember(ctx, msg.ChatID, owner)
+			if err != nil {
+				logs.CtxWarn(ctx, "[security] owner presence check in %s failed: %v", msg.ChatID, err)
+				return false
+			}
+			if member {
+				present = true
+				break
+			}
+		}
+
+		p.mu.Lock()
+		p.checked[key] = presenceCheck{present: present, at: time.Now()}
+		p.mu.Unlock()
+		return present
+	}
+}
+
+func isOwner(chCfg config.ChannelConfig, userID string) bool {
+	for _, owner := range role.Owners(chCfg.Roles) {
+		if owner == userID {
+			return true
+		}
+	}
+	return false
+}
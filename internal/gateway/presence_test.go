@@ -0,0 +1,90 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tgifai/friday/internal/channel"
+	"github.com/tgifai/friday/internal/config"
+)
+
+// fakeChannel records the messages sent through it and fails them with
+// sendErr.
+type fakeChannel struct {
+	id      string
+	sendErr error
+	sent    []string
+}
+
+func (c *fakeChannel) ID() string                  { return c.id }
+func (c *fakeChannel) Type() channel.Type          { return channel.Type("fake") }
+func (c *fakeChannel) Start(context.Context) error { return nil }
+func (c *fakeChannel) Stop(context.Context) error  { return nil }
+func (c *fakeChannel) Routes() []channel.Route     { return nil }
+func (c *fakeChannel) SendChatAction(context.Context, string, channel.ChatAction) error {
+	return nil
+}
+func (c *fakeChannel) ReactMessage(context.Context, string, string, string) error { return nil }
+func (c *fakeChannel) RegisterMessageHandler(func(context.Context, *channel.Message) error) error {
+	return nil
+}
+func (c *fakeChannel) WorkInProgress(context.Context, string, string) (func(), error) {
+	return func() {}, nil
+}
+func (c *fakeChannel) SendMessage(_ context.Context, chatID, content string, _ ...channel.SendOption) error {
+	if c.sendErr != nil {
+		return c.sendErr
+	}
+	c.sent = append(c.sent, chatID+": "+content)
+	return nil
+}
+
+// memberChannel answers membership lookups with err.
+type memberChannel struct {
+	fakeChannel
+	err error
+}
+
+func (c *memberChannel) IsChatMember(context.Context, string, string) (bool, error) {
+	return true, c.err
+}
+
+func registerFake(t *testing.T, ch channel.Channel) {
+	t.Helper()
+	_ = channel.Register(ch)
+	t.Cleanup(func() { channel.Unregister(ch.ID()) })
+}
+
+func TestOwnerPresence_SeenExpires(t *testing.T) {
+	registerFake(t, &fakeChannel{id: "presence-seen"})
+	chCfg := config.ChannelConfig{Roles: config.ChannelRolesConfig{Users: map[string]string{"42": "owner"}}}
+	p := newOwnerPresence()
+	msg := &channel.Message{ChannelID: "presence-seen", ChatID: "-100", UserID: "42"}
+
+	if p.lookup(context.Background(), msg, chCfg)() {
+		t.Fatal("owner present before writing in the chat")
+	}
+	p.observe(msg, chCfg, "group:-100")
+	if !p.lookup(context.Background(), msg, chCfg)() {
+		t.Fatal("owner not present right after writing in the chat")
+	}
+
+	p.seen["presence-seen:-100"] = time.Now().Add(-ownerPresenceTTL)
+	if p.lookup(context.Background(), msg, chCfg)() {
+		t.Error("owner still present after the presence TTL")
+	}
+}
+
+func TestOwnerPresence_LookupErrorDenies(t *testing.T) {
+	registerFake(t, &memberChannel{fakeChannel: fakeChannel{id: "presence-err"}, err: errors.New("api down")})
+	chCfg := config.ChannelConfig{Roles: config.ChannelRolesConfig{Users: map[string]string{"42": "owner"}}}
+	p := newOwnerPresence()
+	msg := &channel.Message{ChannelID: "presence-err", ChatID: "-100", UserID: "42"}
+
+	p.observe(msg, chCfg, "group:-100")
+	if p.lookup(context.Background(), msg, chCfg)() {
+		t.Error("failed membership lookup counted the owner as present")
+	}
+}
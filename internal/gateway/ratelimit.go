@@ -0,0 +1,160 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/tgifai/friday/internal/channel"
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/pkg/logs"
+	fridayProm "github.com/tgifai/friday/internal/pkg/prometheus"
+	"github.com/tgifai/friday/internal/security/ratelimit"
+)
+
+const (
+	rateLimitStatePath     = "security/ratelimit.json"
+	rateLimitSaveInterval  = 30 * time.Second
+	rateLimitIdleBucketTTL = 24 * time.Hour
+
+	// rateLimitNoticeInterval spaces out "slow down" replies per bucket so
+	// the reply itself cannot be used to flood the chat.
+	rateLimitNoticeInterval = time.Minute
+
+	defaultRateLimitReply = "You're sending messages too quickly. Please wait {wait} and try again."
+
+	rateKindTurn    = "turn"
+	rateKindCommand = "command"
+)
+
+var (
+	rateLimitAllowed = promauto.With(fridayProm.GetRegistry()).NewCounterVec(prometheus.CounterOpts{
+		Name: "friday_rate_limit_allowed_total",
+		Help: "Messages that passed the rate limiter.",
+	}, []string{"channel", "kind"})
+	rateLimitLimited = promauto.With(fridayProm.GetRegistry()).NewCounterVec(prometheus.CounterOpts{
+		Name: "friday_rate_limit_limited_total",
+		Help: "Messages dropped by the rate limiter, by the scope whose bucket ran dry.",
+	}, []string{"channel", "kind", "scope"})
+)
+
+// CheckRate applies the channel's rate limits to msg. Commands and agent
+// turns draw from separate buckets. It returns (allowed, reply); reply is a
+// throttled "slow down" notice and may be empty even when denied.
+func (g *SecurityGuard) CheckRate(ctx context.Context, msg *channel.Message, chCfg config.ChannelConfig, isCommand bool) (bool, string) {
+	if g.limiter == nil {
+		return true, ""
+	}
+
+	kind, scopes := rateKindTurn, chCfg.Security.RateLimit.Turns
+	if isCommand {
+		kind, scopes = rateKindCommand, chCfg.Security.RateLimit.Commands
+	}
+
+	prefix := kind + ":" + msg.ChannelID
+	checks := []ratelimit.Check{
+		{Key: prefix + ":channel", Rule: toRule(scopes.Channel)},
+		{Key: prefix + ":chat:" + msg.ChatID, Rule: toRule(scopes.Chat)},
+	}
+	if msg.UserID != "" {
+		checks = append(checks, ratelimit.Check{Key: prefix + ":user:" + msg.UserID, Rule: toRule(scopes.User)})
+	}
+
+	ok, limitedKey, wait := g.limiter.Allow(checks, time.Now())
+	if ok {
+		rateLimitAllowed.WithLabelValues(msg.ChannelID, kind).Inc()
+		return true, ""
+	}
+
+	scope := rateLimitScope(prefix, limitedKey)
+	rateLimitLimited.WithLabelValues(msg.ChannelID, kind, scope).Inc()
+	logs.CtxInfo(ctx, "[security] rate_limited channel_id=%s user_id=%s chat_id=%s kind=%s scope=%s retry_after=%s",
+		msg.ChannelID, msg.UserID, msg.ChatID, kind, scope, wait.Round(time.Second))
+
+	if !g.shouldNotify(limitedKey) {
+		return false, ""
+	}
+	reply := chCfg.Security.RateLimit.Reply
+	if reply == "" {
+		reply = defaultRateLimitReply
+	}
+	return false, strings.ReplaceAll(reply, "{wait}", formatWait(wait))
+}
+
+// shouldNotify reports whether a notice for key is due and records it.
+func (g *SecurityGuard) shouldNotify(key string) bool {
+	g.noticeMu.Lock()
+	defer g.noticeMu.Unlock()
+
+	now := time.Now()
+	if last, ok := g.notices[key]; ok && now.Sub(last) < rateLimitNoticeInterval {
+		return false
+	}
+	for k, t := range g.notices {
+		if now.Sub(t) >= rateLimitNoticeInterval {
+			delete(g.notices, k)
+		}
+	}
+	g.notices[key] = now
+	return true
+}
+
+func toRule(r config.RateLimitRule) ratelimit.Rule {
+	return ratelimit.Rule{PerMinute: r.PerMinute, Burst: r.Burst}
+}
+
+// rateLimitScope extracts "user", "chat" or "channel" from a bucket key.
+func rateLimitScope(prefix, key string) string {
+	scope, _, _ := strings.Cut(strings.TrimPrefix(key, prefix+":"), ":")
+	return scope
+}
+
+func formatWait(d time.Duration) string {
+	if d < time.Minute {
+		secs := int(math.Ceil(d.Seconds()))
+		if secs <= 1 {
+			return "a second"
+		}
+		return fmt.Sprintf("%d seconds", secs)
+	}
+	mins := int(math.Ceil(d.Minutes()))
+	if mins == 1 {
+		return "a minute"
+	}
+	return fmt.Sprintf("%d minutes", mins)
+}
+
+// initRateLimiter restores persisted buckets and keeps them saved while the
+// gateway runs.
+func (g *SecurityGuard) initRateLimiter(ctx context.Context) {
+	if g.limiter == nil {
+		return
+	}
+	if err := g.limiter.Load(); err != nil {
+		logs.CtxWarn(ctx, "[security] load rate limit state: %v", err)
+	}
+	go g.limiter.Run(ctx, rateLimitSaveInterval, rateLimitIdleBucketTTL)
+}
+
+// saveRateLimiter persists bucket state on shutdown.
+func (g *SecurityGuard) saveRateLimiter(ctx context.Context) {
+	if g.limiter == nil {
+		return
+	}
+	if err := g.limiter.Save(); err != nil {
+		logs.CtxWarn(ctx, "[security] save rate limit state: %v", err)
+	}
+}
+
+func newSecurityGuard(limiter *ratelimit.Limiter) *SecurityGuard {
+	return &SecurityGuard{
+		limiter:  limiter,
+		presence: newOwnerPresence(),
+		notices:  make(map[string]time.Time),
+	}
+}
@@ -0,0 +1,112 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/security/redact"
+	"github.com/tgifai/friday/internal/security/secrets"
+)
+
+// credentialKeys are provider and channel config key fragments whose
+// values are credentials.
+var credentialKeys = []string{"api_key", "apikey", "token", "secret", "password", "encrypt_key", "access_key"}
+
+// initRedactor installs the redactor that scrubs chat messages, logs and
+// sessions, seeded with the credentials the gateway knows about.
+func initRedactor(ctx context.Context, cfg *config.Config) error {
+	if cfg.Redact.Disabled {
+		redact.SetDefault(nil)
+		logs.SetScrubber(nil)
+		logs.CtxWarn(ctx, "[gateway] secret redaction is disabled")
+		return nil
+	}
+
+	opts := redact.Options{
+		Values: knownSecrets(ctx, cfg),
+		Skip:   cfg.Redact.Skip,
+	}
+	for _, p := range cfg.Redact.Patterns {
+		opts.Patterns = append(opts.Patterns, redact.Pattern{Name: p.Name, Pattern: p.Pattern})
+	}
+	r, err := redact.New(opts)
+	if err != nil {
+		return fmt.Errorf("redact: %w", err)
+	}
+	redact.SetDefault(r)
+	logs.SetScrubber(func(s string) string { return redact.Default().Scrub(s) })
+	logs.CtxInfo(ctx, "[gateway] secret redaction enabled: %d known value(s), %d custom pattern(s)", len(opts.Values), len(opts.Patterns))
+	return nil
+}
+
+// knownSecrets collects credentials from provider, channel, voice and
+// sandbox config and every value in the secrets store, keyed by a name used in
+// placeholders. Unresolvable placeholders are skipped here; the component
+// using them reports the error.
+func knownSecrets(ctx context.Context, cfg *config.Config) map[string]string {
+	values := make(map[string]string)
+	for id, p := range cfg.Providers {
+		if expanded, err := secrets.ExpandMap(p.Config); err == nil {
+			collectCredentials(values, id, expanded)
+		}
+	}
+	for id, ch := range cfg.Channels {
+		if expanded, err := secrets.ExpandMap(ch.Config); err == nil {
+			collectCredentials(values, id, expanded)
+		}
+	}
+	for id, ag := range cfg.Agents {
+		for name, key := range map[string]string{
+			"stt":      ag.Voice.STT.APIKey,
+			"tts":      ag.Voice.TTS.APIKey,
+			"go_judge": ag.Security.Sandbox.GoJudge.AuthToken,
+		} {
+			if key, err := secrets.Expand(key); err == nil && key != "" {
+				values[id+"."+name] = key
+			}
+		}
+	}
+
+	store := secrets.Default()
+	infos, err := store.List()
+	if err != nil {
+		logs.CtxWarn(ctx, "[gateway] redact: list secrets: %v", err)
+	}
+	for _, info := range infos {
+		value, err := store.Get(info.Name)
+		if err != nil {
+			logs.CtxWarn(ctx, "[gateway] redact: read secret %s: %v", info.Name, err)
+			break
+		}
+		values["secret:"+info.Name] = value
+	}
+	return values
+}
+
+// collectCredentials adds the string values under credential keys of m,
+// at any depth, to values.
+func collectCredentials(values map[string]string, name string, m map[string]any) {
+	for k, v := range m {
+		switch val := v.(type) {
+		case map[string]any:
+			collectCredentials(values, name, val)
+		case string:
+			if val != "" && isCredentialKey(k) {
+				values[name+"."+k] = val
+			}
+		}
+	}
+}
+
+func isCredentialKey(key string) bool {
+	key = strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	for _, one := range credentialKeys {
+		if strings.Contains(key, one) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,59 +4,79 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/tgifai/friday/internal/channel"
 	"github.com/tgifai/friday/internal/config"
 	friConsts "github.com/tgifai/friday/internal/consts"
 	"github.com/tgifai/friday/internal/pkg/logs"
+	"github.com/tgifai/friday/internal/pkg/utils"
+	"github.com/tgifai/friday/internal/security/acl"
 	"github.com/tgifai/friday/internal/security/pairing"
+	"github.com/tgifai/friday/internal/security/ratelimit"
 )
 
 const pairCommandPrefix = "/pair"
 
-// SecurityGuard performs channel-agnostic security checks (ACL + pairing).
-// It is called by the gateway before command routing or agent dispatch.
-type SecurityGuard struct{}
+// SecurityGuard performs channel-agnostic security checks (ACL + pairing,
+// rate limits). It is called by the gateway before command routing or agent
+// dispatch.
+type SecurityGuard struct {
+	limiter  *ratelimit.Limiter
+	presence *ownerPresence
+
+	noticeMu sync.Mutex
+	notices  map[string]time.Time // last "slow down" reply per limited bucket
+}
 
 // Check evaluates whether a message should be allowed through.
 // It returns (allowed, reply). If reply is non-empty the gateway should send
 // it back to the user regardless of whether allowed is true.
 func (g *SecurityGuard) Check(ctx context.Context, msg *channel.Message, chCfg config.ChannelConfig) (bool, string) {
 	pairingEnabled := chCfg.Security.Policy != "" && chCfg.Security.Policy != friConsts.SecurityPolicySilent
+	g.presence.observe(msg, chCfg, g.buildChatKey(msg))
 
 	if pairingEnabled {
 		return g.checkPairing(ctx, msg, chCfg)
 	}
-	return g.checkACL(msg, chCfg)
+	return g.checkACL(ctx, msg, chCfg)
 }
 
-// checkACL applies simple allow/block list rules. When no ACL is configured
+// checkACL applies the channel's ACL rules. When no rule covers the chat
 // the message is allowed through.
-func (g *SecurityGuard) checkACL(msg *channel.Message, chCfg config.ChannelConfig) (bool, string) {
+func (g *SecurityGuard) checkACL(ctx context.Context, msg *channel.Message, chCfg config.ChannelConfig) (bool, string) {
 	if len(chCfg.ACL) == 0 {
 		return true, ""
 	}
 
-	chatKey := g.buildChatKey(msg)
-	entry, exists := chCfg.ACL[chatKey]
-	if !exists {
+	req := acl.Request{
+		ChatKey:      g.buildChatKey(msg),
+		UserID:       msg.UserID,
+		Now:          time.Now(),
+		OwnerPresent: g.presence.lookup(ctx, msg, chCfg),
+	}
+	decision := acl.Evaluate(chCfg.ACL, req)
+	if !decision.Matched {
 		// No rule for this chat — check user-level key.
 		userKey := "user:" + msg.ChatID
-		entry, exists = chCfg.ACL[userKey]
-		if !exists {
+		if _, ok := chCfg.ACL[userKey]; !ok {
 			return true, "" // no rules → allow
 		}
+		req.ChatKey = userKey
+		decision = acl.Evaluate(chCfg.ACL, req)
 	}
-
-	if contains(entry.Block, msg.UserID) {
-		return false, ""
-	}
-	if len(entry.Allow) == 0 {
+	if decision.Allowed {
 		return true, ""
 	}
-	if contains(entry.Allow, msg.UserID) {
-		return true, ""
+
+	logs.CtxInfo(ctx, "[security] acl_denied channel_id=%s user_id=%s chat_key=%s rule=%s reason=%s",
+		msg.ChannelID, msg.UserID, req.ChatKey, decision.Rule, decision.Reason)
+	switch {
+	case decision.Reply != "":
+		return false, decision.Reply
+	case decision.Reason == acl.ReasonBlocked:
+		return false, ""
 	}
 	return false, "Sorry, you are not authorized to use this bot."
 }
@@ -71,14 +91,20 @@ func (g *SecurityGuard) checkPairing(ctx context.Context, msg *channel.Message,
 	}
 
 	// Already allowed?
-	allowed, err := mgr.IsAllowed(chatKey, msg.UserID)
+	access, err := mgr.Check(chatKey, msg.UserID, g.presence.lookup(ctx, msg, chCfg))
 	if err != nil {
 		logs.CtxError(ctx, "[security] pairing allowlist check failed: %v", err)
 		return false, ""
 	}
-	if allowed {
+	if access.Allowed {
 		return true, ""
 	}
+	// Blocked users are ignored rather than offered pairing.
+	if access.Reason == acl.ReasonBlocked {
+		logs.CtxInfo(ctx, "[security] acl_denied channel_id=%s user_id=%s chat_key=%s rule=%s reason=%s",
+			msg.ChannelID, msg.UserID, chatKey, access.Rule, access.Reason)
+		return false, access.Reply
+	}
 
 	// Is this a /pair command?
 	if code, ok := parsePairCommand(msg.Content); ok {
@@ -86,12 +112,25 @@ func (g *SecurityGuard) checkPairing(ctx context.Context, msg *channel.Message,
 	}
 
 	// Unknown user — issue challenge.
+	approvalChat := chCfg.Security.ApprovalChat
+	welcome := ""
+	if approvalChat != "" {
+		welcome = pairing.ApprovalWelcomeTemplate
+	}
 	principal := g.buildPrincipal(msg, chatKey)
-	decision, err := mgr.EvaluateUnknownUser(principal, msg.ChatID, msg.UserID, "")
+	decision, err := mgr.EvaluateUnknownUser(principal, msg.ChatID, msg.UserID, welcome)
 	if err != nil {
 		logs.CtxError(ctx, "[security] pairing evaluate failed: %v", err)
 		return false, ""
 	}
+	// The welcome tells the user their request went to the owner, so it is
+	// only sent when one was forwarded; denied users, those with a request
+	// already pending and those whose request could not be forwarded hear
+	// nothing.
+	if approvalChat != "" && (decision.Challenge.ReqID == "" ||
+		!g.requestApproval(ctx, mgr, msg, approvalChat, chatKey, principal, decision.Challenge.ReqID)) {
+		decision.Respond = false
+	}
 
 	logs.CtxInfo(ctx,
 		"[security] pairing_user_reached channel_id=%s user_id=%s chat_id=%s chat_key=%s req_id=%s code=%s expire=%s",
@@ -122,7 +161,7 @@ func (g *SecurityGuard) handlePairCommand(
 		return false, "Invalid or expired pairing code."
 	}
 
-	changed, grantErr := mgr.GrantACL(chatKey, msg.UserID)
+	changed, grantErr := mgr.GrantACL(chatKey, msg.UserID, config.ACLGrant{Via: friConsts.GrantViaPairingCode})
 	if grantErr != nil {
 		logs.CtxError(ctx, "[security] grant acl failed: %v", grantErr)
 		return false, "Pairing failed due to an internal error."
@@ -139,6 +178,61 @@ func (g *SecurityGuard) handlePairCommand(
 	return false, "Pairing successful. You can now use this bot."
 }
 
+// requestApproval forwards a pairing request to the owner's approval chat,
+// once per requester until the owner decides. It reports whether the owner
+// was sent a new request; one that cannot be forwarded is withdrawn so the
+// requester's next message tries again.
+func (g *SecurityGuard) requestApproval(
+	ctx context.Context,
+	mgr *pairing.Manager,
+	msg *channel.Message,
+	approvalChat, chatKey, principal, reqID string,
+) bool {
+	name := strings.TrimSpace(msg.Metadata["first_name"] + " " + msg.Metadata["last_name"])
+	if username := msg.Metadata["username"]; username != "" {
+		name = strings.TrimSpace(name + " @" + username)
+	}
+	req := pairing.Request{
+		ReqID:     reqID,
+		Principal: principal,
+		ChatKey:   chatKey,
+		ChatID:    msg.ChatID,
+		ThreadID:  msg.ThreadID,
+		UserID:    msg.UserID,
+		UserName:  name,
+		Preview:   utils.Truncate80(msg.Content),
+	}
+	submitted, err := mgr.SubmitRequest(req)
+	if err != nil {
+		logs.CtxError(ctx, "[security] pairing request submit failed: %v", err)
+		return false
+	}
+	if !submitted {
+		return false
+	}
+
+	ch, err := channel.Get(msg.ChannelID)
+	if err != nil {
+		logs.CtxWarn(ctx, "[security] pairing approval channel %s not found: %v", msg.ChannelID, err)
+		g.withdrawRequest(ctx, mgr, reqID)
+		return false
+	}
+	if err := ch.SendMessage(ctx, approvalChat, pairing.FormatRequest(req)); err != nil {
+		logs.CtxWarn(ctx, "[security] forward pairing request %s to %s failed: %v", reqID, approvalChat, err)
+		g.withdrawRequest(ctx, mgr, reqID)
+		return false
+	}
+	logs.CtxInfo(ctx, "[security] pairing_request_forwarded channel_id=%s user_id=%s chat_key=%s req_id=%s approval_chat=%s",
+		msg.ChannelID, msg.UserID, chatKey, reqID, approvalChat)
+	return true
+}
+
+func (g *SecurityGuard) withdrawRequest(ctx context.Context, mgr *pairing.Manager, reqID string) {
+	if err := mgr.WithdrawRequest(reqID); err != nil {
+		logs.CtxError(ctx, "[security] withdraw pairing request %s failed: %v", reqID, err)
+	}
+}
+
 func (g *SecurityGuard) buildChatKey(msg *channel.Message) string {
 	chatType, _ := msg.Metadata["chat_type"]
 	if strings.EqualFold(chatType, "private") || chatType == "" {
@@ -169,13 +263,3 @@ func parsePairCommand(content string) (string, bool) {
 	}
 	return code, true
 }
-
-func contains(list []string, item string) bool {
-	for _, v := range list {
-		if v == item {
-			return true
-		}
-	}
-	return false
-}
-
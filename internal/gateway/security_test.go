@@ -0,0 +1,40 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tgifai/friday/internal/channel"
+	"github.com/tgifai/friday/internal/security/pairing"
+)
+
+func TestRequestApproval_ForwardFailure(t *testing.T) {
+	t.Setenv("FRIDAY_HOME", t.TempDir())
+	ch := &fakeChannel{id: "approval", sendErr: errors.New("chat not found")}
+	registerFake(t, ch)
+	key := pairing.GetKey("fake", ch.id)
+	mgr := pairing.Get(key)
+	t.Cleanup(func() { pairing.Delete(key) })
+
+	g := &SecurityGuard{}
+	msg := &channel.Message{ChannelID: ch.id, ChatID: "7", UserID: "7", Content: "hi"}
+	principal := g.buildPrincipal(msg, "user:7")
+
+	if g.requestApproval(context.Background(), mgr, msg, "owner", "user:7", principal, "req-1") {
+		t.Fatal("request reported as forwarded although sending it failed")
+	}
+	if pending, _ := mgr.PendingRequests(); len(pending) != 0 {
+		t.Fatalf("undelivered request kept pending: %+v", pending)
+	}
+
+	// Once the approval chat is reachable, the next message gets through.
+	ch.sendErr = nil
+	if !g.requestApproval(context.Background(), mgr, msg, "owner", "user:7", principal, "req-2") {
+		t.Fatal("request not forwarded after a failed attempt")
+	}
+	if len(ch.sent) != 1 || !strings.HasPrefix(ch.sent[0], "owner: ") {
+		t.Errorf("sent %q, want one request to the owner chat", ch.sent)
+	}
+}
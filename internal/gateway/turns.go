@@ -0,0 +1,110 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tgifai/friday/internal/channel"
+)
+
+// editRerunWindow is how long after a turn was answered an edit of its
+// message still re-runs it.
+const editRerunWindow = 2 * time.Minute
+
+// turn is the latest inbound message of a session.
+type turn struct {
+	msg        *channel.Message
+	cancel     context.CancelFunc // set while the agent is running
+	finishedAt time.Time
+}
+
+// turnTracker remembers the latest turn per session so that an edit or
+// recall of its message can cancel and replace it.
+type turnTracker struct {
+	mu    sync.Mutex
+	turns map[string]*turn // session key -> latest turn
+	// superseded holds queued messages that were edited before they ran.
+	superseded map[*channel.Message]struct{}
+}
+
+func newTurnTracker() *turnTracker {
+	return &turnTracker{
+		turns:      make(map[string]*turn),
+		superseded: make(map[*channel.Message]struct{}),
+	}
+}
+
+// track records msg as the latest message of its session. Turns answered
+// longer than editRerunWindow ago can no longer be re-run and are dropped.
+func (t *turnTracker) track(msg *channel.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	for key, cur := range t.turns {
+		if !cur.finishedAt.IsZero() && now.Sub(cur.finishedAt) > editRerunWindow {
+			delete(t.turns, key)
+		}
+	}
+	t.turns[msg.SessionKey] = &turn{msg: msg}
+}
+
+// begin attaches cancel to the turn of msg. It returns false when msg was
+// superseded while waiting in its lane and must not run.
+func (t *turnTracker) begin(msg *channel.Message, cancel context.CancelFunc) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.superseded[msg]; ok {
+		delete(t.superseded, msg)
+		return false
+	}
+	if cur := t.turns[msg.SessionKey]; cur != nil && cur.msg == msg {
+		cur.cancel = cancel
+	}
+	return true
+}
+
+// finish marks the turn of msg as answered.
+func (t *turnTracker) finish(msg *channel.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cur := t.turns[msg.SessionKey]; cur != nil && cur.msg == msg {
+		cur.cancel = nil
+		cur.finishedAt = time.Now()
+	}
+}
+
+// supersede handles an edit or recall of an earlier message. When that
+// message is the latest of its session, a queued turn is skipped and a
+// running one is cancelled. For edits it reports whether the turn should be
+// re-run with the new text: only while it is queued or running, or within
+// editRerunWindow after it was answered. The edit then becomes the session's
+// latest turn.
+func (t *turnTracker) supersede(update *channel.Message) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cur := t.turns[update.SessionKey]
+	if cur == nil || cur.msg.ID != update.ID {
+		return false
+	}
+
+	finished := !cur.finishedAt.IsZero()
+	if finished && (update.Event != channel.EventEdited || time.Since(cur.finishedAt) > editRerunWindow) {
+		return false
+	}
+	if !finished {
+		if cur.cancel != nil {
+			cur.cancel()
+		} else {
+			t.superseded[cur.msg] = struct{}{}
+		}
+	}
+
+	if update.Event != channel.EventEdited {
+		delete(t.turns, update.SessionKey)
+		return false
+	}
+	t.turns[update.SessionKey] = &turn{msg: update}
+	return true
+}
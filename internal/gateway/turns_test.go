@@ -0,0 +1,85 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tgifai/friday/internal/channel"
+)
+
+func TestTurnTracker_EditCancelsRunningTurn(t *testing.T) {
+	tt := newTurnTracker()
+	orig := &channel.Message{ID: "1", SessionKey: "s"}
+	tt.track(orig)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if !tt.begin(orig, cancel) {
+		t.Fatal("begin = false for a fresh turn")
+	}
+
+	edit := &channel.Message{ID: "1", SessionKey: "s", Event: channel.EventEdited}
+	if !tt.supersede(edit) {
+		t.Fatal("supersede = false for the running turn")
+	}
+	if ctx.Err() == nil {
+		t.Error("running turn was not cancelled")
+	}
+	if !tt.begin(edit, func() {}) {
+		t.Error("re-run was skipped")
+	}
+}
+
+func TestTurnTracker_EditSkipsQueuedTurn(t *testing.T) {
+	tt := newTurnTracker()
+	orig := &channel.Message{ID: "1", SessionKey: "s"}
+	tt.track(orig)
+
+	if !tt.supersede(&channel.Message{ID: "1", SessionKey: "s", Event: channel.EventEdited}) {
+		t.Fatal("supersede = false for the queued turn")
+	}
+	if tt.begin(orig, func() {}) {
+		t.Error("superseded turn was not skipped")
+	}
+}
+
+func TestTurnTracker_EditIgnoredWhenNotLatestOrStale(t *testing.T) {
+	tt := newTurnTracker()
+	first := &channel.Message{ID: "1", SessionKey: "s"}
+	second := &channel.Message{ID: "2", SessionKey: "s"}
+	tt.track(first)
+	tt.track(second)
+
+	if tt.supersede(&channel.Message{ID: "1", SessionKey: "s", Event: channel.EventEdited}) {
+		t.Error("edit of an older message re-ran the turn")
+	}
+
+	tt.begin(second, func() {})
+	tt.finish(second)
+	tt.turns["s"].finishedAt = time.Now().Add(-2 * editRerunWindow)
+	if tt.supersede(&channel.Message{ID: "2", SessionKey: "s", Event: channel.EventEdited}) {
+		t.Error("edit long after the answer re-ran the turn")
+	}
+}
+
+func TestTurnTracker_PrunesAnsweredTurns(t *testing.T) {
+	tt := newTurnTracker()
+	old := &channel.Message{ID: "1", SessionKey: "a"}
+	tt.track(old)
+	tt.begin(old, func() {})
+	tt.finish(old)
+	tt.turns["a"].finishedAt = time.Now().Add(-2 * editRerunWindow)
+
+	tt.track(&channel.Message{ID: "2", SessionKey: "b"})
+	if _, ok := tt.turns["a"]; ok || len(tt.turns) != 1 {
+		t.Errorf("turn answered long ago kept: %v", tt.turns)
+	}
+
+	// A superseded turn that is skipped leaves nothing behind.
+	queued := &channel.Message{ID: "3", SessionKey: "b"}
+	tt.track(queued)
+	tt.supersede(&channel.Message{ID: "3", SessionKey: "b", Event: channel.EventDeleted})
+	if tt.begin(queued, func() {}) || len(tt.superseded) != 0 {
+		t.Errorf("recalled turn ran or stayed marked: %v", tt.superseded)
+	}
+}
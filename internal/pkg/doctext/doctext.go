@@ -0,0 +1,142 @@
+package doctext
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnsupported is returned when the document format has no text extractor.
+var ErrUnsupported = errors.New("unsupported document format")
+
+// Kind classifies a document by how its text can be obtained.
+type Kind string
+
+const (
+	KindText    Kind = "text"
+	KindPDF     Kind = "pdf"
+	KindDOCX    Kind = "docx"
+	KindUnknown Kind = "unknown"
+)
+
+const (
+	mimePDF  = "application/pdf"
+	mimeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+	// sniffLen is how many leading bytes are inspected when guessing whether
+	// an untyped payload is plain text.
+	sniffLen = 8 * 1024
+)
+
+// textExtensions lists file extensions that are treated as plain text
+// regardless of the MIME type reported by the channel.
+var textExtensions = map[string]bool{
+	".txt": true, ".md": true, ".markdown": true, ".rst": true, ".log": true,
+	".csv": true, ".tsv": true, ".json": true, ".jsonl": true, ".xml": true,
+	".yaml": true, ".yml": true, ".toml": true, ".ini": true, ".conf": true,
+	".cfg": true, ".env": true, ".properties": true, ".sql": true, ".html": true,
+	".htm": true, ".css": true, ".scss": true, ".go": true, ".mod": true,
+	".py": true, ".rb": true, ".php": true, ".java": true, ".kt": true,
+	".scala": true, ".swift": true, ".m": true, ".c": true, ".h": true,
+	".cc": true, ".cpp": true, ".hpp": true, ".cs": true, ".rs": true,
+	".js": true, ".mjs": true, ".cjs": true, ".ts": true, ".tsx": true,
+	".jsx": true, ".vue": true, ".svelte": true, ".lua": true, ".pl": true,
+	".r": true, ".dart": true, ".sh": true, ".bash": true, ".zsh": true,
+	".fish": true, ".ps1": true, ".bat": true, ".proto": true, ".graphql": true,
+	".tf": true, ".hcl": true, ".dockerfile": true, ".makefile": true, ".diff": true,
+	".patch": true, ".tex": true, ".srt": true, ".vtt": true,
+}
+
+// textMIMETypes lists non text/* MIME types whose payload is plain text.
+var textMIMETypes = map[string]bool{
+	"application/json":       true,
+	"application/xml":        true,
+	"application/yaml":       true,
+	"application/x-yaml":     true,
+	"application/toml":       true,
+	"application/javascript": true,
+	"application/typescript": true,
+	"application/x-sh":       true,
+	"application/sql":        true,
+	"application/x-ndjson":   true,
+}
+
+// Detect classifies a document using its MIME type, file name and, as a last
+// resort, its content. Channels often report application/octet-stream for
+// every upload, so the extension and a content sniff are consulted as well.
+func Detect(data []byte, mimeType, fileName string) Kind {
+	mt := normalizeMIME(mimeType)
+	ext := strings.ToLower(filepath.Ext(fileName))
+
+	switch {
+	case mt == mimePDF || ext == ".pdf" || bytes.HasPrefix(data, []byte("%PDF-")):
+		return KindPDF
+	case mt == mimeDOCX || ext == ".docx":
+		return KindDOCX
+	case strings.HasPrefix(mt, "text/") || textMIMETypes[mt] || textExtensions[ext]:
+		return KindText
+	case looksLikeText(data):
+		return KindText
+	}
+	return KindUnknown
+}
+
+// Extract returns the plain text content of a document. Text files are
+// returned as-is, PDF and DOCX files are converted. ErrUnsupported is
+// returned for binary formats without an extractor.
+func Extract(data []byte, mimeType, fileName string) (string, error) {
+	switch Detect(data, mimeType, fileName) {
+	case KindText:
+		return strings.ToValidUTF8(string(data), "�"), nil
+	case KindPDF:
+		return extractPDF(data)
+	case KindDOCX:
+		return extractDOCX(data)
+	default:
+		return "", ErrUnsupported
+	}
+}
+
+// MIMEType returns the best known MIME type for a file, preferring the one
+// reported by the channel unless it is the generic octet-stream.
+func MIMEType(mimeType, fileName string) string {
+	mt := normalizeMIME(mimeType)
+	if mt != "" && mt != "application/octet-stream" {
+		return mt
+	}
+	if byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(fileName))); byExt != "" {
+		return normalizeMIME(byExt)
+	}
+	if mt == "" {
+		return "application/octet-stream"
+	}
+	return mt
+}
+
+func normalizeMIME(mimeType string) string {
+	mt, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(mimeType))
+	}
+	return mt
+}
+
+// looksLikeText reports whether the leading bytes are valid UTF-8 without
+// NUL bytes, which rules out nearly all binary formats.
+func looksLikeText(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+	head := data
+	if len(head) > sniffLen {
+		head = head[:sniffLen]
+		// Do not fail the check because the cut split a multi-byte rune.
+		for i := 0; i < utf8.UTFMax && len(head) > 0 && !utf8.Valid(head); i++ {
+			head = head[:len(head)-1]
+		}
+	}
+	return utf8.Valid(head) && bytes.IndexByte(head, 0) < 0
+}
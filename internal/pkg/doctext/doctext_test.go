@@ -0,0 +1,112 @@
+package doctext
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     []byte
+		mime     string
+		fileName string
+		want     Kind
+	}{
+		{"pdf by mime", nil, "application/pdf", "", KindPDF},
+		{"pdf by magic", []byte("%PDF-1.4\n"), "application/octet-stream", "upload", KindPDF},
+		{"docx by ext", nil, "application/octet-stream", "report.DOCX", KindDOCX},
+		{"text by mime", nil, "text/plain; charset=utf-8", "", KindText},
+		{"code by ext", []byte{0xff}, "application/octet-stream", "main.go", KindText},
+		{"json by mime", nil, "application/json", "", KindText},
+		{"sniffed text", []byte("hello\nworld"), "application/octet-stream", "", KindText},
+		{"binary", []byte{0x00, 0x01, 0x02}, "application/octet-stream", "blob.bin", KindUnknown},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Detect(tc.data, tc.mime, tc.fileName); got != tc.want {
+				t.Errorf("Detect() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExtract_Text(t *testing.T) {
+	got, err := Extract([]byte("package main\n"), "", "main.go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "package main\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestExtract_Unsupported(t *testing.T) {
+	_, err := Extract([]byte{0x00, 0x01}, "application/zip", "a.zip")
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestExtract_DOCX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte(`<?xml version="1.0"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Hello</w:t></w:r><w:r><w:tab/><w:t>World</w:t></w:r></w:p>
+<w:p><w:r><w:t>Second</w:t><w:br/><w:t>line</w:t></w:r></w:p>
+</w:body></w:document>`))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Extract(buf.Bytes(), "", "doc.docx")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Hello\tWorld\nSecond\nline"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestExtractPDFStreams(t *testing.T) {
+	content := []byte("BT /F1 12 Tf 72 712 Td (Hello \\(PDF\\)) Tj T* [(Wor) -20 (ld)] TJ ET")
+
+	var zbuf bytes.Buffer
+	zw := zlib.NewWriter(&zbuf)
+	_, _ = zw.Write(content)
+	_ = zw.Close()
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\n")
+	fmt.Fprintf(&pdf, "2 0 obj << /Length %d /Filter /FlateDecode >> stream\n", zbuf.Len())
+	pdf.Write(zbuf.Bytes())
+	pdf.WriteString("\nendstream endobj\n%%EOF\n")
+
+	got := extractPDFStreams(pdf.Bytes())
+	if !strings.Contains(got, "Hello (PDF)") || !strings.Contains(got, "World") {
+		t.Errorf("unexpected text: %q", got)
+	}
+}
+
+func TestMIMEType(t *testing.T) {
+	if got := MIMEType("application/octet-stream", "a.pdf"); got != "application/pdf" {
+		t.Errorf("got %s", got)
+	}
+	if got := MIMEType("text/csv", "a.bin"); got != "text/csv" {
+		t.Errorf("got %s", got)
+	}
+	if got := MIMEType("", "noext"); got != "application/octet-stream" {
+		t.Errorf("got %s", got)
+	}
+}
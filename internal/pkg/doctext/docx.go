@@ -0,0 +1,78 @@
+package doctext
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxDOCXXMLSize bounds the decompressed size of word/document.xml to guard
+// against zip bombs.
+const maxDOCXXMLSize = 32 * 1024 * 1024
+
+// extractDOCX reads the main document part of an Office Open XML file and
+// flattens paragraphs, tabs and line breaks into plain text.
+func extractDOCX(data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("open docx: %w", err)
+	}
+
+	var doc *zip.File
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			doc = f
+			break
+		}
+	}
+	if doc == nil {
+		return "", fmt.Errorf("open docx: word/document.xml not found")
+	}
+
+	rc, err := doc.Open()
+	if err != nil {
+		return "", fmt.Errorf("open docx body: %w", err)
+	}
+	defer rc.Close()
+
+	dec := xml.NewDecoder(io.LimitReader(rc, maxDOCXXMLSize))
+	var (
+		b      strings.Builder
+		inText bool
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("parse docx body: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.WriteByte('\t')
+			case "br", "cr":
+				b.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+	return strings.TrimSpace(b.String()), nil
+}
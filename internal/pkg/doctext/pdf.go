@@ -0,0 +1,231 @@
+package doctext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	pdftotextTimeout = 30 * time.Second
+	// maxPDFStreamSize bounds each inflated content stream.
+	maxPDFStreamSize = 8 * 1024 * 1024
+)
+
+var (
+	pdftotextOnce sync.Once
+	pdftotextPath string
+
+	pdfStreamRe = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+)
+
+// extractPDF converts a PDF to text. It prefers the poppler `pdftotext` CLI
+// when installed, which handles fonts and layout properly, and falls back to
+// a built-in extractor that reads literal strings from content streams.
+func extractPDF(data []byte) (string, error) {
+	if text, err := runPDFToText(data); err == nil && strings.TrimSpace(text) != "" {
+		return text, nil
+	}
+
+	text := extractPDFStreams(data)
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("no extractable text found in pdf")
+	}
+	return text, nil
+}
+
+func runPDFToText(data []byte) (string, error) {
+	pdftotextOnce.Do(func() {
+		pdftotextPath, _ = exec.LookPath("pdftotext")
+	})
+	if pdftotextPath == "" {
+		return "", exec.ErrNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pdftotextTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, pdftotextPath, "-q", "-enc", "UTF-8", "-layout", "-", "-")
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("pdftotext: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// extractPDFStreams walks every stream object, inflates FlateDecode streams
+// and collects the strings drawn by Tj, TJ, ' and " operators. Text encoded
+// through CID fonts (hex strings) is skipped; pdftotext covers those.
+func extractPDFStreams(data []byte) string {
+	var b strings.Builder
+	for _, loc := range pdfStreamRe.FindAllSubmatchIndex(data, -1) {
+		dict := data[loc[2]:loc[3]]
+		// The lazy match may start at an earlier object; keep only this one.
+		if k := bytes.LastIndex(dict, []byte("obj")); k >= 0 {
+			dict = dict[k:]
+		}
+		start := loc[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		raw := data[start : start+end]
+
+		if bytes.Contains(dict, []byte("/Subtype/Image")) || bytes.Contains(dict, []byte("/Subtype /Image")) {
+			continue
+		}
+		content := raw
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			zr, err := zlib.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				continue
+			}
+			inflated, err := io.ReadAll(io.LimitReader(zr, maxPDFStreamSize))
+			_ = zr.Close()
+			if err != nil && len(inflated) == 0 {
+				continue
+			}
+			content = inflated
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			// Other filters (DCT, LZW, ...) never carry text we can decode.
+			continue
+		}
+		writePDFText(&b, content)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// writePDFText scans a content stream and appends the text operands of
+// text-showing operators, inserting line breaks on positioning operators.
+func writePDFText(b *strings.Builder, content []byte) {
+	if !bytes.Contains(content, []byte("BT")) {
+		return
+	}
+
+	var pending []string
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '(':
+			s, next := readPDFLiteral(content, i)
+			pending = append(pending, s)
+			i = next
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case isPDFDelimiterOrSpace(c):
+			i++
+		default:
+			start := i
+			for i < len(content) && !isPDFDelimiterOrSpace(content[i]) {
+				i++
+			}
+			switch string(content[start:i]) {
+			case "Tj", "TJ":
+				b.WriteString(strings.Join(pending, ""))
+				pending = pending[:0]
+			case "'", "\"":
+				b.WriteByte('\n')
+				b.WriteString(strings.Join(pending, ""))
+				pending = pending[:0]
+			case "T*", "Td", "TD":
+				if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+					b.WriteByte('\n')
+				}
+			case "ET":
+				if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+					b.WriteByte('\n')
+				}
+				pending = pending[:0]
+			}
+		}
+	}
+}
+
+// readPDFLiteral decodes a literal string starting at content[i] == '('
+// and returns it along with the index just after the closing parenthesis.
+func readPDFLiteral(content []byte, i int) (string, int) {
+	var out []byte
+	depth := 0
+	for i < len(content) {
+		c := content[i]
+		switch {
+		case c == '\\' && i+1 < len(content):
+			i++
+			switch e := content[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// Line continuation.
+			default:
+				if e >= '0' && e <= '7' {
+					v := 0
+					n := 0
+					for n < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7' {
+						v = v*8 + int(content[i]-'0')
+						i++
+						n++
+					}
+					out = append(out, byte(v))
+					continue
+				}
+				out = append(out, e)
+			}
+		case c == '(':
+			if depth > 0 {
+				out = append(out, c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return decodePDFString(out), i + 1
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+		i++
+	}
+	return decodePDFString(out), i
+}
+
+// decodePDFString keeps UTF-8 payloads as-is and otherwise maps each byte to
+// the Latin-1 rune of the same value, which matches PDFDocEncoding for the
+// printable ASCII and Latin-1 range.
+func decodePDFString(raw []byte) string {
+	if utf8.Valid(raw) {
+		return string(raw)
+	}
+	runes := make([]rune, len(raw))
+	for i, c := range raw {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func isPDFDelimiterOrSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '[', ']', '<', '>', '{', '}', '/', '(', ')', '%':
+		return true
+	}
+	return false
+}
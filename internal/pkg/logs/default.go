@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/fatih/color"
@@ -31,7 +32,10 @@ type Options struct {
 	Compress   bool
 }
 
-var logger Logger = newDefaultLogger()
+var (
+	logger   Logger = newDefaultLogger()
+	scrubber atomic.Pointer[func(string) string]
+)
 
 // SetLogger sets global logger.
 // Note that this method is not concurrent-safe.
@@ -51,6 +55,17 @@ func DefaultLogger() Logger {
 	return logger
 }
 
+// SetScrubber installs fn to rewrite every log message before it is
+// written, e.g. to redact secrets; nil removes it. It applies to loggers
+// created by this package.
+func SetScrubber(fn func(string) string) {
+	if fn == nil {
+		scrubber.Store(nil)
+		return
+	}
+	scrubber.Store(&fn)
+}
+
 func Init(opts Options) error {
 	l, err := newConfiguredLogger(opts)
 	if err != nil {
@@ -148,6 +163,7 @@ func newDefaultLogger() Logger {
 	log := logrus.New()
 	log.SetFormatter(&customFormatter{enableColor: shouldColorizeStdout("stdout")})
 	log.SetLevel(logrus.InfoLevel)
+	log.AddHook(scrubHook{})
 	return &defaultLogger{log: log}
 }
 
@@ -172,9 +188,24 @@ func newConfiguredLogger(opts Options) (Logger, error) {
 	}
 
 	log.SetLevel(parseLogLevel(opts.Level))
+	log.AddHook(scrubHook{})
 	return &defaultLogger{log: log}, nil
 }
 
+// scrubHook applies the scrubber set with SetScrubber to each entry.
+type scrubHook struct{}
+
+func (scrubHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (scrubHook) Fire(entry *logrus.Entry) error {
+	if fn := scrubber.Load(); fn != nil {
+		entry.Message = (*fn)(entry.Message)
+	}
+	return nil
+}
+
 func buildWriter(opts Options, output string) (io.Writer, error) {
 	switch output {
 	case "stdout":
@@ -33,6 +33,22 @@ var SupportedProviders = []Type{
 	CLI,
 }
 
+// SupportsNativeFile reports whether providers of the given family accept a
+// document with this MIME type as a native file input part. Other providers
+// receive the extracted text instead.
+//
+// Anthropic is not listed: the eino-ext claude adapter rejects file_url parts,
+// so documents reach Claude as extracted text until the adapter maps them to
+// document blocks.
+func SupportsNativeFile(t Type, mimeType string) bool {
+	switch t {
+	case Gemini:
+		return mimeType == "application/pdf" || strings.HasPrefix(mimeType, "text/")
+	default:
+		return false
+	}
+}
+
 type ModelInfo struct {
 	ID       string `json:"id"`
 	Name     string `json:"name"`
@@ -0,0 +1,139 @@
+// Package acl evaluates channel ACL rules. The gateway's plain ACL check
+// and the pairing flow share this engine.
+//
+// Evaluation order:
+//
+//  1. Rule selection: the rule whose key equals the chat key wins; otherwise
+//     the pattern key ("group:-100*", "group:*", "*") with the longest
+//     matching prefix. Without a rule the caller decides: plain ACLs allow,
+//     pairing asks the user to pair.
+//  2. Block: a user matching an entry of block is denied.
+//  3. Grants: a user listed by exact ID in allow is allowed unless the
+//     user's grant has expired.
+//  4. Patterns: a user matching a wildcard or prefix entry of allow is
+//     allowed, even if an exact grant expired.
+//  5. Owner presence: with owner_present, anyone in a group chat that a
+//     channel owner belongs to is allowed.
+//  6. Open rule: a rule without allow entries and without owner_present
+//     allows everyone not blocked.
+//  7. Everyone else is denied.
+//
+// Denials carry the rule's reason, which is sent to the user when set.
+package acl
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tgifai/friday/internal/config"
+)
+
+// Decision reasons, logged by callers.
+const (
+	ReasonNoRule       = "no_rule"
+	ReasonBlocked      = "blocked"
+	ReasonGranted      = "granted"
+	ReasonPattern      = "pattern"
+	ReasonOwnerPresent = "owner_present"
+	ReasonOpen         = "open"
+	ReasonExpired      = "grant_expired"
+	ReasonNotAllowed   = "not_allowed"
+)
+
+// Request is the access question put to the engine.
+type Request struct {
+	ChatKey string
+	UserID  string
+	Now     time.Time
+	// OwnerPresent reports whether a channel owner belongs to the chat. It
+	// is only called for owner_present rules; nil means no owner is known.
+	OwnerPresent func() bool
+}
+
+// Decision is the engine's answer.
+type Decision struct {
+	Matched bool   // a rule applies to the chat
+	Allowed bool   // the user may use the bot; false when no rule matched
+	Rule    string // key of the deciding rule
+	Reason  string // one of the Reason constants
+	Reply   string // the rule's reason text for denied users, may be empty
+}
+
+// Match reports whether value matches pattern: "*" matches everything, a
+// trailing "*" matches by prefix, anything else must be equal.
+func Match(pattern, value string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(value, prefix)
+	}
+	return pattern == value
+}
+
+// FindRule returns the rule governing chatKey: the exact key, else the
+// matching pattern key with the longest prefix.
+func FindRule(rules map[string]config.ChannelACLConfig, chatKey string) (string, config.ChannelACLConfig, bool) {
+	if rule, ok := rules[chatKey]; ok {
+		return chatKey, rule, true
+	}
+	best, found := "", false
+	for key := range rules {
+		if !strings.HasSuffix(key, "*") || !Match(key, chatKey) {
+			continue
+		}
+		if !found || len(key) > len(best) || (len(key) == len(best) && key < best) {
+			best, found = key, true
+		}
+	}
+	if !found {
+		return "", config.ChannelACLConfig{}, false
+	}
+	return best, rules[best], true
+}
+
+// Evaluate applies the rules to req in the documented order.
+func Evaluate(rules map[string]config.ChannelACLConfig, req Request) Decision {
+	key, rule, ok := FindRule(rules, req.ChatKey)
+	if !ok {
+		return Decision{Reason: ReasonNoRule}
+	}
+	allow := func(reason string) Decision {
+		return Decision{Matched: true, Allowed: true, Rule: key, Reason: reason}
+	}
+	deny := func(reason string) Decision {
+		return Decision{Matched: true, Rule: key, Reason: reason, Reply: rule.Reason}
+	}
+
+	for _, one := range rule.Block {
+		if Match(one, req.UserID) {
+			return deny(ReasonBlocked)
+		}
+	}
+
+	expired := false
+	for _, one := range rule.Allow {
+		if one != req.UserID {
+			continue
+		}
+		grant, ok := rule.Grants[one]
+		if !ok || grant.Expires.IsZero() || req.Now.Before(grant.Expires) {
+			return allow(ReasonGranted)
+		}
+		expired = true
+	}
+	for _, one := range rule.Allow {
+		if strings.HasSuffix(one, "*") && Match(one, req.UserID) {
+			return allow(ReasonPattern)
+		}
+	}
+
+	if rule.OwnerPresent && strings.HasPrefix(req.ChatKey, "group:") &&
+		req.OwnerPresent != nil && req.OwnerPresent() {
+		return allow(ReasonOwnerPresent)
+	}
+	if len(rule.Allow) == 0 && !rule.OwnerPresent {
+		return allow(ReasonOpen)
+	}
+	if expired {
+		return deny(ReasonExpired)
+	}
+	return deny(ReasonNotAllowed)
+}
@@ -0,0 +1,90 @@
+package acl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tgifai/friday/internal/config"
+)
+
+func TestFindRule(t *testing.T) {
+	rules := map[string]config.ChannelACLConfig{
+		"group:-100":   {},
+		"group:-1001*": {},
+		"group:*":      {},
+		"*":            {},
+	}
+	for chatKey, want := range map[string]string{
+		"group:-100":     "group:-100",
+		"group:-1001234": "group:-1001*",
+		"group:-5":       "group:*",
+		"user:7":         "*",
+	} {
+		if got, _, ok := FindRule(rules, chatKey); !ok || got != want {
+			t.Errorf("FindRule(%q) = %q, %v; want %q", chatKey, got, ok, want)
+		}
+	}
+	if _, _, ok := FindRule(map[string]config.ChannelACLConfig{"group:1": {}}, "group:2"); ok {
+		t.Error("FindRule matched an unrelated exact key")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	rules := map[string]config.ChannelACLConfig{
+		"group:-1": {
+			Allow: []string{"1", "2", "9*"},
+			Block: []string{"3", "66*"},
+			Grants: map[string]config.ACLGrant{
+				"2": {Expires: now.Add(-time.Minute)},
+			},
+			Reason: "Members only.",
+		},
+		"group:*":  {Allow: []string{"1"}, OwnerPresent: true},
+		"user:*":   {Block: []string{"5"}},
+		"user:50":  {Allow: []string{"50"}, Grants: map[string]config.ACLGrant{"50": {Expires: now.Add(time.Hour)}}},
+		"group:-8": {Allow: []string{"2", "2*"}, Grants: map[string]config.ACLGrant{"2": {Expires: now}}},
+	}
+	present := func() bool { return true }
+
+	cases := []struct {
+		name    string
+		req     Request
+		allowed bool
+		reason  string
+		reply   string
+	}{
+		{"exact grant", Request{ChatKey: "group:-1", UserID: "1"}, true, ReasonGranted, ""},
+		{"prefix user", Request{ChatKey: "group:-1", UserID: "987"}, true, ReasonPattern, ""},
+		{"block wins", Request{ChatKey: "group:-1", UserID: "3"}, false, ReasonBlocked, "Members only."},
+		{"block prefix", Request{ChatKey: "group:-1", UserID: "6612"}, false, ReasonBlocked, "Members only."},
+		{"expired grant", Request{ChatKey: "group:-1", UserID: "2"}, false, ReasonExpired, "Members only."},
+		{"expired exact, pattern still applies", Request{ChatKey: "group:-8", UserID: "2"}, true, ReasonPattern, ""},
+		{"unlisted", Request{ChatKey: "group:-1", UserID: "4"}, false, ReasonNotAllowed, "Members only."},
+		{"owner present", Request{ChatKey: "group:-7", UserID: "4", OwnerPresent: present}, true, ReasonOwnerPresent, ""},
+		{"owner absent", Request{ChatKey: "group:-7", UserID: "4"}, false, ReasonNotAllowed, ""},
+		{"open rule", Request{ChatKey: "user:4", UserID: "4"}, true, ReasonOpen, ""},
+		{"open rule block", Request{ChatKey: "user:5", UserID: "5"}, false, ReasonBlocked, ""},
+		{"grant not yet expired", Request{ChatKey: "user:50", UserID: "50"}, true, ReasonGranted, ""},
+	}
+	for _, tc := range cases {
+		tc.req.Now = now
+		got := Evaluate(rules, tc.req)
+		if !got.Matched || got.Allowed != tc.allowed || got.Reason != tc.reason || got.Reply != tc.reply {
+			t.Errorf("%s: Evaluate = %+v; want allowed=%v reason=%s reply=%q", tc.name, got, tc.allowed, tc.reason, tc.reply)
+		}
+	}
+
+	if got := Evaluate(map[string]config.ChannelACLConfig{"group:1": {}}, Request{ChatKey: "group:2", UserID: "1"}); got.Matched || got.Allowed {
+		t.Errorf("no rule: Evaluate = %+v, want unmatched", got)
+	}
+}
+
+func TestEvaluate_OwnerPresentOnlyInGroups(t *testing.T) {
+	called := false
+	rules := map[string]config.ChannelACLConfig{"*": {OwnerPresent: true}}
+	got := Evaluate(rules, Request{ChatKey: "user:1", UserID: "1", OwnerPresent: func() bool { called = true; return true }})
+	if got.Allowed || called {
+		t.Fatalf("owner_present applied to a private chat: %+v, called=%v", got, called)
+	}
+}
@@ -0,0 +1,127 @@
+// Package audit keeps a tamper-evident, append-only log of tool executions.
+// Each line carries the SHA-256 of the previous line's hash and its own
+// entry, so editing, reordering or deleting lines breaks the chain; Verify
+// walks it and reports the first broken link.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tgifai/friday/internal/consts"
+)
+
+// Outcomes of a tool call.
+const (
+	OutcomeOK     = "ok"
+	OutcomeError  = "error"
+	OutcomeDenied = "denied" // blocked by policy before the tool ran
+)
+
+const logDirName = "audit"
+
+// Entry is one tool invocation.
+type Entry struct {
+	Time         time.Time      `json:"time"`
+	AgentID      string         `json:"agent_id,omitempty"`
+	SessionKey   string         `json:"session_key,omitempty"`
+	UserID       string         `json:"user_id,omitempty"`
+	ChannelID    string         `json:"channel_id,omitempty"`
+	Scheduled    bool           `json:"scheduled,omitempty"`
+	Tool         string         `json:"tool"`
+	CallID       string         `json:"call_id,omitempty"`
+	Args         map[string]any `json:"args,omitempty"` // secrets redacted, long values truncated
+	Outcome      string         `json:"outcome"`
+	Error        string         `json:"error,omitempty"`
+	DurationMs   int64          `json:"duration_ms"`
+	ResultSize   int            `json:"result_size"`
+	ResultDigest string         `json:"result_digest,omitempty"` // sha256 of the result sent to the model
+	Taint        string         `json:"taint,omitempty"`         // untrusted or suspicious for results from outside sources
+}
+
+var (
+	defaultOnce sync.Once
+	defaultLog  *Log
+)
+
+// Default returns the log under FRIDAY_HOME/audit.
+func Default() *Log {
+	defaultOnce.Do(func() {
+		defaultLog = NewLog(DefaultDir())
+	})
+	return defaultLog
+}
+
+// DefaultDir is the directory holding the default log files.
+func DefaultDir() string {
+	return filepath.Join(consts.FridayHomeDir(), logDirName)
+}
+
+// Digest returns the "sha256:<hex>" digest of a tool result.
+func Digest(result string) string {
+	sum := sha256.Sum256([]byte(result))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+const (
+	redacted       = "[REDACTED]"
+	maxArgValueLen = 512
+)
+
+// sensitiveKeys are argument name fragments whose values are never logged.
+var sensitiveKeys = []string{
+	"password", "passwd", "secret", "token", "api_key", "apikey",
+	"authorization", "cookie", "credential", "private_key",
+}
+
+// RedactArgs returns a copy of args fit for the log: values under
+// secret-looking keys are replaced and long strings truncated.
+func RedactArgs(args map[string]any) map[string]any {
+	if len(args) == 0 {
+		return nil
+	}
+	out, _ := redactValue(args).(map[string]any)
+	return out
+}
+
+func redactValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, one := range val {
+			if isSensitive(k) {
+				out[k] = redacted
+				continue
+			}
+			out[k] = redactValue(one)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, one := range val {
+			out[i] = redactValue(one)
+		}
+		return out
+	case string:
+		if len(val) > maxArgValueLen {
+			return val[:maxArgValueLen] + "…[truncated]"
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	for _, one := range sensitiveKeys {
+		if strings.Contains(key, one) {
+			return true
+		}
+	}
+	return false
+}
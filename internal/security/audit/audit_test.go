@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func appendN(t *testing.T, l *Log, start time.Time, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		e := Entry{Time: start.Add(time.Duration(i) * time.Hour), Tool: "exec", UserID: "u1", Outcome: OutcomeOK}
+		if i%2 == 1 {
+			e.Tool, e.UserID = "web_fetch", "u2"
+		}
+		l.now = func() time.Time { return e.Time }
+		if err := l.Append(e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+}
+
+func TestLog_AppendQueryVerify(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLog(dir)
+	// Spans a month boundary, so the chain continues across files.
+	appendN(t, l, time.Date(2026, 4, 30, 22, 0, 0, 0, time.Local), 4)
+
+	// A fresh Log resumes the chain from the head file.
+	l = NewLog(dir)
+	appendN(t, l, time.Date(2026, 5, 2, 0, 0, 0, 0, time.Local), 2)
+
+	report, err := l.Verify()
+	if err != nil || report.Entries != 6 || report.Files != 2 {
+		t.Fatalf("Verify = %+v, %v; want 6 entries in 2 files", report, err)
+	}
+
+	got, err := l.Query(Filter{Tool: "web_fetch"})
+	if err != nil || len(got) != 3 {
+		t.Fatalf("Query(tool) = %d entries, %v; want 3", len(got), err)
+	}
+	got, _ = l.Query(Filter{Since: time.Date(2026, 5, 1, 0, 0, 0, 0, time.Local), UserID: "u1"})
+	if len(got) != 2 {
+		t.Fatalf("Query(since, user) = %d entries, want 2", len(got))
+	}
+}
+
+func TestLog_CallAcrossMonthBoundary(t *testing.T) {
+	l := NewLog(t.TempDir())
+	appendN(t, l, time.Date(2026, 7, 31, 23, 0, 0, 0, time.Local), 2)
+
+	// A call started in July finishes after a call logged in August.
+	started := time.Date(2026, 7, 31, 23, 59, 0, 0, time.Local)
+	l.now = func() time.Time { return time.Date(2026, 8, 1, 0, 5, 0, 0, time.Local) }
+	if err := l.Append(Entry{Time: started, Tool: "agent", Outcome: OutcomeOK}); err != nil {
+		t.Fatal(err)
+	}
+
+	if report, err := l.Verify(); err != nil || report.Entries != 3 {
+		t.Fatalf("Verify = %+v, %v; want 3 entries", report, err)
+	}
+	got, err := l.Query(Filter{Until: time.Date(2026, 8, 1, 0, 0, 0, 0, time.Local), Tool: "agent"})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("Query(until) = %d entries, %v; want the July call logged in August", len(got), err)
+	}
+}
+
+func TestLog_VerifyDetectsTampering(t *testing.T) {
+	cases := map[string]func(lines []string) []string{
+		"edit": func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], `"exec"`, `"read"`, 1)
+			lines[1] = strings.Replace(lines[1], `"web_fetch"`, `"read"`, 1)
+			return lines
+		},
+		"delete": func(lines []string) []string { return append(lines[:1], lines[2:]...) },
+		"swap":   func(lines []string) []string { lines[0], lines[1] = lines[1], lines[0]; return lines },
+		"truncate tail": func(lines []string) []string {
+			return lines[:len(lines)-1]
+		},
+	}
+	for name, tamper := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			l := NewLog(dir)
+			appendN(t, l, time.Date(2026, 6, 1, 0, 0, 0, 0, time.Local), 3)
+
+			path := filepath.Join(dir, "2026-06.jsonl")
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n")
+			lines = tamper(lines)
+			if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := l.Verify(); !errors.Is(err, ErrChainBroken) {
+				t.Fatalf("Verify after %s = %v, want ErrChainBroken", name, err)
+			}
+		})
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	args := map[string]any{
+		"command": "ls",
+		"headers": map[string]any{"Authorization": "Bearer abc", "Accept": "text/html"},
+		"env":     []any{map[string]any{"GITHUB_TOKEN": "ghp_x"}},
+		"content": strings.Repeat("a", 2*maxArgValueLen),
+	}
+	got := RedactArgs(args)
+
+	headers := got["headers"].(map[string]any)
+	if headers["Authorization"] != redacted || headers["Accept"] != "text/html" {
+		t.Errorf("headers = %v", headers)
+	}
+	if env := got["env"].([]any)[0].(map[string]any); env["GITHUB_TOKEN"] != redacted {
+		t.Errorf("env = %v", env)
+	}
+	if content := got["content"].(string); len(content) >= 2*maxArgValueLen {
+		t.Errorf("content was not truncated: %d bytes", len(content))
+	}
+	if args["headers"].(map[string]any)["Authorization"] != "Bearer abc" {
+		t.Error("RedactArgs modified its input")
+	}
+}
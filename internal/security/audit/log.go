@@ -0,0 +1,359 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	monthLayout  = "2006-01"
+	headFileName = "head.json"
+)
+
+// line is the stored form of an entry. Entry keeps the exact bytes that were
+// hashed, so verification doesn't depend on re-encoding; the log therefore
+// uses encoding/json, whose RawMessage round-trips byte for byte.
+type line struct {
+	Seq   int64           `json:"seq"`
+	Prev  string          `json:"prev"`
+	Hash  string          `json:"hash"`
+	Entry json.RawMessage `json:"entry"`
+}
+
+// head is the last link of the chain, kept beside the log files so that
+// truncating the newest lines is detected too.
+type head struct {
+	Seq  int64  `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+// Log is a hash-chained, append-only record of tool calls, stored as one
+// JSONL file per calendar month (e.g. 2026-10.jsonl). The chain continues
+// across files. Lines go to the file of the month they are appended in,
+// not that of the entry's Time, which is when the call started; otherwise
+// a call running across a month boundary would put a later link into an
+// earlier file.
+type Log struct {
+	dir string
+	mu  sync.Mutex
+	now func() time.Time
+
+	loaded bool
+	head   head
+}
+
+// NewLog creates a Log writing to dir. The directory is created on the first
+// Append.
+func NewLog(dir string) *Log {
+	return &Log{dir: dir, now: time.Now}
+}
+
+func chainHash(seq int64, prev string, entry []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n%s\n", seq, prev)
+	h.Write(entry)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Append links e to the chain and writes it to the file of the current
+// month.
+func (l *Log) Append(e Entry) error {
+	entry, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.loadHeadLocked(); err != nil {
+		return err
+	}
+	next := line{Seq: l.head.Seq + 1, Prev: l.head.Hash, Entry: entry}
+	next.Hash = chainHash(next.Seq, next.Prev, entry)
+	data, err := json.Marshal(next)
+	if err != nil {
+		return fmt.Errorf("marshal audit line: %w", err)
+	}
+	data = append(data, '\n')
+
+	if err := os.MkdirAll(l.dir, 0o700); err != nil {
+		return fmt.Errorf("create audit directory: %w", err)
+	}
+	f, err := os.OpenFile(l.monthPath(l.now()), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("write audit log: %w", err)
+	}
+
+	l.head = head{Seq: next.Seq, Hash: next.Hash}
+	return l.saveHeadLocked()
+}
+
+// loadHeadLocked reads the chain head once. Without a head file the chain
+// is resumed from the newest line on disk.
+func (l *Log) loadHeadLocked() error {
+	if l.loaded {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(l.dir, headFileName))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &l.head); err != nil {
+			return fmt.Errorf("unmarshal audit head: %w", err)
+		}
+	case os.IsNotExist(err):
+		last, err := l.lastLine()
+		if err != nil {
+			return err
+		}
+		l.head = head{Seq: last.Seq, Hash: last.Hash}
+	default:
+		return fmt.Errorf("read audit head: %w", err)
+	}
+	l.loaded = true
+	return nil
+}
+
+func (l *Log) saveHeadLocked() error {
+	data, err := json.Marshal(l.head)
+	if err != nil {
+		return fmt.Errorf("marshal audit head: %w", err)
+	}
+	path := filepath.Join(l.dir, headFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write audit head: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rename audit head: %w", err)
+	}
+	return nil
+}
+
+func (l *Log) lastLine() (line, error) {
+	paths, err := l.files(time.Time{}, time.Time{})
+	if err != nil || len(paths) == 0 {
+		return line{}, err
+	}
+	var last line
+	err = scanLines(paths[len(paths)-1], false, func(ln line, _ int) error {
+		last = ln
+		return nil
+	})
+	return last, err
+}
+
+// Filter selects log entries. Zero fields match everything; Until is
+// exclusive.
+type Filter struct {
+	Since      time.Time
+	Until      time.Time
+	AgentID    string
+	UserID     string
+	ChannelID  string
+	SessionKey string
+	Tool       string
+}
+
+func (f Filter) match(e *Entry) bool {
+	switch {
+	case !f.Since.IsZero() && e.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !e.Time.Before(f.Until):
+		return false
+	case f.AgentID != "" && e.AgentID != f.AgentID:
+		return false
+	case f.UserID != "" && e.UserID != f.UserID:
+		return false
+	case f.ChannelID != "" && e.ChannelID != f.ChannelID:
+		return false
+	case f.SessionKey != "" && e.SessionKey != f.SessionKey:
+		return false
+	case f.Tool != "" && e.Tool != f.Tool:
+		return false
+	}
+	return true
+}
+
+// Query returns the entries matching f in the order they were logged. Only the
+// monthly files that may hold entries of [Since, Until) are read, including
+// the month after, where calls started before Until and finished after the
+// month ended are stored; malformed lines are skipped (Verify reports them).
+func (l *Log) Query(f Filter) ([]Entry, error) {
+	until := f.Until
+	if !until.IsZero() {
+		until = until.AddDate(0, 1, 0)
+	}
+	paths, err := l.files(f.Since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Entry
+	for _, path := range paths {
+		err := scanLines(path, false, func(ln line, _ int) error {
+			var e Entry
+			if err := json.Unmarshal(ln.Entry, &e); err != nil {
+				return nil
+			}
+			if f.match(&e) {
+				out = append(out, e)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// ErrChainBroken is returned by Verify when the log was altered.
+var ErrChainBroken = errors.New("audit chain broken")
+
+// Report summarizes a verification run.
+type Report struct {
+	Entries int    // lines checked
+	Files   int    // log files checked
+	Head    string // hash of the last line
+}
+
+// Verify checks every line of the log in order: sequence numbers must be
+// consecutive, each line must link to the previous hash and its own hash
+// must match its entry, and the last line must match the recorded head.
+func (l *Log) Verify() (Report, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	paths, err := l.files(time.Time{}, time.Time{})
+	if err != nil {
+		return Report{}, err
+	}
+
+	var report Report
+	var prev head
+	for _, path := range paths {
+		report.Files++
+		name := filepath.Base(path)
+		err := scanLines(path, true, func(ln line, no int) error {
+			switch {
+			case ln.Seq != prev.Seq+1:
+				return fmt.Errorf("%w: %s line %d: sequence %d follows %d", ErrChainBroken, name, no, ln.Seq, prev.Seq)
+			case ln.Prev != prev.Hash:
+				return fmt.Errorf("%w: %s line %d: does not link to the previous entry", ErrChainBroken, name, no)
+			case chainHash(ln.Seq, ln.Prev, ln.Entry) != ln.Hash:
+				return fmt.Errorf("%w: %s line %d: entry does not match its hash", ErrChainBroken, name, no)
+			}
+			prev = head{Seq: ln.Seq, Hash: ln.Hash}
+			report.Entries++
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	report.Head = prev.Hash
+
+	data, err := os.ReadFile(filepath.Join(l.dir, headFileName))
+	if err != nil {
+		if os.IsNotExist(err) && report.Entries == 0 {
+			return report, nil
+		}
+		return report, fmt.Errorf("%w: read head: %v", ErrChainBroken, err)
+	}
+	var recorded head
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return report, fmt.Errorf("%w: unmarshal head: %v", ErrChainBroken, err)
+	}
+	if recorded != prev {
+		return report, fmt.Errorf("%w: log ends at entry %d but the head records entry %d; entries were removed",
+			ErrChainBroken, prev.Seq, recorded.Seq)
+	}
+	return report, nil
+}
+
+func (l *Log) monthPath(t time.Time) string {
+	return filepath.Join(l.dir, t.Format(monthLayout)+".jsonl")
+}
+
+// files lists the monthly files that may hold entries in [since, until).
+func (l *Log) files(since, until time.Time) ([]string, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read audit directory: %w", err)
+	}
+
+	var paths []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".jsonl")
+		if e.IsDir() || !ok {
+			continue
+		}
+		month, err := time.ParseInLocation(monthLayout, name, time.Local)
+		if err != nil {
+			continue
+		}
+		if !since.IsZero() && !month.AddDate(0, 1, 0).After(since) {
+			continue
+		}
+		if !until.IsZero() && !month.Before(until) {
+			continue
+		}
+		paths = append(paths, filepath.Join(l.dir, e.Name()))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// scanLines calls fn for each line of path with its 1-based line number.
+// Lines that are not valid JSON are reported as a broken chain when strict,
+// and skipped otherwise.
+func scanLines(path string, strict bool, fn func(ln line, no int) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	no := 0
+	for scanner.Scan() {
+		no++
+		raw := scanner.Bytes()
+		if len(raw) == 0 {
+			continue
+		}
+		var ln line
+		if err := json.Unmarshal(raw, &ln); err != nil {
+			if !strict {
+				continue
+			}
+			return fmt.Errorf("%w: %s line %d: %v", ErrChainBroken, filepath.Base(path), no, err)
+		}
+		if err := fn(ln, no); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read audit log %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+// Package egress decides which hosts the web tools may connect to.
+//
+// A Policy has domain allow and deny lists and, unless told otherwise,
+// blocks private, loopback and link-local addresses so a prompt injected
+// into the model cannot reach localhost, cloud metadata endpoints or the
+// internal network. Hosts are resolved before connecting and every address
+// is checked; the dialer then connects to a checked address, so a DNS
+// answer that changes between the check and the connection (rebinding)
+// gains nothing.
+//
+// A domain in a list covers its subdomains, so "example.com" matches
+// "api.example.com"; "*.example.com" matches the subdomains only. Deny
+// wins over allow, and an empty allow list allows every public host.
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrBlocked is wrapped by every policy violation.
+var ErrBlocked = errors.New("egress policy")
+
+// Options configures a Policy.
+type Options struct {
+	Allow []string // domains that may be reached; empty means any
+	Deny  []string // domains that may never be reached
+	// AllowPrivate lets the tools reach private, loopback and link-local
+	// addresses.
+	AllowPrivate bool
+	// AllowNetworks are CIDRs, or single addresses, that may be reached
+	// even though they are private.
+	AllowNetworks []string
+}
+
+// Policy is an immutable egress policy, safe for concurrent use.
+type Policy struct {
+	allow        []string
+	deny         []string
+	allowPrivate bool
+	networks     []netip.Prefix
+	lookup       func(ctx context.Context, host string) ([]netip.Addr, error)
+}
+
+// New builds a Policy from opts.
+func New(opts Options) (*Policy, error) {
+	p := &Policy{
+		allowPrivate: opts.AllowPrivate,
+		lookup: func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		},
+	}
+	for _, one := range opts.Allow {
+		if one = normalizeHost(one); one != "" {
+			p.allow = append(p.allow, one)
+		}
+	}
+	for _, one := range opts.Deny {
+		if one = normalizeHost(one); one != "" {
+			p.deny = append(p.deny, one)
+		}
+	}
+	for _, one := range opts.AllowNetworks {
+		prefix, err := ParseNetwork(one)
+		if err != nil {
+			return nil, err
+		}
+		p.networks = append(p.networks, prefix)
+	}
+	return p, nil
+}
+
+// ParseNetwork parses a CIDR or a single address.
+func ParseNetwork(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid network %q: %w", s, err)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid network %q: %w", s, err)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host = strings.TrimSuffix(host, ".")
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
+
+// CheckHost reports whether host passes the domain lists. An address
+// literal is checked as an address too.
+func (p *Policy) CheckHost(host string) error {
+	host = normalizeHost(host)
+	if host == "" {
+		return fmt.Errorf("%w: empty host", ErrBlocked)
+	}
+	for _, pattern := range p.deny {
+		if matchDomain(host, pattern) {
+			return fmt.Errorf("%w: %s is denied", ErrBlocked, host)
+		}
+	}
+	if len(p.allow) > 0 {
+		allowed := false
+		for _, pattern := range p.allow {
+			if matchDomain(host, pattern) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %s is not on the allow list", ErrBlocked, host)
+		}
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.CheckAddr(addr)
+	}
+	return nil
+}
+
+// CheckAddr reports whether addr may be connected to.
+func (p *Policy) CheckAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	for _, prefix := range p.networks {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	if !p.allowPrivate && isPrivate(addr) {
+		return fmt.Errorf("%w: %s is a private, loopback or link-local address", ErrBlocked, addr)
+	}
+	return nil
+}
+
+// Resolve checks host, resolves it and checks every address it resolves
+// to. A host with any blocked address is blocked as a whole.
+func (p *Policy) Resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	if err := p.CheckHost(host); err != nil {
+		return nil, err
+	}
+	host = normalizeHost(host)
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr.Unmap()}, nil
+	}
+	addrs, err := p.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses for %s", host)
+	}
+	for i, addr := range addrs {
+		if err := p.CheckAddr(addr); err != nil {
+			return nil, fmt.Errorf("%w (resolved from %s)", err, host)
+		}
+		addrs[i] = addr.Unmap()
+	}
+	return addrs, nil
+}
+
+// CheckURL reports whether the host of u may be reached, resolving it.
+func (p *Policy) CheckURL(ctx context.Context, u *url.URL) error {
+	_, err := p.Resolve(ctx, u.Hostname())
+	return err
+}
+
+// DialContext connects to addr through the policy: the host is resolved
+// and checked, and the connection is made to a checked address.
+func (p *Policy) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := p.Resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var d net.Dialer
+	var firstErr error
+	for _, one := range addrs {
+		conn, err := d.DialContext(ctx, network, net.JoinHostPort(one.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// matchDomain reports whether host is pattern or, unless pattern starts
+// with "*.", one of its subdomains.
+func matchDomain(host, pattern string) bool {
+	if rest, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+rest)
+	}
+	return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
+
+// privateNetworks are the ranges blocked besides those the netip
+// predicates cover.
+var privateNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "this network"; 0.0.0.0 reaches localhost
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT, used inside some clouds
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+}
+
+func isPrivate(addr netip.Addr) bool {
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range privateNetworks {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+var builtin, _ = New(Options{})
+
+var defaultPolicy atomic.Pointer[Policy]
+
+func init() { defaultPolicy.Store(builtin) }
+
+// Default returns the policy used when the context carries none: no domain
+// lists, private addresses blocked.
+func Default() *Policy { return defaultPolicy.Load() }
+
+// SetDefault replaces the default policy; nil restores the built-in one.
+func SetDefault(p *Policy) {
+	if p == nil {
+		p = builtin
+	}
+	defaultPolicy.Store(p)
+}
+
+type ctxKey struct{}
+
+// WithPolicy returns a context whose web tool requests go through p.
+func WithPolicy(ctx context.Context, p *Policy) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext returns the policy of ctx, or the default one.
+func FromContext(ctx context.Context) *Policy {
+	if p, ok := ctx.Value(ctxKey{}).(*Policy); ok && p != nil {
+		return p
+	}
+	return Default()
+}
+
+// DialContext dials through the policy of ctx. It fits
+// http.Transport.DialContext.
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return FromContext(ctx).DialContext(ctx, network, addr)
+}
+
+// Transport returns a copy of http.DefaultTransport, keeping its timeouts,
+// that dials through the policy of each request's context. Proxies are
+// turned off, since a proxy would connect on the tools' behalf past the
+// address checks. Keep-alives are off too: the transport is shared by
+// agents with different policies, and a pooled connection would let one
+// agent reuse an address only another's policy admits.
+func Transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = DialContext
+	t.DisableKeepAlives = true
+	return t
+}
@@ -0,0 +1,130 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+// withHosts makes p resolve from the given table instead of DNS.
+func withHosts(p *Policy, hosts map[string][]string) *Policy {
+	p.lookup = func(_ context.Context, host string) ([]netip.Addr, error) {
+		var addrs []netip.Addr
+		for _, one := range hosts[host] {
+			addrs = append(addrs, netip.MustParseAddr(one))
+		}
+		return addrs, nil
+	}
+	return p
+}
+
+func TestCheckHost_Lists(t *testing.T) {
+	p, err := New(Options{
+		Allow: []string{"example.com", "*.corp.io"},
+		Deny:  []string{"secret.example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range []string{"example.com", "API.Example.com.", "x.corp.io"} {
+		if err := p.CheckHost(host); err != nil {
+			t.Errorf("CheckHost(%s) = %v, want allowed", host, err)
+		}
+	}
+	for _, host := range []string{"corp.io", "notexample.com", "secret.example.com", "a.secret.example.com", "93.184.216.34"} {
+		if err := p.CheckHost(host); !errors.Is(err, ErrBlocked) {
+			t.Errorf("CheckHost(%s) = %v, want ErrBlocked", host, err)
+		}
+	}
+}
+
+func TestCheckAddr_Private(t *testing.T) {
+	p, _ := New(Options{})
+	for _, s := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"0.0.0.0", "100.64.0.1", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1"} {
+		if err := p.CheckAddr(netip.MustParseAddr(s)); !errors.Is(err, ErrBlocked) {
+			t.Errorf("CheckAddr(%s) = %v, want ErrBlocked", s, err)
+		}
+	}
+	if err := p.CheckAddr(netip.MustParseAddr("93.184.216.34")); err != nil {
+		t.Errorf("public address blocked: %v", err)
+	}
+
+	p, err := New(Options{AllowNetworks: []string{"10.0.5.0/24", "192.168.1.7"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.CheckAddr(netip.MustParseAddr("10.0.5.9")); err != nil {
+		t.Errorf("allowed network blocked: %v", err)
+	}
+	if err := p.CheckAddr(netip.MustParseAddr("192.168.1.8")); !errors.Is(err, ErrBlocked) {
+		t.Errorf("address next to an allowed one = %v, want ErrBlocked", err)
+	}
+
+	p, _ = New(Options{AllowPrivate: true})
+	if err := p.CheckAddr(netip.MustParseAddr("127.0.0.1")); err != nil {
+		t.Errorf("allow_private still blocks loopback: %v", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	p, _ := New(Options{})
+	withHosts(p, map[string][]string{
+		"public.test":   {"93.184.216.34"},
+		"rebind.test":   {"93.184.216.34", "127.0.0.1"},
+		"metadata.test": {"169.254.169.254"},
+	})
+	ctx := context.Background()
+
+	addrs, err := p.Resolve(ctx, "public.test")
+	if err != nil || len(addrs) != 1 || addrs[0].String() != "93.184.216.34" {
+		t.Fatalf("Resolve(public.test) = %v, %v", addrs, err)
+	}
+	for _, host := range []string{"rebind.test", "metadata.test", "localhost.test"} {
+		if _, err := p.Resolve(ctx, host); err == nil {
+			t.Errorf("Resolve(%s) succeeded, want an error", host)
+		}
+	}
+	if err := p.CheckURL(ctx, &url.URL{Scheme: "http", Host: "[::1]:8080"}); !errors.Is(err, ErrBlocked) {
+		t.Errorf("CheckURL([::1]) = %v, want ErrBlocked", err)
+	}
+	if _, err := p.DialContext(ctx, "tcp", "metadata.test:80"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("DialContext(metadata.test) = %v, want ErrBlocked", err)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != Default() {
+		t.Fatal("context without a policy should use the default")
+	}
+	p, _ := New(Options{AllowPrivate: true})
+	if FromContext(WithPolicy(context.Background(), p)) != p {
+		t.Fatal("context policy not returned")
+	}
+}
+
+func TestTransport_NoConnectionReuseAcrossPolicies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+	client := &http.Client{Transport: Transport()}
+	get := func(p *Policy) error {
+		req, _ := http.NewRequestWithContext(WithPolicy(context.Background(), p), http.MethodGet, srv.URL, nil)
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	private, _ := New(Options{AllowPrivate: true})
+	if err := get(private); err != nil {
+		t.Fatalf("request allowed by the policy failed: %v", err)
+	}
+	if err := get(Default()); !errors.Is(err, ErrBlocked) {
+		t.Fatalf("request after another policy's connection = %v, want ErrBlocked", err)
+	}
+}
@@ -0,0 +1,308 @@
+// Package fspolicy decides which paths an agent's file tools and commands
+// may touch.
+//
+// A Policy has read-only and read-write roots; a path is governed by the
+// most specific root containing it, so a read-only directory can sit inside
+// a read-write one. Deny globs win over every root. Symlinks are either
+// followed, with the target checked against the roots, or refused
+// outright. Reads and writes can be capped in size.
+//
+// In deny globs "*" and "?" stay within one path component and "**" spans
+// any number of them. A glob without a slash matches any path component,
+// so ".env" denies every .env file and ".ssh" everything under a .ssh
+// directory. Other relative globs match the end of a path, absolute ones
+// the whole path.
+package fspolicy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ErrDenied is wrapped by every policy violation.
+var ErrDenied = errors.New("filesystem policy")
+
+// Access is the kind of access a path is checked for.
+type Access int
+
+const (
+	Read Access = iota
+	Write
+)
+
+func (a Access) String() string {
+	if a == Write {
+		return "write"
+	}
+	return "read"
+}
+
+// Options configures a Policy. Relative roots are taken from Base and "~"
+// stands for the home directory.
+type Options struct {
+	Base          string
+	ReadOnly      []string
+	ReadWrite     []string
+	Deny          []string // globs, see the package doc
+	MaxReadBytes  int64    // 0 means no cap
+	MaxWriteBytes int64    // 0 means no cap
+	// RefuseSymlinks denies paths through a symlink instead of following
+	// them.
+	RefuseSymlinks bool
+}
+
+type root struct {
+	configured string // as configured, made absolute
+	path       string // with symlinks resolved
+	writable   bool
+}
+
+// Policy is an immutable filesystem policy, safe for concurrent use.
+type Policy struct {
+	base           string
+	roots          []root
+	deny           []*regexp.Regexp
+	denyRaw        []string
+	maxRead        int64
+	maxWrite       int64
+	refuseSymlinks bool
+}
+
+// New builds a Policy from opts.
+func New(opts Options) (*Policy, error) {
+	p := &Policy{
+		base:           opts.Base,
+		maxRead:        opts.MaxReadBytes,
+		maxWrite:       opts.MaxWriteBytes,
+		refuseSymlinks: opts.RefuseSymlinks,
+	}
+	for _, one := range opts.ReadOnly {
+		if err := p.addRoot(one, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, one := range opts.ReadWrite {
+		if err := p.addRoot(one, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, one := range opts.Deny {
+		re, err := compileGlob(expandHome(one))
+		if err != nil {
+			return nil, fmt.Errorf("deny %q: %w", one, err)
+		}
+		p.deny = append(p.deny, re)
+		p.denyRaw = append(p.denyRaw, one)
+	}
+	return p, nil
+}
+
+func (p *Policy) addRoot(path string, writable bool) error {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil
+	}
+	path = expandHome(path)
+	if !filepath.IsAbs(path) && p.base != "" {
+		path = filepath.Join(p.base, path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("root %q: %w", path, err)
+	}
+	// Paths are checked with symlinks resolved, so resolve roots too.
+	real := abs
+	if one, err := filepath.EvalSymlinks(abs); err == nil {
+		real = one
+	}
+	p.roots = append(p.roots, root{configured: abs, path: real, writable: writable})
+	return nil
+}
+
+// expandHome replaces a leading "~" with the home directory.
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// Resolve turns path into an absolute path, relative paths being taken
+// from the base, without checking it.
+func (p *Policy) Resolve(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	if !filepath.IsAbs(path) && p.base != "" {
+		path = filepath.Join(p.base, path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	return abs, nil
+}
+
+// Check resolves path and reports whether access to it is allowed. It
+// returns the absolute path to use, with symlinks resolved when they are
+// followed.
+func (p *Policy) Check(path string, access Access) (string, error) {
+	abs, err := p.Resolve(path)
+	if err != nil {
+		return "", err
+	}
+	real, err := p.realPath(abs)
+	if err != nil {
+		return "", err
+	}
+	if p.refuseSymlinks && p.linked(abs, real) {
+		return "", fmt.Errorf("%w: %s goes through a symlink, which this agent may not follow", ErrDenied, path)
+	}
+
+	for _, target := range uniq(abs, real) {
+		for i, re := range p.deny {
+			if re.MatchString(filepath.ToSlash(target)) {
+				return "", fmt.Errorf("%w: %s is denied by rule %q", ErrDenied, path, p.denyRaw[i])
+			}
+		}
+	}
+
+	r, ok := p.rootOf(real)
+	if !ok {
+		if real != abs {
+			return "", fmt.Errorf("%w: %s links to %s, outside the allowed paths", ErrDenied, path, real)
+		}
+		return "", fmt.Errorf("%w: %s is outside the allowed paths", ErrDenied, path)
+	}
+	if access == Write && !r.writable {
+		return "", fmt.Errorf("%w: %s is read-only", ErrDenied, path)
+	}
+	return real, nil
+}
+
+// realPath resolves the symlinks in abs. Missing trailing components, such
+// as a file about to be written, are kept as they are.
+func (p *Policy) realPath(abs string) (string, error) {
+	existing, rest := abs, ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return abs, nil
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+	real, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	return filepath.Join(real, rest), nil
+}
+
+// linked reports whether abs resolves to real through a symlink other than
+// those in a root's own configured path (such as /tmp on macOS).
+func (p *Policy) linked(abs, real string) bool {
+	var best *root
+	for i, r := range p.roots {
+		if within(abs, r.configured) && (best == nil || len(r.configured) > len(best.configured)) {
+			best = &p.roots[i]
+		}
+	}
+	if best == nil {
+		return abs != real
+	}
+	rel, err := filepath.Rel(best.configured, abs)
+	return err != nil || filepath.Join(best.path, rel) != real
+}
+
+// rootOf returns the most specific root containing path.
+func (p *Policy) rootOf(path string) (root, bool) {
+	var best root
+	found := false
+	for _, r := range p.roots {
+		if within(path, r.path) && (!found || len(r.path) > len(best.path)) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+// CheckReadSize reports whether reading size bytes is within the cap.
+func (p *Policy) CheckReadSize(path string, size int64) error {
+	if p.maxRead > 0 && size > p.maxRead {
+		return fmt.Errorf("%w: %s is %d bytes, over the %d byte read limit", ErrDenied, path, size, p.maxRead)
+	}
+	return nil
+}
+
+// CheckWriteSize reports whether writing size bytes is within the cap.
+func (p *Policy) CheckWriteSize(path string, size int64) error {
+	if p.maxWrite > 0 && size > p.maxWrite {
+		return fmt.Errorf("%w: writing %d bytes to %s is over the %d byte write limit", ErrDenied, size, path, p.maxWrite)
+	}
+	return nil
+}
+
+func within(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func uniq(a, b string) []string {
+	if a == b {
+		return []string{a}
+	}
+	return []string{a, b}
+}
+
+// compileGlob turns a deny glob into a regexp over slash-separated
+// absolute paths.
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	glob = filepath.ToSlash(strings.TrimSpace(glob))
+	if glob == "" {
+		return nil, fmt.Errorf("empty glob")
+	}
+
+	var b strings.Builder
+	if strings.HasPrefix(glob, "/") {
+		b.WriteString("^")
+	} else {
+		b.WriteString("(^|/)")
+	}
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case glob[i:] == "/**":
+			// A trailing "/**" covers the directory itself too.
+			b.WriteString("(/.*)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("(/|$)")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,111 @@
+package fspolicy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestPolicy(t *testing.T, opts Options) (*Policy, string) {
+	t.Helper()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []string{"ws/docs", "ws/.ssh", "shared", "outside"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts.Base = filepath.Join(dir, "ws")
+	p, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, dir
+}
+
+func TestCheck_Roots(t *testing.T) {
+	p, dir := newTestPolicy(t, Options{
+		ReadWrite: []string{"."},
+		ReadOnly:  []string{"docs", "../shared"},
+	})
+
+	cases := []struct {
+		path   string
+		access Access
+		ok     bool
+	}{
+		{"notes.md", Write, true},
+		{"docs/a.md", Read, true},
+		{"docs/a.md", Write, false},
+		{filepath.Join(dir, "shared", "x"), Read, true},
+		{filepath.Join(dir, "shared", "x"), Write, false},
+		{filepath.Join(dir, "outside", "x"), Read, false},
+		{"../outside/x", Read, false},
+	}
+	for _, tc := range cases {
+		_, err := p.Check(tc.path, tc.access)
+		if tc.ok && err != nil {
+			t.Errorf("Check(%s, %s) = %v, want allowed", tc.path, tc.access, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrDenied) {
+			t.Errorf("Check(%s, %s) = %v, want ErrDenied", tc.path, tc.access, err)
+		}
+	}
+}
+
+func TestCheck_Deny(t *testing.T) {
+	p, _ := newTestPolicy(t, Options{
+		ReadWrite: []string{"."},
+		Deny:      []string{"**/.ssh/**", ".env", "*.pem", "secrets/*.json"},
+	})
+	for _, path := range []string{".ssh", ".ssh/id_rsa", "app/.env", "certs/server.pem", "x/secrets/db.json"} {
+		if _, err := p.Check(path, Read); !errors.Is(err, ErrDenied) {
+			t.Errorf("Check(%s) = %v, want ErrDenied", path, err)
+		}
+	}
+	for _, path := range []string{"env.md", "app/.envrc", "secrets/sub/db.json", "pem"} {
+		if _, err := p.Check(path, Read); err != nil {
+			t.Errorf("Check(%s) = %v, want allowed", path, err)
+		}
+	}
+}
+
+func TestCheck_Symlinks(t *testing.T) {
+	for _, refuse := range []bool{false, true} {
+		p, dir := newTestPolicy(t, Options{ReadWrite: []string{"."}, RefuseSymlinks: refuse})
+		ws := filepath.Join(dir, "ws")
+		if err := os.Symlink(filepath.Join(dir, "outside"), filepath.Join(ws, "out")); err != nil {
+			t.Skipf("symlinks unsupported: %v", err)
+		}
+		if err := os.Symlink(filepath.Join(ws, "docs"), filepath.Join(ws, "docs-link")); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := p.Check("out/secret", Read); !errors.Is(err, ErrDenied) {
+			t.Errorf("refuse=%v: link out of the roots = %v, want ErrDenied", refuse, err)
+		}
+		real, err := p.Check("docs-link/a.md", Write)
+		switch {
+		case refuse && !errors.Is(err, ErrDenied):
+			t.Errorf("refuse=true: link inside the roots = %v, want ErrDenied", err)
+		case !refuse && (err != nil || real != filepath.Join(ws, "docs", "a.md")):
+			t.Errorf("refuse=false: link inside the roots = %q, %v", real, err)
+		}
+	}
+}
+
+func TestSizeCaps(t *testing.T) {
+	p, _ := newTestPolicy(t, Options{MaxReadBytes: 10, MaxWriteBytes: 5})
+	if err := p.CheckReadSize("a", 10); err != nil {
+		t.Fatalf("read at the cap refused: %v", err)
+	}
+	if err := p.CheckReadSize("a", 11); !errors.Is(err, ErrDenied) {
+		t.Fatalf("read over the cap = %v", err)
+	}
+	if err := p.CheckWriteSize("a", 6); !errors.Is(err, ErrDenied) {
+		t.Fatalf("write over the cap = %v", err)
+	}
+}
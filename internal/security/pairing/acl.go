@@ -0,0 +1,298 @@
+package pairing
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/security/acl"
+)
+
+// ACL entry states reported by Entries.
+const (
+	StatusAllowed = "allowed"
+	StatusBlocked = "blocked"
+	StatusExpired = "expired"
+
+	grantViaConfig = "config"
+)
+
+// ErrOpensChat is returned when a change would leave a group chat's allow
+// list empty while other users are blocked, which lets everyone else in.
+var ErrOpensChat = errors.New("change would leave the allow list empty, which admits every user not blocked")
+
+// Entry is one user listed in a chat's ACL.
+type Entry struct {
+	ChannelID string
+	ChatKey   string
+	UserID    string
+	Status    string    // StatusAllowed or StatusBlocked
+	GrantedAt time.Time // zero for users added by editing the config
+	Via       string    // how access was granted; "config" when unrecorded
+	Expires   time.Time // zero when the grant does not expire
+}
+
+// Check evaluates the channel's ACL rules for a user; see the acl package
+// for the order. ownerPresent may be nil.
+func (m *Manager) Check(chatKey string, userID string, ownerPresent func() bool) (acl.Decision, error) {
+	chatKey = strings.TrimSpace(chatKey)
+	userID = strings.TrimSpace(userID)
+	if chatKey == "" || userID == "" {
+		return acl.Decision{}, errors.New("chatKey and userID cannot be empty")
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return acl.Decision{}, err
+	}
+	chCfg, ok := cfg.Channels[m.chanId]
+	if !ok {
+		return acl.Decision{}, fmt.Errorf("channel not found: %s", m.chanId)
+	}
+	return acl.Evaluate(chCfg.ACL, acl.Request{
+		ChatKey:      chatKey,
+		UserID:       userID,
+		Now:          time.Now(),
+		OwnerPresent: ownerPresent,
+	}), nil
+}
+
+// GrantACL adds userID to the chat's allow list, lifting any block, and
+// records the grant. A zero grant.At is set to now; a zero grant.Expires
+// takes the channel's security.grant_ttl. Re-granting renews an expiring
+// grant but never shortens a permanent one.
+func (m *Manager) GrantACL(chatKey string, userID string, grant config.ACLGrant) (bool, error) {
+	return m.updateACL(chatKey, userID, func(chCfg *config.ChannelConfig, entry *config.ChannelACLConfig) bool {
+		if grant.At.IsZero() {
+			grant.At = time.Now()
+		}
+		if grant.Expires.IsZero() && chCfg.Security.GrantTTL != "" {
+			if ttl, err := time.ParseDuration(chCfg.Security.GrantTTL); err == nil && ttl > 0 {
+				grant.Expires = grant.At.Add(ttl)
+			}
+		}
+
+		changed := assignPairedRole(&chCfg.Roles, userID)
+		prev, hasGrant := entry.Grants[userID]
+		switch {
+		case !slices.Contains(entry.Allow, userID):
+			entry.Allow = append(entry.Allow, userID)
+		case hasGrant && !prev.Expires.IsZero():
+			// Renew an expiring grant.
+		default:
+			grant = config.ACLGrant{}
+		}
+		if grant != (config.ACLGrant{}) {
+			if entry.Grants == nil {
+				entry.Grants = make(map[string]config.ACLGrant)
+			}
+			entry.Grants[userID] = grant
+			changed = true
+		}
+		if slices.Contains(entry.Block, userID) {
+			entry.Block = slices.DeleteFunc(entry.Block, func(id string) bool { return id == userID })
+			changed = true
+		}
+		return changed
+	})
+}
+
+// RevokeACL removes userID from the chat's allow list.
+func (m *Manager) RevokeACL(chatKey string, userID string) (bool, error) {
+	return m.updateACL(chatKey, userID, func(_ *config.ChannelConfig, entry *config.ChannelACLConfig) bool {
+		if !slices.Contains(entry.Allow, userID) {
+			return false
+		}
+		removeAllowed(entry, userID)
+		return true
+	})
+}
+
+// BlockACL moves userID from the chat's allow list to its block list.
+// Blocked users are ignored without being offered pairing.
+func (m *Manager) BlockACL(chatKey string, userID string) (bool, error) {
+	return m.updateACL(chatKey, userID, func(_ *config.ChannelConfig, entry *config.ChannelACLConfig) bool {
+		if slices.Contains(entry.Block, userID) {
+			return false
+		}
+		entry.Block = append(entry.Block, userID)
+		removeAllowed(entry, userID)
+		return true
+	})
+}
+
+// updateACL runs fn on the chat's ACL entry and persists the result through
+// config.Update, which retries when the config changed concurrently.
+func (m *Manager) updateACL(chatKey, userID string,
+	fn func(chCfg *config.ChannelConfig, entry *config.ChannelACLConfig) bool,
+) (bool, error) {
+	chatKey = strings.TrimSpace(chatKey)
+	userID = strings.TrimSpace(userID)
+	if chatKey == "" || userID == "" {
+		return false, errors.New("chatKey and userID cannot be empty")
+	}
+
+	return config.Update(func(cfg *config.Config) (bool, error) {
+		chCfg, ok := cfg.Channels[m.chanId]
+		if !ok {
+			return false, fmt.Errorf("channel not found: %s", m.chanId)
+		}
+		entry := chCfg.ACL[chatKey]
+		if !fn(&chCfg, &entry) {
+			return false, nil
+		}
+		if err := settleEntry(&chCfg, chatKey, entry); err != nil {
+			return false, err
+		}
+		cfg.Channels[m.chanId] = chCfg
+		return true, nil
+	})
+}
+
+// PruneExpiredGrants removes users whose grants expired from the allow
+// lists of every channel. Entries that would be left open to everyone keep
+// their expired users, which the acl engine denies anyway.
+func PruneExpiredGrants(now time.Time) (int, error) {
+	pruned := 0
+	_, err := config.Update(func(cfg *config.Config) (bool, error) {
+		pruned = 0
+		for chanID, chCfg := range cfg.Channels {
+			for chatKey, entry := range chCfg.ACL {
+				var expired []string
+				for userID, grant := range entry.Grants {
+					if !grant.Expires.IsZero() && !now.Before(grant.Expires) {
+						expired = append(expired, userID)
+					}
+				}
+				if len(expired) == 0 {
+					continue
+				}
+				entry.Allow = slices.Clone(entry.Allow)
+				entry.Grants = maps.Clone(entry.Grants)
+				for _, userID := range expired {
+					removeAllowed(&entry, userID)
+				}
+				if err := settleEntry(&chCfg, chatKey, entry); err != nil {
+					continue
+				}
+				pruned += len(expired)
+			}
+			cfg.Channels[chanID] = chCfg
+		}
+		return pruned > 0, nil
+	})
+	return pruned, err
+}
+
+func removeAllowed(entry *config.ChannelACLConfig, userID string) {
+	entry.Allow = slices.DeleteFunc(entry.Allow, func(id string) bool { return id == userID })
+	delete(entry.Grants, userID)
+}
+
+// settleEntry stores an edited ACL entry without ever opening the chat: an
+// entry with no allowed users and no owner_present admits everyone not
+// blocked. Such an entry is dropped when the channel uses pairing (its
+// users have to pair again) and kept for a private chat whose only user is
+// blocked; anything else is refused with ErrOpensChat.
+func settleEntry(chCfg *config.ChannelConfig, chatKey string, entry config.ChannelACLConfig) error {
+	if chCfg.ACL == nil {
+		chCfg.ACL = make(map[string]config.ChannelACLConfig)
+	}
+	pairingEnabled := chCfg.Security.Policy != "" && chCfg.Security.Policy != securityPolicySilent
+	switch {
+	case len(entry.Allow) > 0 || entry.OwnerPresent:
+		chCfg.ACL[chatKey] = entry
+	case pairingEnabled && len(entry.Block) == 0:
+		delete(chCfg.ACL, chatKey)
+	case strings.HasPrefix(chatKey, "user:") && !strings.HasSuffix(chatKey, "*") && len(entry.Block) > 0:
+		chCfg.ACL[chatKey] = entry
+	default:
+		return fmt.Errorf("%w: %s", ErrOpensChat, chatKey)
+	}
+	return nil
+}
+
+// assignPairedRole records the channel's paired role for a newly granted
+// user. Users that already have a role keep it.
+func assignPairedRole(roles *config.ChannelRolesConfig, userID string) bool {
+	if roles.Paired == "" {
+		return false
+	}
+	if _, ok := roles.Users[userID]; ok {
+		return false
+	}
+	if roles.Users == nil {
+		roles.Users = make(map[string]string)
+	}
+	roles.Users[userID] = roles.Paired
+	return true
+}
+
+// Entries lists the users in the ACLs of the given channel, or of every
+// channel when channelID is empty, sorted by channel, chat and user.
+// Allowed users whose grant ended before now are reported as expired.
+func Entries(cfg *config.Config, channelID string, now time.Time) []Entry {
+	var out []Entry
+	for id, chCfg := range cfg.Channels {
+		if channelID != "" && id != channelID {
+			continue
+		}
+		for chatKey, acl := range chCfg.ACL {
+			for _, userID := range acl.Allow {
+				e := Entry{ChannelID: id, ChatKey: chatKey, UserID: userID, Status: StatusAllowed, Via: grantViaConfig}
+				if grant, ok := acl.Grants[userID]; ok {
+					e.GrantedAt, e.Via, e.Expires = grant.At, grant.Via, grant.Expires
+					if !grant.Expires.IsZero() && !now.Before(grant.Expires) {
+						e.Status = StatusExpired
+					}
+				}
+				out = append(out, e)
+			}
+			for _, userID := range acl.Block {
+				out = append(out, Entry{ChannelID: id, ChatKey: chatKey, UserID: userID, Status: StatusBlocked})
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.ChannelID != b.ChannelID {
+			return a.ChannelID < b.ChannelID
+		}
+		if a.ChatKey != b.ChatKey {
+			return a.ChatKey < b.ChatKey
+		}
+		return a.UserID < b.UserID
+	})
+	return out
+}
+
+// FormatEntries renders ACL entries as an aligned table.
+func FormatEntries(entries []Entry) string {
+	if len(entries) == 0 {
+		return "No ACL entries.\n"
+	}
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHANNEL\tCHAT KEY\tUSER\tSTATUS\tGRANTED\tEXPIRES\tVIA")
+	for _, e := range entries {
+		granted, expires, via := "-", "-", e.Via
+		if !e.GrantedAt.IsZero() {
+			granted = e.GrantedAt.Local().Format(time.DateTime)
+		}
+		if !e.Expires.IsZero() {
+			expires = e.Expires.Local().Format(time.DateTime)
+		}
+		if via == "" {
+			via = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.ChannelID, e.ChatKey, e.UserID, e.Status, granted, expires, via)
+	}
+	_ = w.Flush()
+	return b.String()
+}
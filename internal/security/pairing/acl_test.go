@@ -0,0 +1,78 @@
+package pairing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
+)
+
+func TestEntries(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{Channels: map[string]config.ChannelConfig{
+		"tg": {ACL: map[string]config.ChannelACLConfig{
+			"group:-9": {
+				Allow:  []string{"2", "1"},
+				Block:  []string{"3"},
+				Grants: map[string]config.ACLGrant{"2": {At: at, Via: "cli"}},
+			},
+		}},
+		"lark": {ACL: map[string]config.ChannelACLConfig{"user:5": {Allow: []string{"5"}}}},
+	}}
+
+	got := Entries(cfg, "tg", at)
+	want := []Entry{
+		{ChannelID: "tg", ChatKey: "group:-9", UserID: "1", Status: StatusAllowed, Via: "config"},
+		{ChannelID: "tg", ChatKey: "group:-9", UserID: "2", Status: StatusAllowed, GrantedAt: at, Via: "cli"},
+		{ChannelID: "tg", ChatKey: "group:-9", UserID: "3", Status: StatusBlocked},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Entries = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Entries[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if all := Entries(cfg, "", at); len(all) != 4 || all[0].ChannelID != "lark" {
+		t.Errorf("Entries(all) = %+v", all)
+	}
+}
+
+func TestSettleEntry(t *testing.T) {
+	pairingCh := config.ChannelConfig{Security: config.ChannelSecurityConfig{Policy: consts.SecurityPolicyWelcome}}
+	plainCh := config.ChannelConfig{Security: config.ChannelSecurityConfig{Policy: consts.SecurityPolicySilent}}
+
+	cases := []struct {
+		name  string
+		ch    config.ChannelConfig
+		key   string
+		entry config.ChannelACLConfig
+		kept  bool
+		opens bool
+	}{
+		{"allowed users left", plainCh, "group:1", config.ChannelACLConfig{Allow: []string{"1"}}, true, false},
+		{"owner_present keeps it closed", plainCh, "group:1", config.ChannelACLConfig{OwnerPresent: true}, true, false},
+		{"pairing: drop, users pair again", pairingCh, "group:1", config.ChannelACLConfig{}, false, false},
+		{"pairing: blocked users left in a group", pairingCh, "group:1", config.ChannelACLConfig{Block: []string{"2"}}, false, true},
+		{"private chat with its user blocked", plainCh, "user:2", config.ChannelACLConfig{Block: []string{"2"}}, true, false},
+		{"plain: empty rule would open", plainCh, "user:2", config.ChannelACLConfig{}, false, true},
+	}
+	for _, tc := range cases {
+		ch := tc.ch
+		ch.ACL = map[string]config.ChannelACLConfig{tc.key: {Allow: []string{"x"}}}
+		err := settleEntry(&ch, tc.key, tc.entry)
+		if got := errors.Is(err, ErrOpensChat); got != tc.opens {
+			t.Errorf("%s: settleEntry error = %v, want ErrOpensChat=%v", tc.name, err, tc.opens)
+			continue
+		}
+		if tc.opens {
+			continue
+		}
+		if _, ok := ch.ACL[tc.key]; ok != tc.kept {
+			t.Errorf("%s: entry kept = %v, want %v", tc.name, ok, tc.kept)
+		}
+	}
+}
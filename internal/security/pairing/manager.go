@@ -8,7 +8,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/bytedance/gg/gslice"
 	"github.com/google/uuid"
 
 	"github.com/tgifai/friday/internal/config"
@@ -23,7 +22,6 @@ const (
 
 	defaultPairingWelcomeWindowSec = 300
 	defaultPairingMaxResp          = 3
-	maxPairingPersistCASRetries    = 3
 	defaultPairingCodeTTL          = 5 * time.Minute
 	defaultPairingCodeLen          = 8
 
@@ -62,6 +60,11 @@ type Manager struct {
 
 	challenges map[string]Challenge
 	windows    map[string][]time.Time
+	requests   map[string]Request   // keyed by Request.ReqID, awaiting the owner
+	denied     map[string]time.Time // principal → end of the re-request cooldown
+
+	// requestsPath persists requests and denied; see RequestsPath.
+	requestsPath string
 }
 
 func newManager(chanId string) *Manager {
@@ -69,6 +72,8 @@ func newManager(chanId string) *Manager {
 		chanId:     strings.TrimSpace(chanId),
 		challenges: make(map[string]Challenge, 16),
 		windows:    make(map[string][]time.Time, 16),
+		requests:   make(map[string]Request, 4),
+		denied:     make(map[string]time.Time, 4),
 	}
 }
 
@@ -176,6 +181,8 @@ func (m *Manager) VerifyCode(principalKey string, code string) (Challenge, error
 
 	delete(m.challenges, principalKey)
 	delete(m.windows, principalKey)
+	// A request that fails to drop only lingers until it expires.
+	_ = m.dropRequestsLocked(principalKey)
 	return challenge, nil
 }
 
@@ -196,100 +203,6 @@ func (m *Manager) GetActiveChallenge(principalKey string) (Challenge, bool) {
 	return challenge, true
 }
 
-func (m *Manager) IsAllowed(chatKey string, userID string) (bool, error) {
-	chatKey = strings.TrimSpace(chatKey)
-	userID = strings.TrimSpace(userID)
-	if chatKey == "" || userID == "" {
-		return false, errors.New("chatKey and userID cannot be empty")
-	}
-
-	cfg, err := config.Get()
-	if err != nil {
-		return false, err
-	}
-	chCfg, ok := cfg.Channels[m.chanId]
-	if !ok {
-		return false, fmt.Errorf("channel not found: %s", m.chanId)
-	}
-
-	entry, exists := chCfg.ACL[chatKey]
-	if !exists {
-		return false, nil
-	}
-	if gslice.Contains(entry.Block, userID) {
-		return false, nil
-	}
-	if len(entry.Allow) == 0 {
-		return true, nil
-	}
-	return gslice.Contains(entry.Allow, userID), nil
-}
-
-func (m *Manager) GrantACL(chatKey string, userID string) (bool, error) {
-	chatKey = strings.TrimSpace(chatKey)
-	userID = strings.TrimSpace(userID)
-	if chatKey == "" || userID == "" {
-		return false, errors.New("chatKey and userID cannot be empty")
-	}
-
-	for attempt := 0; attempt < maxPairingPersistCASRetries; attempt++ {
-		cfg, err := config.Get()
-		if err != nil {
-			return false, err
-		}
-		expectedHash, err := config.Hash()
-		if err != nil {
-			return false, err
-		}
-		chCfg, ok := cfg.Channels[m.chanId]
-		if !ok {
-			return false, fmt.Errorf("channel not found: %s", m.chanId)
-		}
-
-		if chCfg.ACL == nil {
-			chCfg.ACL = make(map[string]config.ChannelACLConfig)
-		}
-		entry, entryOK := chCfg.ACL[chatKey]
-		if !entryOK {
-			chCfg.ACL[chatKey] = config.ChannelACLConfig{Allow: []string{userID}}
-		} else {
-			changed := false
-			if !gslice.Contains(entry.Allow, userID) {
-				entry.Allow = append(entry.Allow, userID)
-				changed = true
-			}
-			if gslice.Contains(entry.Block, userID) {
-				filtered := make([]string, 0, len(entry.Block))
-				for _, id := range entry.Block {
-					if id != userID {
-						filtered = append(filtered, id)
-					}
-				}
-				entry.Block = filtered
-				changed = true
-			}
-			if !changed {
-				return false, nil
-			}
-			chCfg.ACL[chatKey] = entry
-		}
-		cfg.Channels[m.chanId] = chCfg
-
-		if err := config.ApplyWithCAS("config", cfg, expectedHash); err != nil {
-			if errors.Is(err, config.ErrConfigConflict) {
-				continue
-			}
-			return false, fmt.Errorf("apply config update: %w", err)
-		}
-		if err := config.Save(); err != nil {
-			return false, fmt.Errorf("save config update: %w", err)
-		}
-		return true, nil
-	}
-
-	return false, fmt.Errorf("persist pairing allowlist conflict after %d retries", maxPairingPersistCASRetries)
-}
-
 func (m *Manager) loadSecurityConfigLocked() config.ChannelSecurityConfig {
 	silent := config.ChannelSecurityConfig{
 		Policy:        securityPolicySilent,
@@ -317,6 +230,16 @@ func (m *Manager) compactStateLocked(now time.Time, welcomeWindowSec int) {
 			delete(m.challenges, key)
 		}
 	}
+	for id, req := range m.requests {
+		if !now.Before(req.ExpiresAt) {
+			delete(m.requests, id)
+		}
+	}
+	for key, until := range m.denied {
+		if !now.Before(until) {
+			delete(m.denied, key)
+		}
+	}
 
 	window := time.Duration(welcomeWindowSec) * time.Second
 	if window <= 0 {
@@ -41,6 +41,9 @@ func (r *managerRegistry) Get(channelKey string) *Manager {
 	channelID := parsePairingChannelID(channelKey)
 	silentManager := newManager(channelID)
 	silentManager.chanId = channelID
+	if channelID != "" {
+		silentManager.requestsPath = RequestsPath(channelID)
+	}
 	r.managers[channelKey] = silentManager
 	return silentManager
 }
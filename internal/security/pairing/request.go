@@ -0,0 +1,306 @@
+package pairing
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
+)
+
+const (
+	defaultPairingRequestTTL = 24 * time.Hour
+	defaultPairingDenyTTL    = 24 * time.Hour
+	minRequestPrefix         = 6
+
+	// ApprovalWelcomeTemplate is sent to an unknown user when their pairing
+	// request has been recorded for the owner to approve.
+	ApprovalWelcomeTemplate = `👋 Welcome to Friday!
+
+This chat is not yet paired. Your request has been sent to the owner for approval; you'll get a message here once they decide.
+
+If you were given a pairing code, you can also reply with:
+/pair <YOUR_CODE>
+
+📌 Chat ID: {chatId}
+👤 User ID: {userId}
+🔑 Request: {reqId}`
+)
+
+// Request is a pairing request awaiting the owner's decision.
+type Request struct {
+	ReqID     string    `json:"req_id"`
+	Principal string    `json:"principal"`
+	ChatKey   string    `json:"chat_key"`
+	ChatID    string    `json:"chat_id"`
+	ThreadID  string    `json:"thread_id,omitempty"`
+	UserID    string    `json:"user_id"`
+	UserName  string    `json:"user_name,omitempty"`
+	Preview   string    `json:"preview,omitempty"` // first message of the requester
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// requestState is the on-disk form of a channel's pending requests, shared
+// by the gateway and the friday pairing CLI.
+type requestState struct {
+	Requests map[string]Request   `json:"requests"`
+	Denied   map[string]time.Time `json:"denied"`
+}
+
+// RequestsPath returns the file holding a channel's pending requests.
+func RequestsPath(channelID string) string {
+	return filepath.Join(consts.FridayHomeDir(), "security", "pairing", channelID+".json")
+}
+
+// SubmitRequest records a request for the owner to decide on. It returns
+// false when the same principal already has a pending request or was denied
+// recently, in which case the owner should not be asked again.
+func (m *Manager) SubmitRequest(req Request) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	req.ReqID = strings.TrimSpace(req.ReqID)
+	req.Principal = strings.TrimSpace(req.Principal)
+	if req.ReqID == "" || req.Principal == "" {
+		return false, errors.New("request id and principal cannot be empty")
+	}
+
+	unlock, err := m.lockRequestsFile()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	if err := m.loadRequestsLocked(); err != nil {
+		return false, err
+	}
+	now := time.Now()
+	m.compactStateLocked(now, defaultPairingWelcomeWindowSec)
+	if _, ok := m.denied[req.Principal]; ok {
+		return false, nil
+	}
+	for _, pending := range m.requests {
+		if pending.Principal == req.Principal {
+			return false, nil
+		}
+	}
+
+	req.CreatedAt = now
+	req.ExpiresAt = now.Add(defaultPairingRequestTTL)
+	m.requests[req.ReqID] = req
+	if err := m.saveRequestsLocked(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ResolveRequest removes a pending request and returns it. Denied
+// principals can't file a new request until the cooldown passes; approved
+// ones have their outstanding pairing challenge cleared.
+func (m *Manager) ResolveRequest(reqID string, approved bool) (Request, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	unlock, err := m.lockRequestsFile()
+	if err != nil {
+		return Request{}, err
+	}
+	defer unlock()
+	if err := m.loadRequestsLocked(); err != nil {
+		return Request{}, err
+	}
+	now := time.Now()
+	m.compactStateLocked(now, defaultPairingWelcomeWindowSec)
+	req, ok := m.findRequestLocked(strings.TrimSpace(reqID))
+	if !ok {
+		return Request{}, errors.New("pairing request not found or expired")
+	}
+	delete(m.requests, req.ReqID)
+	if approved {
+		delete(m.challenges, req.Principal)
+		delete(m.windows, req.Principal)
+	} else {
+		m.denied[req.Principal] = now.Add(defaultPairingDenyTTL)
+	}
+	if err := m.saveRequestsLocked(); err != nil {
+		return Request{}, err
+	}
+	return req, nil
+}
+
+// WithdrawRequest removes a pending request without a decision, as when it
+// could not be delivered to the owner, so the requester can file it again.
+func (m *Manager) WithdrawRequest(reqID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	unlock, err := m.lockRequestsFile()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := m.loadRequestsLocked(); err != nil {
+		return err
+	}
+	if _, ok := m.requests[reqID]; !ok {
+		return nil
+	}
+	delete(m.requests, reqID)
+	return m.saveRequestsLocked()
+}
+
+// PendingRequests lists the requests awaiting a decision, oldest first.
+func (m *Manager) PendingRequests() ([]Request, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.loadRequestsLocked(); err != nil {
+		return nil, err
+	}
+	m.compactStateLocked(time.Now(), defaultPairingWelcomeWindowSec)
+	out := make([]Request, 0, len(m.requests))
+	for _, req := range m.requests {
+		out = append(out, req)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// findRequestLocked looks a request up by ID or by an unambiguous ID prefix
+// of at least minRequestPrefix characters, so owners needn't type full IDs.
+func (m *Manager) findRequestLocked(reqID string) (Request, bool) {
+	if req, ok := m.requests[reqID]; ok {
+		return req, true
+	}
+	if len(reqID) < minRequestPrefix {
+		return Request{}, false
+	}
+	var found Request
+	matches := 0
+	for id, req := range m.requests {
+		if strings.HasPrefix(id, reqID) {
+			found = req
+			matches++
+		}
+	}
+	return found, matches == 1
+}
+
+func (m *Manager) dropRequestsLocked(principal string) error {
+	unlock, err := m.lockRequestsFile()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := m.loadRequestsLocked(); err != nil {
+		return err
+	}
+	dropped := false
+	for id, req := range m.requests {
+		if req.Principal == principal {
+			delete(m.requests, id)
+			dropped = true
+		}
+	}
+	if !dropped {
+		return nil
+	}
+	return m.saveRequestsLocked()
+}
+
+// lockRequestsFile takes the cross-process lock on the requests file, so a
+// load and save by the gateway and one by the CLI can't interleave.
+func (m *Manager) lockRequestsFile() (func(), error) {
+	if m.requestsPath == "" {
+		return func() {}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(m.requestsPath), 0o700); err != nil {
+		return nil, fmt.Errorf("create pairing requests directory: %w", err)
+	}
+	unlock, err := config.LockFile(m.requestsPath)
+	if err != nil {
+		return nil, fmt.Errorf("lock pairing requests: %w", err)
+	}
+	return unlock, nil
+}
+
+// loadRequestsLocked refreshes the pending requests from disk, where another
+// process may have resolved them. Managers without a path keep them in
+// memory only.
+func (m *Manager) loadRequestsLocked() error {
+	if m.requestsPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(m.requestsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			clear(m.requests)
+			clear(m.denied)
+			return nil
+		}
+		return fmt.Errorf("read pairing requests: %w", err)
+	}
+	var state requestState
+	if len(data) > 0 {
+		if err := sonic.Unmarshal(data, &state); err != nil {
+			return fmt.Errorf("unmarshal pairing requests: %w", err)
+		}
+	}
+	m.requests = state.Requests
+	m.denied = state.Denied
+	if m.requests == nil {
+		m.requests = make(map[string]Request, 4)
+	}
+	if m.denied == nil {
+		m.denied = make(map[string]time.Time, 4)
+	}
+	return nil
+}
+
+// saveRequestsLocked writes the pending requests atomically (tmp + rename).
+func (m *Manager) saveRequestsLocked() error {
+	if m.requestsPath == "" {
+		return nil
+	}
+	data, err := sonic.Marshal(requestState{Requests: m.requests, Denied: m.denied})
+	if err != nil {
+		return fmt.Errorf("marshal pairing requests: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.requestsPath), 0o700); err != nil {
+		return fmt.Errorf("create pairing requests directory: %w", err)
+	}
+	tmp := m.requestsPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write pairing requests: %w", err)
+	}
+	if err := os.Rename(tmp, m.requestsPath); err != nil {
+		return fmt.Errorf("rename pairing requests: %w", err)
+	}
+	return nil
+}
+
+// FormatRequest renders a pairing request for the owner, with the commands
+// that approve or deny it.
+func FormatRequest(req Request) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔐 Pairing request %s\n", req.ReqID)
+	fmt.Fprintf(&b, "Chat: %s\n", req.ChatKey)
+	if req.UserName != "" {
+		fmt.Fprintf(&b, "User: %s (%s)\n", req.UserID, req.UserName)
+	} else {
+		fmt.Fprintf(&b, "User: %s\n", req.UserID)
+	}
+	if req.Preview != "" {
+		fmt.Fprintf(&b, "Message: %s\n", req.Preview)
+	}
+	fmt.Fprintf(&b, "\nReply /approve %s or /deny %s", req.ReqID, req.ReqID)
+	b.WriteString("\nAdd a duration after the ID, e.g. 24h, for time-limited access.")
+	return b.String()
+}
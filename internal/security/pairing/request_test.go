@@ -0,0 +1,90 @@
+package pairing
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestRequests_SubmitResolve(t *testing.T) {
+	m := newManager("tg")
+	req := Request{ReqID: "1b9d6bcb-0001", Principal: "telegram:tg:user:1:1", ChatKey: "user:1", UserID: "1"}
+
+	if ok, err := m.SubmitRequest(req); err != nil || !ok {
+		t.Fatalf("SubmitRequest = %v, %v; want true", ok, err)
+	}
+	// A second challenge for the same principal must not reach the owner again.
+	dup := req
+	dup.ReqID = "7f3e2a10-0002"
+	if ok, _ := m.SubmitRequest(dup); ok {
+		t.Fatal("duplicate request for the same principal was accepted")
+	}
+	if pending, err := m.PendingRequests(); err != nil || len(pending) != 1 {
+		t.Fatalf("PendingRequests = %d, %v; want 1", len(pending), err)
+	}
+
+	if _, err := m.ResolveRequest("1b9d", true); err == nil {
+		t.Fatal("ResolveRequest accepted a prefix shorter than the minimum")
+	}
+	got, err := m.ResolveRequest("1b9d6b", false)
+	if err != nil || got.UserID != "1" {
+		t.Fatalf("ResolveRequest(prefix) = %+v, %v", got, err)
+	}
+	if _, err := m.ResolveRequest(req.ReqID, true); err == nil {
+		t.Fatal("a resolved request could be resolved again")
+	}
+
+	// Denied principals are not forwarded again during the cooldown.
+	if ok, _ := m.SubmitRequest(dup); ok {
+		t.Fatal("request accepted from a recently denied principal")
+	}
+}
+
+func TestRequests_SharedThroughFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tg.json")
+	gateway, cli := newManager("tg"), newManager("tg")
+	gateway.requestsPath, cli.requestsPath = path, path
+
+	req := Request{ReqID: "5c0ffee0-0001", Principal: "telegram:tg:group:-9:7", ChatKey: "group:-9", UserID: "7"}
+	if ok, err := gateway.SubmitRequest(req); err != nil || !ok {
+		t.Fatalf("SubmitRequest = %v, %v; want true", ok, err)
+	}
+
+	// Another process sees the request and resolves it.
+	pending, err := cli.PendingRequests()
+	if err != nil || len(pending) != 1 || pending[0].ChatKey != "group:-9" {
+		t.Fatalf("PendingRequests from another manager = %+v, %v", pending, err)
+	}
+	if _, err := cli.ResolveRequest("5c0ffee0", true); err != nil {
+		t.Fatalf("ResolveRequest = %v", err)
+	}
+	if pending, _ := gateway.PendingRequests(); len(pending) != 0 {
+		t.Fatalf("request resolved elsewhere is still pending: %+v", pending)
+	}
+}
+
+func TestRequests_ConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tg.json")
+	const writers = 8
+	var wg sync.WaitGroup
+	for i := range writers {
+		m := newManager("tg")
+		m.requestsPath = path
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := Request{ReqID: fmt.Sprintf("req-%d", i), Principal: fmt.Sprintf("telegram:tg:user:%d:%d", i, i), UserID: fmt.Sprint(i)}
+			if ok, err := m.SubmitRequest(req); err != nil || !ok {
+				t.Errorf("SubmitRequest(%d) = %v, %v", i, ok, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	m := newManager("tg")
+	m.requestsPath = path
+	if pending, err := m.PendingRequests(); err != nil || len(pending) != writers {
+		t.Fatalf("%d requests pending, want %d; a concurrent save overwrote the others (%v)", len(pending), writers, err)
+	}
+}
@@ -0,0 +1,195 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/tgifai/friday/internal/pkg/logs"
+)
+
+// Rule is a token bucket holding up to Burst tokens that refills at
+// PerMinute tokens per minute.
+type Rule struct {
+	PerMinute float64
+	Burst     int
+}
+
+// Enabled reports whether the rule limits anything.
+func (r Rule) Enabled() bool {
+	return r.PerMinute > 0 && r.Burst > 0
+}
+
+// Check pairs a bucket key with the rule that governs it.
+type Check struct {
+	Key  string
+	Rule Rule
+}
+
+type bucket struct {
+	Tokens  float64   `json:"tokens"`
+	Updated time.Time `json:"updated"`
+}
+
+// refill tops the bucket up for the time elapsed since its last update.
+func (b *bucket) refill(r Rule, now time.Time) {
+	if elapsed := now.Sub(b.Updated); elapsed > 0 {
+		b.Tokens = math.Min(float64(r.Burst), b.Tokens+elapsed.Minutes()*r.PerMinute)
+	}
+	b.Updated = now
+}
+
+// Limiter keeps token buckets keyed by caller-defined strings. Bucket state
+// is persisted to a JSON file so limits survive restarts.
+type Limiter struct {
+	path   string
+	saveMu sync.Mutex // serializes writes of the state file
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	dirty   bool
+}
+
+// New creates a Limiter backed by the given file path. An empty path keeps
+// state in memory only.
+func New(path string) *Limiter {
+	return &Limiter{
+		path:    path,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes one token from every bucket in checks. When any bucket is
+// empty nothing is taken, and the key of that bucket is returned with the
+// time until it holds a token again. Disabled rules are skipped.
+func (l *Limiter) Allow(checks []Check, now time.Time) (ok bool, limitedKey string, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, c := range checks {
+		if !c.Rule.Enabled() {
+			continue
+		}
+		b := l.bucketLocked(c, now)
+		if b.Tokens < 1 {
+			wait := time.Duration((1 - b.Tokens) / c.Rule.PerMinute * float64(time.Minute))
+			return false, c.Key, wait
+		}
+	}
+	for _, c := range checks {
+		if c.Rule.Enabled() {
+			l.buckets[c.Key].Tokens--
+		}
+	}
+	l.dirty = true
+	return true, "", 0
+}
+
+func (l *Limiter) bucketLocked(c Check, now time.Time) *bucket {
+	b, ok := l.buckets[c.Key]
+	if !ok {
+		b = &bucket{Tokens: float64(c.Rule.Burst), Updated: now}
+		l.buckets[c.Key] = b
+		return b
+	}
+	b.refill(c.Rule, now)
+	return b
+}
+
+// Load reads persisted buckets. It is safe to call on a missing file.
+func (l *Limiter) Load() error {
+	if l.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read rate limit state: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	buckets := make(map[string]*bucket)
+	if err := sonic.Unmarshal(data, &buckets); err != nil {
+		return fmt.Errorf("unmarshal rate limit state: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buckets = buckets
+	return nil
+}
+
+// Save writes the buckets to disk atomically (tmp + rename) if they changed
+// since the last save.
+func (l *Limiter) Save() error {
+	if l.path == "" {
+		return nil
+	}
+	l.saveMu.Lock()
+	defer l.saveMu.Unlock()
+
+	l.mu.Lock()
+	if !l.dirty {
+		l.mu.Unlock()
+		return nil
+	}
+	data, err := sonic.Marshal(l.buckets)
+	l.dirty = false
+	l.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("marshal rate limit state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("create rate limit state directory: %w", err)
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write rate limit state: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("rename rate limit state: %w", err)
+	}
+	return nil
+}
+
+// Prune drops buckets untouched for longer than idle; such buckets have
+// refilled completely and carry no state worth keeping.
+func (l *Limiter) Prune(idle time.Duration, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, b := range l.buckets {
+		if now.Sub(b.Updated) > idle {
+			delete(l.buckets, key)
+			l.dirty = true
+		}
+	}
+}
+
+// Run periodically prunes idle buckets and persists state until ctx is
+// cancelled. Callers should Save once more on shutdown.
+func (l *Limiter) Run(ctx context.Context, interval, idle time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.Prune(idle, now)
+			if err := l.Save(); err != nil {
+				logs.CtxWarn(ctx, "[ratelimit] save state: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLimiter_BurstAndRefill(t *testing.T) {
+	l := New("")
+	now := time.Now()
+	checks := []Check{{Key: "user:1", Rule: Rule{PerMinute: 6, Burst: 2}}}
+
+	for i := 0; i < 2; i++ {
+		if ok, _, _ := l.Allow(checks, now); !ok {
+			t.Fatalf("call %d denied within burst", i)
+		}
+	}
+	ok, key, wait := l.Allow(checks, now)
+	if ok || key != "user:1" {
+		t.Fatalf("Allow = %v, %q; want denied by user:1", ok, key)
+	}
+	if wait != 10*time.Second {
+		t.Errorf("retryAfter = %s, want 10s", wait)
+	}
+	if ok, _, _ := l.Allow(checks, now.Add(10*time.Second)); !ok {
+		t.Error("denied after refill")
+	}
+}
+
+func TestLimiter_DeniedCheckTakesNothing(t *testing.T) {
+	l := New("")
+	now := time.Now()
+	chat := Check{Key: "chat", Rule: Rule{PerMinute: 60, Burst: 10}}
+	user := Check{Key: "user", Rule: Rule{PerMinute: 1, Burst: 1}}
+
+	l.Allow([]Check{chat, user}, now)
+	if ok, key, _ := l.Allow([]Check{chat, user}, now); ok || key != "user" {
+		t.Fatalf("Allow = %v, %q; want denied by user", ok, key)
+	}
+	if got := l.buckets["chat"].Tokens; got != 9 {
+		t.Errorf("chat tokens = %v, want 9", got)
+	}
+}
+
+func TestLimiter_PersistsState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+	now := time.Now()
+	checks := []Check{{Key: "user:1", Rule: Rule{PerMinute: 1, Burst: 1}}}
+
+	l := New(path)
+	l.Allow(checks, now)
+	if err := l.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	restored := New(path)
+	if err := restored.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if ok, _, _ := restored.Allow(checks, now.Add(time.Second)); ok {
+		t.Error("bucket state was not restored")
+	}
+}
@@ -0,0 +1,26 @@
+package redact
+
+import "context"
+
+type turnCtxKey struct{}
+
+// WithTurn attaches t to ctx so that code running within the turn, tools
+// included, scrubs with the turn's placeholders.
+func WithTurn(ctx context.Context, t *Turn) context.Context {
+	return context.WithValue(ctx, turnCtxKey{}, t)
+}
+
+// TurnFrom returns the Turn attached to ctx, or nil.
+func TurnFrom(ctx context.Context) *Turn {
+	t, _ := ctx.Value(turnCtxKey{}).(*Turn)
+	return t
+}
+
+// Scrub scrubs s with the turn in ctx, or with the Default redactor
+// outside a turn.
+func Scrub(ctx context.Context, s string) string {
+	if t := TurnFrom(ctx); t != nil {
+		return t.Scrub(s)
+	}
+	return Default().Scrub(s)
+}
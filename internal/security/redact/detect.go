@@ -0,0 +1,39 @@
+package redact
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// builtinRules detect common credential formats. Provider-specific rules
+// come before the generic ones so placeholders name the kind of key.
+var builtinRules = []rule{
+	{name: "private_key", re: regexp.MustCompile(`-----BEGIN[A-Z ]*PRIVATE KEY-----[\s\S]*?-----END[A-Z ]*PRIVATE KEY-----`)},
+	{name: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{name: "anthropic_key", re: regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+	{name: "openai_key", re: regexp.MustCompile(`\bsk-(?:proj-|svcacct-|admin-)?[A-Za-z0-9_-]{20,}`)},
+	{name: "github_token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})`)},
+	{name: "aws_access_key", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{name: "slack_token", re: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{name: "google_api_key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{name: "telegram_bot_token", re: regexp.MustCompile(`\b\d{8,10}:[A-Za-z0-9_-]{35}\b`)},
+	{name: "bearer", re: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/=-]{16,})`)},
+	{
+		// KEY=value and "key": "value" pairs under secret-looking names,
+		// as in `env` output, .env files and JSON. Only values mixing
+		// letters and digits are taken, so max_tokens=4096 stays.
+		name: "assignment",
+		re:   regexp.MustCompile(`(?i)[a-z0-9_.-]*(?:api[_-]?key|secret|token|passw(?:or)?d|access[_-]?key|private[_-]?key)[a-z0-9_.-]*\\?["']?\s*[:=]\s*\\?["']?([^\s"'\\&,;<>\[\]{}()]{8,})`),
+		keep: func(s string) bool { return !hasLetterAndDigit(s) },
+	},
+}
+
+// jsonStringContent returns s encoded for use inside a JSON string. Text
+// that is already valid there, such as a value cut out of JSON, is kept.
+func jsonStringContent(s string) string {
+	if json.Valid([]byte(`"` + s + `"`)) {
+		return s
+	}
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
@@ -0,0 +1,256 @@
+// Package redact scrubs secrets from text before it leaves the agent: chat
+// messages, log lines and persisted sessions.
+//
+// Secrets are found by their known values (credentials from the config and
+// the secrets store), by built-in detectors for common key formats and by
+// user-defined patterns. Each is replaced with a placeholder such as
+// "[REDACTED:openai_key#1]". Within one turn a Turn remembers which secret
+// each placeholder stands for, so tool calls the model makes with a
+// placeholder still run with the real value. The mapping lives in memory
+// only and is dropped with the turn.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+)
+
+// minValueLen is the shortest known value redacted; shorter ones would
+// match ordinary text.
+const minValueLen = 8
+
+const placeholderPrefix = "[REDACTED:"
+
+// Pattern is a named regular expression. When it has a capture group only
+// the first group is redacted, so "token=..." keeps its key.
+type Pattern struct {
+	Name    string
+	Pattern string
+}
+
+// Options configures a Redactor.
+type Options struct {
+	// Values maps a name to a known secret value, e.g. "openai-main" to
+	// the provider's API key. Values shorter than 8 bytes are ignored.
+	Values map[string]string
+	// Skip lists built-in detectors to turn off.
+	Skip []string
+	// Patterns are user-defined detectors, run after the built-in ones.
+	Patterns []Pattern
+}
+
+type rule struct {
+	name string
+	re   *regexp.Regexp
+	// keep reports whether a match is left alone, e.g. numbers caught by
+	// the generic assignment detector.
+	keep func(string) bool
+}
+
+type value struct {
+	name   string
+	secret string
+}
+
+// Redactor finds secrets in text. It is immutable and safe for concurrent
+// use; a nil Redactor leaves text unchanged.
+type Redactor struct {
+	values []value
+	rules  []rule
+}
+
+// New builds a Redactor from the built-in detectors and opts.
+func New(opts Options) (*Redactor, error) {
+	for _, name := range opts.Skip {
+		if !slices.ContainsFunc(builtinRules, func(r rule) bool { return r.name == name }) {
+			return nil, fmt.Errorf("unknown detector: %s", name)
+		}
+	}
+
+	r := &Redactor{}
+	for name, secret := range opts.Values {
+		if len(secret) >= minValueLen {
+			r.values = append(r.values, value{name: name, secret: secret})
+		}
+	}
+	// Longest first, so a value containing another is replaced whole.
+	slices.SortFunc(r.values, func(a, b value) int {
+		if len(a.secret) != len(b.secret) {
+			return len(b.secret) - len(a.secret)
+		}
+		return strings.Compare(a.name, b.name)
+	})
+
+	for _, one := range builtinRules {
+		if !slices.Contains(opts.Skip, one.name) {
+			r.rules = append(r.rules, one)
+		}
+	}
+	for _, p := range opts.Patterns {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %s: %w", p.Name, err)
+		}
+		r.rules = append(r.rules, rule{name: p.Name, re: re})
+	}
+	return r, nil
+}
+
+// Scrub replaces the secrets in s with placeholders that carry no number
+// and cannot be restored. It suits text that outlives the turn, such as
+// log lines.
+func (r *Redactor) Scrub(s string) string {
+	return r.scrub(s, func(name, _ string) string {
+		return placeholderPrefix + name + "]"
+	})
+}
+
+func (r *Redactor) scrub(s string, replace func(name, secret string) string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, v := range r.values {
+		if strings.Contains(s, v.secret) {
+			s = strings.ReplaceAll(s, v.secret, replace(v.name, v.secret))
+		}
+	}
+	for _, one := range r.rules {
+		s = replaceRule(s, one, replace)
+	}
+	return s
+}
+
+// replaceRule replaces the matches of one rule, or their first capture
+// group when the rule has one.
+func replaceRule(s string, one rule, replace func(name, secret string) string) string {
+	idx := one.re.FindAllStringSubmatchIndex(s, -1)
+	if len(idx) == 0 {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range idx {
+		start, end := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		secret := s[start:end]
+		if secret == "" || strings.HasPrefix(secret, placeholderPrefix) || (one.keep != nil && one.keep(secret)) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(replace(one.name, secret))
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// NewTurn starts the reversible redaction of one turn.
+func (r *Redactor) NewTurn() *Turn {
+	return &Turn{r: r}
+}
+
+// Turn redacts the text of one turn. The same secret gets the same
+// placeholder throughout the turn, and Restore maps placeholders back. A
+// nil Turn leaves text unchanged.
+type Turn struct {
+	r *Redactor
+
+	mu       sync.Mutex
+	bySecret map[string]string // secret -> placeholder
+	byHolder map[string]string // placeholder -> secret
+	counts   map[string]int    // detector name -> placeholders issued
+}
+
+// Scrub replaces the secrets in s with numbered placeholders.
+func (t *Turn) Scrub(s string) string {
+	if t == nil {
+		return s
+	}
+	return t.r.scrub(s, t.placeholder)
+}
+
+func (t *Turn) placeholder(name, secret string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if holder, ok := t.bySecret[secret]; ok {
+		return holder
+	}
+	if t.bySecret == nil {
+		t.bySecret = make(map[string]string)
+		t.byHolder = make(map[string]string)
+		t.counts = make(map[string]int)
+	}
+	t.counts[name]++
+	holder := fmt.Sprintf("%s%s#%d]", placeholderPrefix, name, t.counts[name])
+	t.bySecret[secret] = holder
+	t.byHolder[holder] = secret
+	return holder
+}
+
+var placeholderPattern = regexp.MustCompile(`\[REDACTED:[^\]\s#]+#\d+\]`)
+
+// Restore puts back the secrets behind the placeholders this turn issued.
+// Placeholders from other turns are left as they are.
+func (t *Turn) Restore(s string) string {
+	return t.restore(s, func(secret string) string { return secret })
+}
+
+// RestoreJSON is Restore for JSON text, such as tool-call arguments.
+// Secrets that are not valid inside a JSON string, like a private key
+// taken from plain text, are escaped.
+func (t *Turn) RestoreJSON(s string) string {
+	return t.restore(s, jsonStringContent)
+}
+
+func (t *Turn) restore(s string, encode func(string) string) string {
+	if t == nil || !strings.Contains(s, placeholderPrefix) {
+		return s
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return placeholderPattern.ReplaceAllStringFunc(s, func(holder string) string {
+		if secret, ok := t.byHolder[holder]; ok {
+			return encode(secret)
+		}
+		return holder
+	})
+}
+
+var defaultRedactor atomic.Pointer[Redactor]
+
+func init() {
+	r, _ := New(Options{})
+	defaultRedactor.Store(r)
+}
+
+// Default returns the process-wide Redactor, nil when redaction is off.
+// Until SetDefault is called it has the built-in detectors only.
+func Default() *Redactor {
+	return defaultRedactor.Load()
+}
+
+// SetDefault replaces the process-wide Redactor; nil turns redaction off.
+func SetDefault(r *Redactor) {
+	defaultRedactor.Store(r)
+}
+
+// hasLetterAndDigit reports whether s mixes letters and digits, as
+// generated credentials do.
+func hasLetterAndDigit(s string) bool {
+	var letter, digit bool
+	for _, c := range s {
+		letter = letter || unicode.IsLetter(c)
+		digit = digit || unicode.IsDigit(c)
+	}
+	return letter && digit
+}
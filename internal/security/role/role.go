@@ -0,0 +1,85 @@
+package role
+
+import (
+	"sort"
+
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
+)
+
+var ranks = map[string]int{
+	consts.RoleGuest:  1,
+	consts.RoleMember: 2,
+	consts.RoleAdmin:  3,
+	consts.RoleOwner:  4,
+}
+
+// Allows reports whether a user holding role have may use something that
+// requires role need. An empty need is open to everyone; an empty have is
+// the system itself (cron jobs, heartbeats) and may use everything.
+func Allows(have, need string) bool {
+	if need == "" || have == "" {
+		return true
+	}
+	return ranks[have] >= ranks[need]
+}
+
+// Listed reports whether have appears in roles. The system role (empty)
+// is always listed.
+func Listed(have string, roles []string) bool {
+	if have == "" {
+		return true
+	}
+	for _, r := range roles {
+		if have == r {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the role of userID in a channel. Users without an entry
+// get the channel default; when no default is set, a channel without a
+// roles section keeps everyone as owner, as before roles existed, and any
+// other channel defaults to member. A channel with a paired role counts as
+// having roles even before anyone paired, so the first pairing does not
+// demote the users who were owners until then; validation makes such a
+// channel name an owner or a default.
+func Resolve(cfg config.ChannelRolesConfig, userID string) string {
+	if r, ok := cfg.Users[userID]; ok && r != "" {
+		return r
+	}
+	switch {
+	case cfg.Default != "":
+		return cfg.Default
+	case cfg.Paired == "" && len(cfg.Users) == 0:
+		return consts.RoleOwner
+	default:
+		return consts.RoleMember
+	}
+}
+
+// ToolAllowed reports whether have may use the named tool under an agent's
+// tool_roles. Tools without an entry fall back to the "*" entry; tools
+// covered by neither are open to every role.
+func ToolAllowed(toolRoles map[string][]string, name, have string) bool {
+	roles, ok := toolRoles[name]
+	if !ok {
+		if roles, ok = toolRoles["*"]; !ok {
+			return true
+		}
+	}
+	return Listed(have, roles)
+}
+
+// Owners lists the users explicitly assigned the owner role, sorted.
+func Owners(cfg config.ChannelRolesConfig) []string {
+	var out []string
+	for userID, r := range cfg.Users {
+		if r == consts.RoleOwner {
+			out = append(out, userID)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,81 @@
+package role
+
+import (
+	"testing"
+
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
+)
+
+func TestAllows(t *testing.T) {
+	cases := []struct {
+		have, need string
+		want       bool
+	}{
+		{consts.RoleOwner, consts.RoleAdmin, true},
+		{consts.RoleAdmin, consts.RoleAdmin, true},
+		{consts.RoleMember, consts.RoleAdmin, false},
+		{consts.RoleGuest, "", true},
+		{"", consts.RoleOwner, true}, // system
+	}
+	for _, tc := range cases {
+		if got := Allows(tc.have, tc.need); got != tc.want {
+			t.Errorf("Allows(%q, %q) = %v, want %v", tc.have, tc.need, got, tc.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	if got := Resolve(config.ChannelRolesConfig{}, "u1"); got != consts.RoleOwner {
+		t.Errorf("no roles configured: got %q, want owner", got)
+	}
+	cfg := config.ChannelRolesConfig{Users: map[string]string{"u1": consts.RoleAdmin}}
+	if got := Resolve(cfg, "u1"); got != consts.RoleAdmin {
+		t.Errorf("listed user: got %q, want admin", got)
+	}
+	if got := Resolve(cfg, "u2"); got != consts.RoleMember {
+		t.Errorf("unlisted user: got %q, want member", got)
+	}
+	cfg.Default = consts.RoleGuest
+	if got := Resolve(cfg, "u2"); got != consts.RoleGuest {
+		t.Errorf("unlisted user with default: got %q, want guest", got)
+	}
+
+	// A paired role does not leave everyone owner until the first pairing.
+	paired := config.ChannelRolesConfig{Paired: consts.RoleMember}
+	if got := Resolve(paired, "u1"); got != consts.RoleMember {
+		t.Errorf("unlisted user with a paired role: got %q, want member", got)
+	}
+	if err := paired.Validate(); err == nil {
+		t.Error("paired role without an owner or default passed validation")
+	}
+	paired.Users = map[string]string{"op": consts.RoleOwner}
+	if err := paired.Validate(); err != nil {
+		t.Errorf("paired role with an owner: %v", err)
+	}
+}
+
+func TestToolAllowed(t *testing.T) {
+	toolRoles := map[string][]string{
+		"exec": {consts.RoleOwner},
+		"*":    {consts.RoleOwner, consts.RoleAdmin, consts.RoleMember},
+	}
+	cases := []struct {
+		name, have string
+		want       bool
+	}{
+		{"exec", consts.RoleOwner, true},
+		{"exec", consts.RoleAdmin, false},
+		{"web_fetch", consts.RoleMember, true},
+		{"web_fetch", consts.RoleGuest, false},
+		{"exec", "", true},
+	}
+	for _, tc := range cases {
+		if got := ToolAllowed(toolRoles, tc.name, tc.have); got != tc.want {
+			t.Errorf("ToolAllowed(%s, %q) = %v, want %v", tc.name, tc.have, got, tc.want)
+		}
+	}
+	if !ToolAllowed(nil, "exec", consts.RoleGuest) {
+		t.Error("tools without tool_roles should be open")
+	}
+}
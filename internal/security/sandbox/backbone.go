@@ -9,6 +9,7 @@ import (
 const (
 	BackboneLocal   = "local"
 	BackboneGoJudge = "go_judge"
+	BackboneNative  = "native"
 )
 
 type BackboneBuilder func(workspace string, cfg SandboxConfig) (Executor, error)
@@ -43,6 +44,14 @@ func RegisterBackbone(name string, builder BackboneBuilder) error {
 	return nil
 }
 
+// HasBackbone reports whether a backbone is registered under name.
+func HasBackbone(name string) bool {
+	backboneMu.RLock()
+	defer backboneMu.RUnlock()
+	_, ok := backboneBuilders[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
 func NewExecutorForTool(workspace string, cfg SandboxConfig, toolName string) (Executor, bool, error) {
 	if !cfg.Enable || !cfg.AppliesToTool(toolName) {
 		return nil, false, nil
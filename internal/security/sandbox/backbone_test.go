@@ -66,3 +66,66 @@ func TestNewExecutorForToolUnsupportedBackbone(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestSandboxConfigValidate(t *testing.T) {
+	cfg := SandboxConfig{Enable: true, ApplyToTools: []string{" EXEC "}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Runtime != BackboneLocal || cfg.ApplyToTools[0] != "exec" {
+		t.Fatalf("config not normalized: %+v", cfg)
+	}
+
+	goJudge := SandboxConfig{Enable: true, Runtime: BackboneGoJudge, ApplyToTools: []string{"exec"}, GoJudge: GoJudgeConfig{Endpoint: "http://127.0.0.1:5050"}}
+	if err := goJudge.Validate(); err != nil {
+		t.Fatalf("go_judge for exec only: %v", err)
+	}
+
+	for _, bad := range []SandboxConfig{
+		{Runtime: "unknown"},
+		{ApplyToTools: []string{"web_fetch"}},
+		{Enable: true, Runtime: BackboneGoJudge},
+		{Enable: true, Runtime: BackboneGoJudge, GoJudge: GoJudgeConfig{Endpoint: "http://127.0.0.1:5050"}},
+		{Enable: true, Runtime: BackboneGoJudge, ApplyToTools: []string{"exec", "mcp"}, GoJudge: GoJudgeConfig{Endpoint: "http://127.0.0.1:5050"}},
+		{Enable: true, Runtime: BackboneNative, Native: NativeConfig{ProcLimit: -1}},
+		{Enable: true, Runtime: BackboneNative, Native: NativeConfig{ReadOnlyPaths: []string{"opt"}}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", bad)
+		}
+	}
+}
+
+func TestAppliesToToolDefaultsToAllTools(t *testing.T) {
+	cfg := SandboxConfig{Enable: true}
+	for _, name := range Tools {
+		if !cfg.AppliesToTool(name) {
+			t.Errorf("%s not sandboxed with an empty apply_to_tools", name)
+		}
+	}
+	if cfg.AppliesToTool("web_fetch") {
+		t.Errorf("web_fetch cannot be sandboxed")
+	}
+}
+
+func TestLocalExecutorCommand(t *testing.T) {
+	workspace := t.TempDir()
+	cmd, err := NewCommand(context.Background(), NewLocalExecutor(workspace), &ExecRequest{
+		Env:     []string{"SANDBOX_TEST=launched"},
+		Command: Command{Display: "echo $SANDBOX_TEST", UseShell: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	if cmd.Dir != workspace || strings.TrimSpace(string(out)) != "launched" {
+		t.Fatalf("dir %q, output %q", cmd.Dir, out)
+	}
+
+	if _, err := NewCommand(context.Background(), NewGoJudgeExecutor(workspace, GoJudgeConfig{}), &ExecRequest{}); err == nil {
+		t.Fatalf("go-judge should not launch long-running processes")
+	}
+}
@@ -1,16 +1,25 @@
 package sandbox
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
 
-type SecurityConfig struct {
-	Sandbox SandboxConfig `yaml:"sandbox"`
-}
+// Tools are the tools that run commands and can be sandboxed. exec runs
+// one command to completion; the others keep a process running, which
+// needs a backbone that implements Launcher.
+var Tools = []string{"exec", "process", "agent", "mcp"}
 
+// SandboxConfig is configured per agent in agents.<id>.security.sandbox.
 type SandboxConfig struct {
 	Enable       bool          `yaml:"enable"`
-	Runtime      string        `yaml:"runtime"` // local | go_judge
-	ApplyToTools []string      `yaml:"apply_to_tools"`
+	Runtime      string        `yaml:"runtime"`        // local | go_judge | native, or a registered backbone
+	ApplyToTools []string      `yaml:"apply_to_tools"` // empty means all of Tools; go_judge needs [exec]
 	GoJudge      GoJudgeConfig `yaml:"go_judge"`
+	Native       NativeConfig  `yaml:"native"`
 }
 
 type GoJudgeConfig struct {
@@ -26,8 +35,26 @@ type GoJudgeConfig struct {
 	MaxStderrBytes    int    `yaml:"max_stderr_bytes"`
 }
 
+// NativeConfig configures the native Linux backbone. Zero limits are not
+// enforced; any non-zero CPU, memory or process limit needs cgroup v2.
+type NativeConfig struct {
+	CPUs           float64  `yaml:"cpus"` // CPU time per second, e.g. 0.5 or 2
+	MemoryLimitKB  int      `yaml:"memory_limit_kb"`
+	ProcLimit      int      `yaml:"proc_limit"`
+	ScratchSizeMB  int      `yaml:"scratch_size_mb"` // size of the writable /tmp, default 64
+	ShareNetwork   bool     `yaml:"share_network"`   // keep the host network instead of an empty one
+	Seccomp        bool     `yaml:"seccomp"`         // block syscalls sandboxed commands have no use for
+	ReadOnlyPaths  []string `yaml:"read_only_paths"` // extra host paths mounted read-only
+	CgroupParent   string   `yaml:"cgroup_parent"`   // cgroup v2 directory to create sandbox cgroups in
+	MaxStdoutBytes int      `yaml:"max_stdout_bytes"`
+	MaxStderrBytes int      `yaml:"max_stderr_bytes"`
+}
+
 func (s SandboxConfig) AppliesToTool(toolName string) bool {
 	name := strings.ToLower(strings.TrimSpace(toolName))
+	if len(s.ApplyToTools) == 0 {
+		return slices.Contains(Tools, name)
+	}
 	for _, one := range s.ApplyToTools {
 		if one == name {
 			return true
@@ -35,3 +62,75 @@ func (s SandboxConfig) AppliesToTool(toolName string) bool {
 	}
 	return false
 }
+
+// Validate normalizes the config and checks the backbone and tool names.
+func (s *SandboxConfig) Validate() error {
+	if s == nil {
+		return errors.New("sandbox config cannot be nil")
+	}
+
+	s.Runtime = strings.ToLower(strings.TrimSpace(s.Runtime))
+	if s.Runtime == "" {
+		s.Runtime = BackboneLocal
+	}
+	if !HasBackbone(s.Runtime) {
+		return fmt.Errorf("unsupported runtime: %s", s.Runtime)
+	}
+	for i, name := range s.ApplyToTools {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(Tools, name) {
+			return fmt.Errorf("apply_to_tools: %q cannot be sandboxed, must be one of %s", s.ApplyToTools[i], strings.Join(Tools, ", "))
+		}
+		s.ApplyToTools[i] = name
+	}
+
+	if !s.Enable {
+		return nil
+	}
+	switch s.Runtime {
+	case BackboneGoJudge:
+		// go-judge runs a command to completion and cannot keep one alive,
+		// so the other tools would only fail once they are called.
+		for _, name := range Tools {
+			if name != "exec" && s.AppliesToTool(name) {
+				return fmt.Errorf("apply_to_tools: go_judge cannot run %s, set apply_to_tools to [exec]", name)
+			}
+		}
+		return s.GoJudge.validate()
+	case BackboneNative:
+		return s.Native.validate()
+	}
+	return nil
+}
+
+func (g *GoJudgeConfig) validate() error {
+	g.Endpoint = strings.TrimSpace(g.Endpoint)
+	if g.Endpoint == "" {
+		return errors.New("go_judge.endpoint is required")
+	}
+	if g.RequestTimeoutSec < 0 || g.CPULimitMS < 0 || g.WallLimitMS < 0 || g.MemoryLimitKB < 0 ||
+		g.ProcLimit < 0 || g.MaxStdoutBytes < 0 || g.MaxStderrBytes < 0 {
+		return errors.New("go_judge limits cannot be negative")
+	}
+	return nil
+}
+
+func (n *NativeConfig) validate() error {
+	if n.CPUs < 0 || n.MemoryLimitKB < 0 || n.ProcLimit < 0 || n.ScratchSizeMB < 0 ||
+		n.MaxStdoutBytes < 0 || n.MaxStderrBytes < 0 {
+		return errors.New("native limits cannot be negative")
+	}
+	for i, path := range n.ReadOnlyPaths {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("native.read_only_paths: %q must be absolute", path)
+		}
+		n.ReadOnlyPaths[i] = filepath.Clean(path)
+	}
+	if n.CgroupParent != "" {
+		if !filepath.IsAbs(n.CgroupParent) {
+			return fmt.Errorf("native.cgroup_parent: %q must be absolute", n.CgroupParent)
+		}
+		n.CgroupParent = filepath.Clean(n.CgroupParent)
+	}
+	return nil
+}
@@ -88,7 +88,7 @@ func (e *GoJudgeExecutor) Execute(ctx context.Context, req *ExecRequest) (*ExecR
 		Cmd: []goJudgeCommand{
 			{
 				Args:        args,
-				Env:         []string{"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+				Env:         append([]string{"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"}, req.Env...),
 				Cwd:         sandboxWD,
 				CPULimit:    cpuLimitMS,
 				ClockLimit:  wallLimitMS,
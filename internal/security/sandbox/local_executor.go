@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"time"
 )
@@ -32,13 +33,7 @@ func (e *LocalExecutor) Execute(ctx context.Context, req *ExecRequest) (*ExecRes
 	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cmd := commandWithContext(cmdCtx, req.Command)
-	if req.WorkingDir != "" {
-		cmd.Dir = req.WorkingDir
-	} else if e.workspace != "" {
-		cmd.Dir = e.workspace
-	}
-	setCommandProcessGroup(cmd)
+	cmd := e.prepare(cmdCtx, req)
 
 	var stdoutBuf bytes.Buffer
 	var stderrBuf bytes.Buffer
@@ -73,6 +68,28 @@ func (e *LocalExecutor) Execute(ctx context.Context, req *ExecRequest) (*ExecRes
 	}, nil
 }
 
+// Command implements Launcher.
+func (e *LocalExecutor) Command(ctx context.Context, req *ExecRequest) (*exec.Cmd, error) {
+	if req == nil {
+		return nil, fmt.Errorf("exec request is required")
+	}
+	return e.prepare(ctx, req), nil
+}
+
+func (e *LocalExecutor) prepare(ctx context.Context, req *ExecRequest) *exec.Cmd {
+	cmd := commandWithContext(ctx, req.Command)
+	if req.WorkingDir != "" {
+		cmd.Dir = req.WorkingDir
+	} else if e.workspace != "" {
+		cmd.Dir = e.workspace
+	}
+	if len(req.Env) > 0 {
+		cmd.Env = append(os.Environ(), req.Env...)
+	}
+	setCommandProcessGroup(cmd)
+	return cmd
+}
+
 func commandWithContext(ctx context.Context, cmd Command) *exec.Cmd {
 	if cmd.UseShell {
 		return exec.CommandContext(ctx, "sh", "-c", cmd.Display)
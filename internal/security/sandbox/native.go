@@ -0,0 +1,131 @@
+package sandbox
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultNativeScratchMB   = 64
+	defaultNativeOutputBytes = 1 << 20
+)
+
+// NativeExecutor runs commands on Linux in fresh user, mount, PID, IPC, UTS
+// and, unless the network is shared, network namespaces. The sandbox sees
+// the system directories and the workspace read-only at their host paths,
+// a small tmpfs as its writable /tmp and nothing else of the host
+// filesystem. Landlock confines file access further where the kernel
+// supports it, seccomp optionally blocks syscalls that could escape or
+// probe the sandbox, and cgroup v2 enforces the CPU, memory and process
+// limits.
+//
+// It needs no helper binary: the sandbox is set up by the running
+// executable, started again under a marker argument that the package
+// init function recognizes.
+type NativeExecutor struct {
+	workspace      string
+	readOnlyPaths  []string
+	scratchMB      int
+	shareNetwork   bool
+	seccomp        bool
+	limits         cgroupLimits
+	cgroupParent   string
+	maxStdoutBytes int
+	maxStderrBytes int
+}
+
+// cgroupLimits are the cgroup v2 limits of one sandbox; zero means none.
+type cgroupLimits struct {
+	CPUs        float64
+	MemoryBytes int64
+	Pids        int
+}
+
+func (l cgroupLimits) empty() bool {
+	return l.CPUs == 0 && l.MemoryBytes == 0 && l.Pids == 0
+}
+
+func init() {
+	if err := RegisterBackbone(BackboneNative, func(workspace string, cfg SandboxConfig) (Executor, error) {
+		return NewNativeExecutor(workspace, cfg.Native)
+	}); err != nil {
+		panic(err)
+	}
+}
+
+func NewNativeExecutor(workspace string, cfg NativeConfig) (*NativeExecutor, error) {
+	if strings.TrimSpace(workspace) == "" {
+		return nil, fmt.Errorf("native sandbox needs a workspace")
+	}
+	workspace, err := filepath.Abs(workspace)
+	if err != nil {
+		return nil, fmt.Errorf("resolve workspace: %w", err)
+	}
+
+	e := &NativeExecutor{
+		workspace:      workspace,
+		readOnlyPaths:  cfg.ReadOnlyPaths,
+		scratchMB:      cfg.ScratchSizeMB,
+		shareNetwork:   cfg.ShareNetwork,
+		seccomp:        cfg.Seccomp,
+		cgroupParent:   cfg.CgroupParent,
+		maxStdoutBytes: cfg.MaxStdoutBytes,
+		maxStderrBytes: cfg.MaxStderrBytes,
+		limits: cgroupLimits{
+			CPUs:        cfg.CPUs,
+			MemoryBytes: int64(cfg.MemoryLimitKB) * 1024,
+			Pids:        cfg.ProcLimit,
+		},
+	}
+	if e.scratchMB <= 0 {
+		e.scratchMB = defaultNativeScratchMB
+	}
+	if e.maxStdoutBytes <= 0 {
+		e.maxStdoutBytes = defaultNativeOutputBytes
+	}
+	if e.maxStderrBytes <= 0 {
+		e.maxStderrBytes = defaultNativeOutputBytes
+	}
+	if err := e.checkSupport(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *NativeExecutor) buildArgs(cmd Command) ([]string, error) {
+	if cmd.UseShell {
+		display := strings.TrimSpace(cmd.Display)
+		if display == "" {
+			return nil, fmt.Errorf("command is required")
+		}
+		return []string{"/bin/sh", "-c", display}, nil
+	}
+	program := strings.TrimSpace(cmd.Program)
+	if program == "" {
+		return nil, fmt.Errorf("command program is required")
+	}
+	return append([]string{program}, cmd.Args...), nil
+}
+
+// resolveWorkingDir returns the directory to run in. The workspace is
+// mounted at its host path, so the host path is used as is, but it must
+// lie within the workspace.
+func (e *NativeExecutor) resolveWorkingDir(workingDir string) (string, error) {
+	wd := strings.TrimSpace(workingDir)
+	if wd == "" {
+		return e.workspace, nil
+	}
+	if !filepath.IsAbs(wd) {
+		wd = filepath.Join(e.workspace, wd)
+	}
+	wd = filepath.Clean(wd)
+	within, err := isPathWithin(wd, e.workspace)
+	if err != nil {
+		return "", fmt.Errorf("resolve sandbox working dir: %w", err)
+	}
+	if !within {
+		return "", fmt.Errorf("working_dir must be within workspace when sandbox is enabled")
+	}
+	return wd, nil
+}
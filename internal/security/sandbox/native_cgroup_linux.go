@@ -0,0 +1,170 @@
+package sandbox
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	cgroupRoot   = "/sys/fs/cgroup"
+	cgroupPrefix = "friday-sandbox-"
+	cpuPeriodUS  = 100000
+)
+
+// cgroupMu serializes creating and sweeping sandbox cgroups.
+var cgroupMu sync.Mutex
+
+// newCgroup creates a cgroup for one sandbox under parent, or under the
+// cgroup friday runs in, and applies limits. The sandbox init process
+// joins it before starting the command.
+//
+// cgroup v2 only allows controllers on the children of a cgroup that has
+// no processes of its own. When friday creates sandboxes in its own
+// cgroup it therefore first moves itself into a "friday" leaf next to
+// them.
+func newCgroup(parent string, limits cgroupLimits) (string, error) {
+	cgroupMu.Lock()
+	defer cgroupMu.Unlock()
+
+	own := parent == ""
+	if own {
+		self, err := selfCgroup()
+		if err != nil {
+			return "", err
+		}
+		parent = self
+	}
+	if err := enableControllers(parent, limits, own); err != nil {
+		return "", fmt.Errorf("cgroup %s: %w", parent, err)
+	}
+	sweepCgroups(parent)
+
+	var id [6]byte
+	_, _ = rand.Read(id[:])
+	dir := filepath.Join(parent, cgroupPrefix+hex.EncodeToString(id[:]))
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create cgroup: %w", err)
+	}
+
+	files := map[string]string{}
+	if limits.CPUs > 0 {
+		quota := max(int(limits.CPUs*cpuPeriodUS), 1000)
+		files["cpu.max"] = fmt.Sprintf("%d %d", quota, cpuPeriodUS)
+	}
+	if limits.MemoryBytes > 0 {
+		files["memory.max"] = strconv.FormatInt(limits.MemoryBytes, 10)
+		files["memory.swap.max"] = "0"
+	}
+	if limits.Pids > 0 {
+		files["pids.max"] = strconv.Itoa(limits.Pids)
+	}
+	for name, value := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0)
+		if err != nil && !(name == "memory.swap.max" && errors.Is(err, os.ErrNotExist)) {
+			_ = os.Remove(dir)
+			return "", fmt.Errorf("set %s: %w", name, err)
+		}
+	}
+	return dir, nil
+}
+
+// selfCgroup returns the directory of the cgroup friday runs in.
+func selfCgroup() (string, error) {
+	f, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if path, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			return filepath.Join(cgroupRoot, path), nil
+		}
+	}
+	return "", errors.New("friday does not run in a cgroup v2 hierarchy")
+}
+
+func enableControllers(parent string, limits cgroupLimits, own bool) error {
+	var want []string
+	if limits.CPUs > 0 {
+		want = append(want, "+cpu")
+	}
+	if limits.MemoryBytes > 0 {
+		want = append(want, "+memory")
+	}
+	if limits.Pids > 0 {
+		want = append(want, "+pids")
+	}
+	control := filepath.Join(parent, "cgroup.subtree_control")
+	err := os.WriteFile(control, []byte(strings.Join(want, " ")), 0)
+	if errors.Is(err, unix.EBUSY) && own {
+		leaf := filepath.Join(parent, "friday")
+		if err := os.Mkdir(leaf, 0o755); err != nil && !errors.Is(err, os.ErrExist) {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(leaf, "cgroup.procs"), []byte(strconv.Itoa(os.Getpid())), 0); err != nil {
+			return fmt.Errorf("move friday into %s: %w", leaf, err)
+		}
+		err = os.WriteFile(control, []byte(strings.Join(want, " ")), 0)
+	}
+	if err != nil {
+		return fmt.Errorf("enable %s: %w (set native.cgroup_parent to a cgroup delegated to friday)", strings.Join(want, " "), err)
+	}
+	return nil
+}
+
+// sweepCgroups removes the cgroups of sandboxes that have finished. A
+// cgroup that has never run anything may belong to a sandbox that is
+// about to start and is kept.
+func sweepCgroups(parent string) {
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), cgroupPrefix) {
+			continue
+		}
+		dir := filepath.Join(parent, entry.Name())
+		if cgroupValue(dir, "cgroup.events", "populated") == "0" &&
+			cgroupValue(dir, "cpu.stat", "usage_usec") != "0" {
+			_ = os.Remove(dir)
+		}
+	}
+}
+
+// cgroupValue returns the value of key in a flat-keyed cgroup file.
+func cgroupValue(dir, file, key string) string {
+	raw, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(raw), "\n") {
+		if value, ok := strings.CutPrefix(line, key+" "); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// removeCgroup removes the cgroup of a finished sandbox. Its processes
+// are killed with its init process, but may take a moment to go.
+func removeCgroup(dir string) {
+	for i := 0; i < 20; i++ {
+		if err := os.Remove(dir); err == nil || errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,411 @@
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/bytedance/sonic"
+	"golang.org/x/sys/unix"
+)
+
+// systemDirs are mounted read-only into every sandbox when the host has
+// them. Symlinks, as on merged-/usr systems, are recreated instead.
+var systemDirs = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/libx32", "/etc"}
+
+// devNodes are bound from the host /dev.
+var devNodes = []string{"null", "zero", "full", "random", "urandom", "tty"}
+
+// forwardedSignals are passed on from init to the command.
+var forwardedSignals = []os.Signal{
+	syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM,
+	syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGWINCH, syscall.SIGCONT,
+}
+
+func init() {
+	if len(os.Args) == 2 && os.Args[0] == nativeInitArg {
+		runNativeInit(os.Args[1])
+	}
+}
+
+// runNativeInit is the sandbox init process, PID 1 of the new namespaces.
+// It builds the sandbox filesystem, restricts itself, starts the command
+// and waits for it, forwarding signals and reaping orphans, then exits
+// with the command's status. It never returns.
+//
+// The restrictions that are per thread, Landlock, seccomp and the dropped
+// capabilities, are applied to the locked main thread, which then forks
+// the command so it inherits them.
+func runNativeInit(raw string) {
+	runtime.LockOSThread()
+
+	var spec nativeSpec
+	if err := sonic.UnmarshalString(raw, &spec); err != nil {
+		fmt.Fprintf(os.Stderr, "friday sandbox: parse spec: %v\n", err)
+		os.Exit(nativeInitFailed)
+	}
+	var status *os.File
+	if spec.StatusFD {
+		syscall.CloseOnExec(3)
+		status = os.NewFile(3, "status")
+	}
+	fail := func(err error) {
+		if status != nil {
+			_, _ = status.WriteString(err.Error())
+		} else {
+			fmt.Fprintf(os.Stderr, "friday sandbox: %v\n", err)
+		}
+		os.Exit(nativeInitFailed)
+	}
+
+	if err := setupSandbox(&spec); err != nil {
+		fail(err)
+	}
+	if err := restrictSelf(&spec); err != nil {
+		fail(err)
+	}
+	program, err := lookPathIn(spec.Args[0], spec.Env)
+	if err != nil {
+		fail(err)
+	}
+
+	signals := make(chan os.Signal, 16)
+	signal.Notify(signals, forwardedSignals...)
+	pid, err := syscall.ForkExec(program, spec.Args, &syscall.ProcAttr{
+		Dir:   spec.Dir,
+		Env:   spec.Env,
+		Files: []uintptr{0, 1, 2},
+	})
+	if err != nil {
+		fail(fmt.Errorf("start %s: %w", spec.Args[0], err))
+	}
+	if status != nil {
+		_ = status.Close()
+	}
+	go func() {
+		for sig := range signals {
+			_ = syscall.Kill(pid, sig.(syscall.Signal))
+		}
+	}()
+	os.Exit(reap(pid))
+}
+
+// reap waits for children until pid exits and returns its exit status,
+// or 128 plus the signal that killed it.
+func reap(pid int) int {
+	for {
+		var ws syscall.WaitStatus
+		wpid, err := syscall.Wait4(-1, &ws, 0, nil)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			return nativeInitFailed
+		}
+		if wpid != pid {
+			continue
+		}
+		switch {
+		case ws.Exited():
+			return ws.ExitStatus()
+		case ws.Signaled():
+			return 128 + int(ws.Signal())
+		}
+	}
+}
+
+// hostPath is a host file or directory to be mounted into the sandbox.
+// It is opened before the new root is mounted over /tmp, which would
+// hide it if it lives there, and bound through /proc/self/fd.
+type hostPath struct {
+	target string
+	fd     int
+	dir    bool
+}
+
+func openHostPath(path string) (hostPath, error) {
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return hostPath{}, fmt.Errorf("open %s: %w", path, err)
+	}
+	var st unix.Stat_t
+	if err := unix.Fstat(fd, &st); err != nil {
+		_ = unix.Close(fd)
+		return hostPath{}, fmt.Errorf("stat %s: %w", path, err)
+	}
+	return hostPath{target: path, fd: fd, dir: st.Mode&unix.S_IFMT == unix.S_IFDIR}, nil
+}
+
+// setupSandbox builds the sandbox root on a tmpfs mounted over /tmp and
+// pivots into it.
+func setupSandbox(spec *nativeSpec) error {
+	if spec.Cgroup != "" {
+		if err := os.WriteFile(filepath.Join(spec.Cgroup, "cgroup.procs"), []byte("0"), 0); err != nil {
+			return fmt.Errorf("join cgroup: %w", err)
+		}
+	}
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %w", err)
+	}
+
+	links := map[string]string{}
+	var system, user []hostPath
+	defer func() {
+		for _, one := range slices.Concat(system, user) {
+			_ = unix.Close(one.fd)
+		}
+	}()
+	for _, dir := range systemDirs {
+		info, err := os.Lstat(dir)
+		if err != nil {
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if links[dir], err = os.Readlink(dir); err != nil {
+				return err
+			}
+			continue
+		}
+		one, err := openHostPath(dir)
+		if err != nil {
+			return err
+		}
+		system = append(system, one)
+	}
+	for _, path := range append([]string{spec.Workspace}, spec.ReadOnly...) {
+		one, err := openHostPath(path)
+		if err != nil {
+			return err
+		}
+		user = append(user, one)
+	}
+	// Parents are mounted before what lies inside them.
+	slices.SortFunc(user, func(a, b hostPath) int { return strings.Compare(a.target, b.target) })
+
+	const root = "/tmp"
+	if err := unix.Mount("tmpfs", root, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=0755"); err != nil {
+		return fmt.Errorf("mount root: %w", err)
+	}
+	for _, one := range system {
+		if err := bindHostPath(root, one); err != nil {
+			return err
+		}
+	}
+	for dir, link := range links {
+		if err := os.Symlink(link, root+dir); err != nil {
+			return err
+		}
+	}
+	if err := mountTmpfs(root+"/tmp", fmt.Sprintf("mode=1777,size=%dm", spec.ScratchMB)); err != nil {
+		return err
+	}
+	if err := os.Mkdir(root+"/proc", 0o555); err != nil {
+		return err
+	}
+	if err := unix.Mount("proc", root+"/proc", "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("mount /proc: %w", err)
+	}
+	if err := setupDev(root); err != nil {
+		return err
+	}
+	for _, one := range user {
+		if err := bindHostPath(root, one); err != nil {
+			return err
+		}
+	}
+
+	if err := unix.Chdir(root); err != nil {
+		return err
+	}
+	if err := unix.PivotRoot(".", "."); err != nil {
+		return fmt.Errorf("pivot root: %w", err)
+	}
+	if err := unix.Unmount(".", unix.MNT_DETACH); err != nil {
+		return fmt.Errorf("detach host root: %w", err)
+	}
+	if err := unix.Chdir("/"); err != nil {
+		return err
+	}
+	if err := unix.Mount("", "/", "", unix.MS_REMOUNT|unix.MS_BIND|unix.MS_RDONLY|unix.MS_NOSUID|unix.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("remount root read-only: %w", err)
+	}
+
+	if !spec.Network {
+		if err := loopbackUp(); err != nil {
+			return err
+		}
+	}
+	return unix.Sethostname([]byte("sandbox"))
+}
+
+// bindHostPath mounts one read-only under root at its host path.
+func bindHostPath(root string, one hostPath) error {
+	target := root + one.target
+	if one.dir {
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			return err
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, nil, 0o644); err != nil && !os.IsExist(err) {
+			return err
+		}
+	}
+	source := fmt.Sprintf("/proc/self/fd/%d", one.fd)
+	if err := unix.Mount(source, target, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return fmt.Errorf("bind %s: %w", one.target, err)
+	}
+
+	attr := &unix.MountAttr{Attr_set: unix.MOUNT_ATTR_RDONLY | unix.MOUNT_ATTR_NOSUID | unix.MOUNT_ATTR_NODEV}
+	err := unix.MountSetattr(unix.AT_FDCWD, target, unix.AT_RECURSIVE, attr)
+	if err != unix.ENOSYS {
+		if err != nil {
+			return fmt.Errorf("make %s read-only: %w", one.target, err)
+		}
+		return nil
+	}
+	// Before Linux 5.12 only the top mount can be made read-only. A
+	// remount in a user namespace must keep the flags the host mount is
+	// locked with.
+	var st unix.Statfs_t
+	if err := unix.Statfs(target, &st); err != nil {
+		return err
+	}
+	flags := uintptr(unix.MS_REMOUNT | unix.MS_BIND | unix.MS_RDONLY | unix.MS_NOSUID | unix.MS_NODEV)
+	for locked, ms := range map[int64]uintptr{
+		unix.ST_NOEXEC:     unix.MS_NOEXEC,
+		unix.ST_NOATIME:    unix.MS_NOATIME,
+		unix.ST_NODIRATIME: unix.MS_NODIRATIME,
+		unix.ST_RELATIME:   unix.MS_RELATIME,
+	} {
+		if int64(st.Flags)&locked != 0 {
+			flags |= ms
+		}
+	}
+	if err := unix.Mount("", target, "", flags, ""); err != nil {
+		return fmt.Errorf("make %s read-only: %w", one.target, err)
+	}
+	return nil
+}
+
+func mountTmpfs(target, options string) error {
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return err
+	}
+	if err := unix.Mount("tmpfs", target, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, options); err != nil {
+		return fmt.Errorf("mount %s: %w", target, err)
+	}
+	return nil
+}
+
+// setupDev gives the sandbox a minimal /dev.
+func setupDev(root string) error {
+	dev := root + "/dev"
+	if err := os.Mkdir(dev, 0o755); err != nil {
+		return err
+	}
+	for _, name := range devNodes {
+		if _, err := os.Stat("/dev/" + name); err != nil {
+			continue
+		}
+		if err := os.WriteFile(dev+"/"+name, nil, 0o666); err != nil {
+			return err
+		}
+		if err := unix.Mount("/dev/"+name, dev+"/"+name, "", unix.MS_BIND, ""); err != nil {
+			return fmt.Errorf("bind /dev/%s: %w", name, err)
+		}
+	}
+	for name, link := range map[string]string{
+		"fd":     "/proc/self/fd",
+		"stdin":  "/proc/self/fd/0",
+		"stdout": "/proc/self/fd/1",
+		"stderr": "/proc/self/fd/2",
+	} {
+		if err := os.Symlink(link, dev+"/"+name); err != nil {
+			return err
+		}
+	}
+	return mountTmpfs(dev+"/shm", "mode=1777")
+}
+
+// loopbackUp brings up lo in the new network namespace, the only
+// interface it has.
+func loopbackUp() error {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("bring up lo: %w", err)
+	}
+	defer unix.Close(fd)
+	ifr, err := unix.NewIfreq("lo")
+	if err != nil {
+		return err
+	}
+	if err := unix.IoctlIfreq(fd, unix.SIOCGIFFLAGS, ifr); err != nil {
+		return fmt.Errorf("bring up lo: %w", err)
+	}
+	ifr.SetUint16(ifr.Uint16() | unix.IFF_UP)
+	if err := unix.IoctlIfreq(fd, unix.SIOCSIFFLAGS, ifr); err != nil {
+		return fmt.Errorf("bring up lo: %w", err)
+	}
+	return nil
+}
+
+// restrictSelf drops every capability and applies Landlock and, when
+// enabled, seccomp to the calling thread.
+func restrictSelf(spec *nativeSpec) error {
+	for c := 0; c <= unix.CAP_LAST_CAP; c++ {
+		if err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(c), 0, 0, 0); err != nil && err != unix.EINVAL {
+			return fmt.Errorf("drop capabilities: %w", err)
+		}
+	}
+	if err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0); err != nil && err != unix.EINVAL {
+		return fmt.Errorf("drop capabilities: %w", err)
+	}
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	if err := unix.Capset(&hdr, &data[0]); err != nil {
+		return fmt.Errorf("drop capabilities: %w", err)
+	}
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("set no_new_privs: %w", err)
+	}
+	if err := applyLandlock(); err != nil {
+		return fmt.Errorf("landlock: %w", err)
+	}
+	if spec.Seccomp {
+		if err := applySeccomp(); err != nil {
+			return fmt.Errorf("seccomp: %w", err)
+		}
+	}
+	return nil
+}
+
+// lookPathIn finds name in the PATH of env, inside the sandbox.
+func lookPathIn(name string, env []string) (string, error) {
+	if strings.Contains(name, "/") {
+		return name, nil
+	}
+	var path string
+	for _, kv := range env {
+		if value, ok := strings.CutPrefix(kv, "PATH="); ok {
+			path = value
+		}
+	}
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			dir = "."
+		}
+		candidate := filepath.Join(dir, name)
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() && info.Mode()&0o111 != 0 {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%s: executable file not found in $PATH", name)
+}
@@ -0,0 +1,195 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/tgifai/friday/internal/pkg/iobuf"
+)
+
+// nativeInitArg is the argv[0] under which the executable sets up a
+// sandbox instead of running normally; see runNativeInit.
+const nativeInitArg = "friday-sandbox-init"
+
+// nativeInitFailed is the exit status of a sandbox that could not be set up.
+const nativeInitFailed = 125
+
+// nativeSpec tells the sandbox init process what to set up and run.
+type nativeSpec struct {
+	Args      []string `json:"args"`
+	Env       []string `json:"env"`
+	Dir       string   `json:"dir"`
+	Workspace string   `json:"workspace"`
+	ReadOnly  []string `json:"read_only,omitempty"`
+	ScratchMB int      `json:"scratch_mb"`
+	Network   bool     `json:"network"`
+	Seccomp   bool     `json:"seccomp"`
+	Cgroup    string   `json:"cgroup,omitempty"`
+	// StatusFD makes init report setup errors on fd 3, which it closes
+	// once the command has started.
+	StatusFD bool `json:"status_fd,omitempty"`
+}
+
+func (e *NativeExecutor) checkSupport() error {
+	if readSysctl("/proc/sys/user/max_user_namespaces") == "0" {
+		return errors.New("native sandbox: user namespaces are disabled (user.max_user_namespaces is 0)")
+	}
+	if os.Getuid() != 0 && readSysctl("/proc/sys/kernel/unprivileged_userns_clone") == "0" {
+		return errors.New("native sandbox: unprivileged user namespaces are disabled (kernel.unprivileged_userns_clone is 0)")
+	}
+	if !e.limits.empty() {
+		if _, err := os.Stat(cgroupRoot + "/cgroup.controllers"); err != nil {
+			return fmt.Errorf("native sandbox: cpu, memory and process limits need cgroup v2 mounted at %s", cgroupRoot)
+		}
+	}
+	return nil
+}
+
+func readSysctl(path string) string {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+func (e *NativeExecutor) Execute(ctx context.Context, req *ExecRequest) (*ExecResult, error) {
+	if req == nil {
+		return nil, fmt.Errorf("exec request is required")
+	}
+
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd, cgroup, err := e.prepare(cmdCtx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	if cgroup != "" {
+		defer removeCgroup(cgroup)
+	}
+
+	statusR, statusW, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("create status pipe: %w", err)
+	}
+	defer statusR.Close()
+	cmd.ExtraFiles = []*os.File{statusW}
+
+	stdout := iobuf.NewLimitedBuffer(e.maxStdoutBytes)
+	stderr := iobuf.NewLimitedBuffer(e.maxStderrBytes)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Start()
+	statusW.Close()
+	if err != nil {
+		return nil, fmt.Errorf("start native sandbox: %w", err)
+	}
+	status, _ := io.ReadAll(statusR)
+	err = cmd.Wait()
+	if len(status) > 0 {
+		return nil, fmt.Errorf("native sandbox: %s", status)
+	}
+
+	timedOut := errors.Is(cmdCtx.Err(), context.DeadlineExceeded)
+	exitCode := 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("command execution failed: %w", err)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+	if timedOut && exitCode == 0 {
+		exitCode = -1
+	}
+
+	return &ExecResult{
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		ExitCode: exitCode,
+		TimedOut: timedOut,
+	}, nil
+}
+
+// Command implements Launcher. The cgroup of the process is removed by
+// a later sandbox once the process has exited.
+func (e *NativeExecutor) Command(ctx context.Context, req *ExecRequest) (*exec.Cmd, error) {
+	if req == nil {
+		return nil, fmt.Errorf("exec request is required")
+	}
+	cmd, _, err := e.prepare(ctx, req, false)
+	return cmd, err
+}
+
+// prepare builds the command that starts the sandbox init process, and
+// the cgroup it joins, if any.
+func (e *NativeExecutor) prepare(ctx context.Context, req *ExecRequest, statusFD bool) (*exec.Cmd, string, error) {
+	args, err := e.buildArgs(req.Command)
+	if err != nil {
+		return nil, "", err
+	}
+	wd, err := e.resolveWorkingDir(req.WorkingDir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	spec := nativeSpec{
+		Args: args,
+		Env: append([]string{
+			"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+			"HOME=/tmp",
+			"TMPDIR=/tmp",
+		}, req.Env...),
+		Dir:       wd,
+		Workspace: e.workspace,
+		ReadOnly:  e.readOnlyPaths,
+		ScratchMB: e.scratchMB,
+		Network:   e.shareNetwork,
+		Seccomp:   e.seccomp,
+		StatusFD:  statusFD,
+	}
+	if !e.limits.empty() {
+		if spec.Cgroup, err = newCgroup(e.cgroupParent, e.limits); err != nil {
+			return nil, "", err
+		}
+	}
+	raw, err := sonic.Marshal(spec)
+	if err != nil {
+		return nil, "", fmt.Errorf("marshal sandbox spec: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "/proc/self/exe")
+	cmd.Args = []string{nativeInitArg, string(raw)}
+	cmd.Env = []string{}
+
+	flags := syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+		syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS
+	if !e.shareNetwork {
+		flags |= syscall.CLONE_NEWNET
+	}
+	// The sandbox runs as root of its user namespace, which maps to the
+	// user running friday; init drops every capability before starting
+	// the command.
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  uintptr(flags),
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+		Setpgid:     true,
+	}
+	return cmd, spec.Cgroup, nil
+}
@@ -0,0 +1,157 @@
+package sandbox
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func newTestNativeExecutor(t *testing.T, cfg NativeConfig) (*NativeExecutor, string) {
+	t.Helper()
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "notes.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewNativeExecutor(workspace, cfg)
+	if err != nil {
+		t.Skipf("native sandbox unavailable: %v", err)
+	}
+	res, err := e.Execute(context.Background(), &ExecRequest{Command: Command{Display: "true", UseShell: true}})
+	if err != nil {
+		t.Skipf("native sandbox unavailable: %v", err)
+	}
+	if res.ExitCode != 0 {
+		t.Skipf("native sandbox unavailable: exit %d: %s", res.ExitCode, res.Stderr)
+	}
+	return e, workspace
+}
+
+func runNative(t *testing.T, e *NativeExecutor, script string) *ExecResult {
+	t.Helper()
+	res, err := e.Execute(context.Background(), &ExecRequest{
+		Timeout: 10 * time.Second,
+		Command: Command{Display: script, UseShell: true},
+	})
+	if err != nil {
+		t.Fatalf("Execute(%q): %v", script, err)
+	}
+	return res
+}
+
+func TestNativeExecutorIsolation(t *testing.T) {
+	e, workspace := newTestNativeExecutor(t, NativeConfig{})
+
+	res := runNative(t, e, "pwd; cat notes.txt")
+	if got := string(res.Stdout); got != workspace+"\nhello" {
+		t.Errorf("workspace not mounted: %q, stderr %q", got, res.Stderr)
+	}
+	if res := runNative(t, e, "echo x > notes.txt"); res.ExitCode == 0 {
+		t.Errorf("workspace is writable")
+	}
+	if res := runNative(t, e, "echo scratch > /tmp/x && cat /tmp/x"); res.ExitCode != 0 || string(res.Stdout) != "scratch\n" {
+		t.Errorf("scratch dir: exit %d, %q, %q", res.ExitCode, res.Stdout, res.Stderr)
+	}
+	home, _ := os.UserHomeDir()
+	if res := runNative(t, e, "ls "+home); home != "" && res.ExitCode == 0 {
+		t.Errorf("home directory %s is visible", home)
+	}
+	if res := runNative(t, e, "ls /proc | grep -c '^[0-9]'"); strings.TrimSpace(string(res.Stdout)) != "4" {
+		t.Errorf("not in a new PID namespace, processes: %q", res.Stdout) // init, sh, ls and grep
+	}
+	if res := runNative(t, e, "cat /proc/net/dev | tail -n +3 | cut -d: -f1"); strings.TrimSpace(string(res.Stdout)) != "lo" {
+		t.Errorf("network not isolated: %q", res.Stdout)
+	}
+	if res := runNative(t, e, "echo x > /dev/null && echo x > /dev/shm/x"); res.ExitCode != 0 {
+		t.Errorf("devices not writable: %q", res.Stderr)
+	}
+	if res := runNative(t, e, "exit 3"); res.ExitCode != 3 {
+		t.Errorf("exit code %d, want 3", res.ExitCode)
+	}
+}
+
+func TestNativeExecutorTimeout(t *testing.T) {
+	e, _ := newTestNativeExecutor(t, NativeConfig{})
+	start := time.Now()
+	res, err := e.Execute(context.Background(), &ExecRequest{
+		Timeout: 300 * time.Millisecond,
+		Command: Command{Display: "echo started; sleep 10", UseShell: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.TimedOut || res.ExitCode != -1 || string(res.Stdout) != "started\n" {
+		t.Fatalf("result %+v", res)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("timeout not enforced")
+	}
+}
+
+func TestNativeExecutorOutputLimit(t *testing.T) {
+	e, _ := newTestNativeExecutor(t, NativeConfig{MaxStdoutBytes: 4})
+	if res := runNative(t, e, "echo 123456789"); string(res.Stdout) != "1234" {
+		t.Fatalf("stdout %q", res.Stdout)
+	}
+}
+
+func TestNativeExecutorSeccomp(t *testing.T) {
+	e, _ := newTestNativeExecutor(t, NativeConfig{Seccomp: true})
+	if _, err := exec.LookPath("unshare"); err != nil {
+		t.Skip("unshare not installed")
+	}
+	if res := runNative(t, e, "echo ok; unshare -U true"); res.ExitCode == 0 || string(res.Stdout) != "ok\n" {
+		t.Fatalf("exit %d, stdout %q, stderr %q", res.ExitCode, res.Stdout, res.Stderr)
+	}
+}
+
+func TestNativeExecutorLandlock(t *testing.T) {
+	if abi, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION); errno != 0 || abi < 1 {
+		t.Skip("kernel lacks Landlock")
+	}
+	e, _ := newTestNativeExecutor(t, NativeConfig{})
+	// /proc is mounted writable; only Landlock stops the write.
+	if res := runNative(t, e, "echo renamed > /proc/self/comm"); res.ExitCode == 0 {
+		t.Fatal("write outside the scratch dirs allowed")
+	}
+}
+
+func TestNativeExecutorCommand(t *testing.T) {
+	e, _ := newTestNativeExecutor(t, NativeConfig{})
+	cmd, err := NewCommand(context.Background(), e, &ExecRequest{
+		Env:     []string{"SANDBOX_TEST=launched"},
+		Command: Command{Display: "cat; echo $SANDBOX_TEST", UseShell: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd.Stdin = strings.NewReader("piped ")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	if string(out) != "piped launched\n" {
+		t.Fatalf("output %q", out)
+	}
+
+	cmd, _ = NewCommand(context.Background(), e, &ExecRequest{Command: Command{Program: "no-such-program"}})
+	out, err = cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "no-such-program") {
+		t.Fatalf("missing program: %v, %q", err, out)
+	}
+}
+
+func TestNativeExecutorWorkingDir(t *testing.T) {
+	e, _ := newTestNativeExecutor(t, NativeConfig{})
+	if _, err := e.Execute(context.Background(), &ExecRequest{
+		WorkingDir: "/etc",
+		Command:    Command{Display: "true", UseShell: true},
+	}); err == nil {
+		t.Fatal("working dir outside the workspace accepted")
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+)
+
+var errNativeUnsupported = errors.New("native sandbox requires Linux")
+
+func (e *NativeExecutor) checkSupport() error {
+	return errNativeUnsupported
+}
+
+func (e *NativeExecutor) Execute(ctx context.Context, req *ExecRequest) (*ExecResult, error) {
+	return nil, errNativeUnsupported
+}
+
+// Command implements Launcher.
+func (e *NativeExecutor) Command(ctx context.Context, req *ExecRequest) (*exec.Cmd, error) {
+	return nil, errNativeUnsupported
+}
@@ -0,0 +1,102 @@
+//go:build amd64 || arm64
+
+package sandbox
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// seccompDenied are the syscalls that fail with EPERM in the sandbox: ways
+// to change mounts or namespaces, load kernel code, inspect other
+// processes, and kernel interfaces with a record of privilege escalation
+// bugs that commands have no use for.
+var seccompDenied = append([]uintptr{
+	unix.SYS_PTRACE, unix.SYS_PROCESS_VM_READV, unix.SYS_PROCESS_VM_WRITEV,
+	unix.SYS_MOUNT, unix.SYS_UMOUNT2, unix.SYS_PIVOT_ROOT, unix.SYS_CHROOT,
+	unix.SYS_FSOPEN, unix.SYS_FSCONFIG, unix.SYS_FSMOUNT, unix.SYS_FSPICK,
+	unix.SYS_MOVE_MOUNT, unix.SYS_OPEN_TREE, unix.SYS_MOUNT_SETATTR,
+	unix.SYS_UNSHARE, unix.SYS_SETNS,
+	unix.SYS_KEXEC_LOAD, unix.SYS_KEXEC_FILE_LOAD,
+	unix.SYS_INIT_MODULE, unix.SYS_FINIT_MODULE, unix.SYS_DELETE_MODULE,
+	unix.SYS_BPF, unix.SYS_PERF_EVENT_OPEN, unix.SYS_USERFAULTFD,
+	unix.SYS_KEYCTL, unix.SYS_ADD_KEY, unix.SYS_REQUEST_KEY,
+	unix.SYS_OPEN_BY_HANDLE_AT, unix.SYS_NAME_TO_HANDLE_AT,
+	unix.SYS_SWAPON, unix.SYS_SWAPOFF, unix.SYS_REBOOT, unix.SYS_ACCT, unix.SYS_QUOTACTL,
+}, seccompArchDenied...)
+
+// cloneNamespaceFlags are the clone flags that create namespaces.
+const cloneNamespaceFlags = unix.CLONE_NEWUSER | unix.CLONE_NEWNS | unix.CLONE_NEWPID | unix.CLONE_NEWNET |
+	unix.CLONE_NEWIPC | unix.CLONE_NEWUTS | unix.CLONE_NEWCGROUP | unix.CLONE_NEWTIME
+
+// seccompData offsets, see struct seccomp_data.
+const (
+	seccompNr    = 0
+	seccompArch  = 4
+	seccompArgs0 = 16 // low half on little-endian
+)
+
+func bpfStmt(code uint16, k uint32) unix.SockFilter {
+	return unix.SockFilter{Code: code, K: k}
+}
+
+func bpfJump(code uint16, k uint32, jt, jf uint8) unix.SockFilter {
+	return unix.SockFilter{Code: code, Jt: jt, Jf: jf, K: k}
+}
+
+// seccompFilter builds the filter program. Syscalls of other
+// architectures kill the process, since the syscall numbers checked
+// would not apply to them. clone3 fails with ENOSYS, so libc falls back
+// to clone, whose flags can be checked for namespaces.
+func seccompFilter() []unix.SockFilter {
+	const (
+		ld   = unix.BPF_LD | unix.BPF_W | unix.BPF_ABS
+		jeq  = unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K
+		jge  = unix.BPF_JMP | unix.BPF_JGE | unix.BPF_K
+		jset = unix.BPF_JMP | unix.BPF_JSET | unix.BPF_K
+		ret  = unix.BPF_RET | unix.BPF_K
+	)
+	eperm := uint32(unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM))
+
+	prog := []unix.SockFilter{
+		bpfStmt(ld, seccompArch),
+		bpfJump(jeq, seccompAuditArch, 1, 0),
+		bpfStmt(ret, unix.SECCOMP_RET_KILL_PROCESS),
+		bpfStmt(ld, seccompNr),
+	}
+	if seccompX32Bit != 0 {
+		prog = append(prog,
+			bpfJump(jge, seccompX32Bit, 0, 1),
+			bpfStmt(ret, eperm),
+		)
+	}
+	for _, nr := range seccompDenied {
+		prog = append(prog,
+			bpfJump(jeq, uint32(nr), 0, 1),
+			bpfStmt(ret, eperm),
+		)
+	}
+	prog = append(prog,
+		bpfJump(jeq, unix.SYS_CLONE3, 0, 1),
+		bpfStmt(ret, uint32(unix.SECCOMP_RET_ERRNO|uint32(unix.ENOSYS))),
+		bpfJump(jeq, unix.SYS_CLONE, 0, 3),
+		bpfStmt(ld, seccompArgs0),
+		bpfJump(jset, cloneNamespaceFlags, 0, 1),
+		bpfStmt(ret, eperm),
+		bpfStmt(ret, unix.SECCOMP_RET_ALLOW),
+	)
+	return prog
+}
+
+// applySeccomp installs the filter on the calling thread, which must
+// already have no_new_privs set.
+func applySeccomp() error {
+	filter := seccompFilter()
+	prog := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	_, _, errno := unix.Syscall(unix.SYS_SECCOMP, unix.SECCOMP_SET_MODE_FILTER, 0, uintptr(unsafe.Pointer(&prog)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
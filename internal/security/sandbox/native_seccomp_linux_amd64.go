@@ -0,0 +1,11 @@
+package sandbox
+
+import "golang.org/x/sys/unix"
+
+const (
+	seccompAuditArch = unix.AUDIT_ARCH_X86_64
+	// seccompX32Bit marks x32 syscalls, which share the x86-64 audit arch.
+	seccompX32Bit = 0x40000000
+)
+
+var seccompArchDenied = []uintptr{unix.SYS_IOPL, unix.SYS_IOPERM}
@@ -0,0 +1,12 @@
+//go:build !amd64 && !arm64
+
+package sandbox
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func applySeccomp() error {
+	return fmt.Errorf("not supported on %s", runtime.GOARCH)
+}
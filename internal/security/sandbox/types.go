@@ -2,6 +2,8 @@ package sandbox
 
 import (
 	"context"
+	"fmt"
+	"os/exec"
 	"time"
 )
 
@@ -17,6 +19,7 @@ type ExecRequest struct {
 	WorkingDir string
 	Timeout    time.Duration
 	Command    Command
+	Env        []string // KEY=value pairs added to the environment
 }
 
 type ExecResult struct {
@@ -29,3 +32,22 @@ type ExecResult struct {
 type Executor interface {
 	Execute(ctx context.Context, req *ExecRequest) (*ExecResult, error)
 }
+
+// Launcher is implemented by executors that can also start long-running
+// processes with their standard streams attached, as the process tool, CLI
+// agents and stdio MCP servers need. Command returns the command unstarted,
+// already in its own process group; callers attach pipes, start and wait
+// on it but leave its SysProcAttr alone. The request timeout does not
+// apply, ctx ends the process instead.
+type Launcher interface {
+	Command(ctx context.Context, req *ExecRequest) (*exec.Cmd, error)
+}
+
+// NewCommand prepares a long-running process through e.
+func NewCommand(ctx context.Context, e Executor, req *ExecRequest) (*exec.Cmd, error) {
+	l, ok := e.(Launcher)
+	if !ok {
+		return nil, fmt.Errorf("sandbox %T cannot run long-running processes", e)
+	}
+	return l.Command(ctx, req)
+}
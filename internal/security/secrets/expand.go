@@ -0,0 +1,99 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// placeholderPattern matches ${NAME} environment references and
+// ${secret:name} store references in config values.
+var placeholderPattern = regexp.MustCompile(`\$\{(secret:)?([A-Za-z0-9._-]+)\}`)
+
+// Expand replaces the placeholders in s: ${secret:name} with the value from
+// the default store and ${NAME} with the environment variable. Expanded
+// values are meant for the component being built from the config; they must
+// never be written back to the config itself.
+func Expand(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var firstErr error
+	out := placeholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := placeholderPattern.FindStringSubmatch(m)
+		value, err := lookup(sub[1] != "", sub[2])
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return value
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return out, nil
+}
+
+// ExpandMap returns a copy of m with the placeholders in every string
+// value expanded, descending into nested maps and lists.
+func ExpandMap(m map[string]any) (map[string]any, error) {
+	if m == nil {
+		return nil, nil
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		expanded, err := expandValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		out[k] = expanded
+	}
+	return out, nil
+}
+
+func expandValue(v any) (any, error) {
+	switch typed := v.(type) {
+	case string:
+		return Expand(typed)
+	case map[string]any:
+		return ExpandMap(typed)
+	case []any:
+		out := make([]any, len(typed))
+		for i, one := range typed {
+			expanded, err := expandValue(one)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = expanded
+		}
+		return out, nil
+	case []string:
+		out := make([]string, len(typed))
+		for i, one := range typed {
+			expanded, err := Expand(one)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = expanded
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+func lookup(secret bool, name string) (string, error) {
+	if secret {
+		value, err := Default().Get(name)
+		if err != nil {
+			return "", fmt.Errorf("resolve ${secret:%s}: %w", name, err)
+		}
+		return value, nil
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("resolve ${%s}: environment variable is not set", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,180 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/tgifai/friday/internal/consts"
+)
+
+// KeySource is where the store's encryption key comes from. It is chosen
+// when the store is created and recorded in it.
+type KeySource string
+
+const (
+	KeySourceFile       KeySource = "file"       // random key in a 0600 file, FRIDAY_HOME/secrets.key by default
+	KeySourcePassphrase KeySource = "passphrase" // derived with scrypt from FRIDAY_SECRETS_PASSPHRASE
+	KeySourceKeyring    KeySource = "keyring"    // random key kept in the OS keyring
+)
+
+// Environment variables that configure the default store's key.
+const (
+	EnvKeySource  = "FRIDAY_SECRETS_KEY"        // file, passphrase or keyring; used when creating the store
+	EnvPassphrase = "FRIDAY_SECRETS_PASSPHRASE" // passphrase for stores created with the passphrase source
+	EnvKeyFile    = "FRIDAY_SECRETS_KEY_FILE"   // key file path for the file source
+)
+
+const (
+	keyFileName = "secrets.key"
+	keySize     = 32
+)
+
+// KeyOptions supply the key material of a Store.
+type KeyOptions struct {
+	Source     KeySource // source for a new store; empty picks passphrase when one is set, else file
+	Passphrase string
+	KeyFile    string
+}
+
+// KeyOptionsFromEnv reads the FRIDAY_SECRETS_* variables.
+func KeyOptionsFromEnv() KeyOptions {
+	opts := KeyOptions{
+		Source:     KeySource(strings.ToLower(strings.TrimSpace(os.Getenv(EnvKeySource)))),
+		Passphrase: os.Getenv(EnvPassphrase),
+		KeyFile:    strings.TrimSpace(os.Getenv(EnvKeyFile)),
+	}
+	if opts.KeyFile == "" {
+		opts.KeyFile = filepath.Join(consts.FridayHomeDir(), keyFileName)
+	}
+	return opts
+}
+
+func (o KeyOptions) sourceForNewStore() KeySource {
+	switch {
+	case o.Source != "":
+		return o.Source
+	case o.Passphrase != "":
+		return KeySourcePassphrase
+	}
+	return KeySourceFile
+}
+
+// resolve returns the key for source. With create set, a missing key file
+// or keyring entry is generated.
+func (o KeyOptions) resolve(source KeySource, kdf *kdfParams, create bool) ([]byte, error) {
+	switch source {
+	case KeySourcePassphrase:
+		if o.Passphrase == "" {
+			return nil, fmt.Errorf("the secrets store is protected by a passphrase; set %s", EnvPassphrase)
+		}
+		if kdf == nil {
+			return nil, errors.New("secrets store has no key derivation parameters")
+		}
+		return kdf.derive(o.Passphrase)
+	case KeySourceFile:
+		return o.fileKey(create)
+	case KeySourceKeyring:
+		return keyringKey(create)
+	default:
+		return nil, fmt.Errorf("unknown secrets key source %q; use file, passphrase or keyring", source)
+	}
+}
+
+func (o KeyOptions) fileKey(create bool) ([]byte, error) {
+	if o.KeyFile == "" {
+		return nil, errors.New("secrets key file path is not set")
+	}
+	raw, err := os.ReadFile(o.KeyFile)
+	if err == nil {
+		return decodeKey(string(raw))
+	}
+	if !os.IsNotExist(err) || !create {
+		return nil, fmt.Errorf("read secrets key file: %w", err)
+	}
+
+	key, encoded, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(o.KeyFile), 0o700); err != nil {
+		return nil, fmt.Errorf("create secrets key dir: %w", err)
+	}
+	f, err := os.OpenFile(o.KeyFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("create secrets key file: %w", err)
+	}
+	if _, err := f.WriteString(encoded + "\n"); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("write secrets key file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("close secrets key file: %w", err)
+	}
+	return key, nil
+}
+
+func keyringKey(create bool) ([]byte, error) {
+	encoded, err := keyringGet()
+	if err == nil {
+		return decodeKey(encoded)
+	}
+	if !errors.Is(err, errKeyringNotFound) || !create {
+		return nil, fmt.Errorf("read key from OS keyring: %w", err)
+	}
+
+	key, encoded, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := keyringSet(encoded); err != nil {
+		return nil, fmt.Errorf("store key in OS keyring: %w", err)
+	}
+	return key, nil
+}
+
+func newKey() ([]byte, string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, "", fmt.Errorf("generate secrets key: %w", err)
+	}
+	return key, hex.EncodeToString(key), nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("secrets key must be %d hex-encoded bytes", keySize)
+	}
+	return key, nil
+}
+
+// kdfParams are the scrypt parameters of a passphrase-protected store.
+type kdfParams struct {
+	Salt []byte `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+func newKDFParams() (*kdfParams, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+	return &kdfParams{Salt: salt, N: 1 << 15, R: 8, P: 1}, nil
+}
+
+func (k *kdfParams) derive(passphrase string) ([]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), k.Salt, k.N, k.R, k.P, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive secrets key: %w", err)
+	}
+	return key, nil
+}
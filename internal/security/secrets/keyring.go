@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// The OS keyring is reached through the platform's command-line client:
+// security(1) on macOS and secret-tool from libsecret on Linux.
+const (
+	keyringService = "friday"
+	keyringAccount = "secrets-key"
+)
+
+var errKeyringNotFound = errors.New("no key in the OS keyring")
+
+func keyringGet() (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", keyringAccount, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", keyringService, "account", keyringAccount)
+	default:
+		return "", fmt.Errorf("OS keyring is not supported on %s", runtime.GOOS)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// Both tools exit non-zero when the item does not exist.
+			return "", fmt.Errorf("%w: %s", errKeyringNotFound, strings.TrimSpace(stderr.String()))
+		}
+		return "", err
+	}
+	if value := strings.TrimSpace(string(out)); value != "" {
+		return value, nil
+	}
+	return "", errKeyringNotFound
+}
+
+func keyringSet(value string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "add-generic-password", "-U", "-s", keyringService, "-a", keyringAccount, "-w", value)
+	case "linux":
+		cmd = exec.Command("secret-tool", "store", "--label=Friday secrets key", "service", keyringService, "account", keyringAccount)
+		cmd.Stdin = strings.NewReader(value)
+	default:
+		return fmt.Errorf("OS keyring is not supported on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
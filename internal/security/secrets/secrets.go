@@ -0,0 +1,327 @@
+// Package secrets keeps API keys and tokens in an encrypted file under
+// FRIDAY_HOME, so config.yaml can reference them as ${secret:name} instead
+// of holding them in plaintext. Each value is sealed with AES-256-GCM using
+// a key taken from a passphrase, a key file or the OS keyring; the secret
+// name is bound as additional data so values cannot be swapped between
+// names. Names and timestamps are stored in the clear.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tgifai/friday/internal/consts"
+)
+
+const (
+	storeFileName = "secrets.json"
+	storeVersion  = 1
+
+	// checkText is sealed with the key when the store is created, so a
+	// wrong key is reported before anything is read or written with it.
+	checkText = "friday-secrets"
+)
+
+var (
+	ErrNotFound    = errors.New("secret not found")
+	ErrWrongKey    = errors.New("secrets key does not match the store")
+	ErrInvalidName = errors.New("secret names may only contain letters, digits, '.', '_' and '-'")
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// Info describes a stored secret without its value.
+type Info struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type storeFile struct {
+	Version   int                    `json:"version"`
+	KeySource KeySource              `json:"key_source"`
+	KDF       *kdfParams             `json:"kdf,omitempty"` // passphrase only
+	Check     []byte                 `json:"check"`
+	Secrets   map[string]sealedValue `json:"secrets"`
+}
+
+type sealedValue struct {
+	Data      []byte    `json:"data"` // nonce || ciphertext
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store is an encrypted secrets file. The key is resolved on first use and
+// kept in memory for the life of the Store.
+type Store struct {
+	path string
+	keys KeyOptions
+
+	mu  sync.Mutex
+	key []byte
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultStore *Store
+)
+
+// Default returns the store at FRIDAY_HOME/secrets.json, keyed as
+// configured by the FRIDAY_SECRETS_* environment variables.
+func Default() *Store {
+	defaultOnce.Do(func() {
+		defaultStore = NewStore(DefaultPath(), KeyOptionsFromEnv())
+	})
+	return defaultStore
+}
+
+// DefaultPath is the path of the default store.
+func DefaultPath() string {
+	return filepath.Join(consts.FridayHomeDir(), storeFileName)
+}
+
+// NewStore returns a store backed by the file at path.
+func NewStore(path string, keys KeyOptions) *Store {
+	return &Store{path: path, keys: keys}
+}
+
+// Path returns the store file path.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Get decrypts the named secret.
+func (s *Store) Get(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	one, ok := f.Secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	aead, err := s.unlock(f)
+	if err != nil {
+		return "", err
+	}
+	plain, err := open(aead, one.Data, name)
+	if err != nil {
+		return "", fmt.Errorf("decrypt secret %s: %w", name, err)
+	}
+	return string(plain), nil
+}
+
+// Set encrypts and stores value under name, creating the store and its key
+// on first use.
+func (s *Store) Set(name, value string) error {
+	if !namePattern.MatchString(name) {
+		return ErrInvalidName
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.load()
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		if f, err = s.create(); err != nil {
+			return err
+		}
+	}
+	aead, err := s.unlock(f)
+	if err != nil {
+		return err
+	}
+	data, err := seal(aead, []byte(value), name)
+	if err != nil {
+		return err
+	}
+	f.Secrets[name] = sealedValue{Data: data, UpdatedAt: time.Now().UTC()}
+	return s.save(f)
+}
+
+// Delete removes the named secret. It reports whether it existed.
+func (s *Store) Delete(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.load()
+	if err != nil || f == nil {
+		return false, err
+	}
+	if _, ok := f.Secrets[name]; !ok {
+		return false, nil
+	}
+	delete(f.Secrets, name)
+	return true, s.save(f)
+}
+
+// List returns the stored secret names, sorted. It does not need the key.
+func (s *Store) List() ([]Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.load()
+	if err != nil || f == nil {
+		return nil, err
+	}
+	out := make([]Info, 0, len(f.Secrets))
+	for name, one := range f.Secrets {
+		out = append(out, Info{Name: name, UpdatedAt: one.UpdatedAt})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// KeySource reports how the store's key is obtained, or "" when the store
+// does not exist yet.
+func (s *Store) KeySource() (KeySource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.load()
+	if err != nil || f == nil {
+		return "", err
+	}
+	return f.KeySource, nil
+}
+
+// load reads the store file; a missing file yields nil.
+func (s *Store) load() (*storeFile, error) {
+	raw, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read secrets store: %w", err)
+	}
+	var f storeFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("parse secrets store: %w", err)
+	}
+	if f.Version != storeVersion {
+		return nil, fmt.Errorf("unsupported secrets store version %d", f.Version)
+	}
+	if f.Secrets == nil {
+		f.Secrets = make(map[string]sealedValue)
+	}
+	return &f, nil
+}
+
+// create initialises a new store with the configured key source.
+func (s *Store) create() (*storeFile, error) {
+	f := &storeFile{
+		Version:   storeVersion,
+		KeySource: s.keys.sourceForNewStore(),
+		Secrets:   make(map[string]sealedValue),
+	}
+	if f.KeySource == KeySourcePassphrase {
+		kdf, err := newKDFParams()
+		if err != nil {
+			return nil, err
+		}
+		f.KDF = kdf
+	}
+	key, err := s.keys.resolve(f.KeySource, f.KDF, true)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if f.Check, err = seal(aead, []byte(checkText), ""); err != nil {
+		return nil, err
+	}
+	s.key = key
+	return f, nil
+}
+
+// unlock resolves the key of f and verifies it against the check value.
+func (s *Store) unlock(f *storeFile) (cipher.AEAD, error) {
+	if s.key == nil {
+		key, err := s.keys.resolve(f.KeySource, f.KDF, false)
+		if err != nil {
+			return nil, err
+		}
+		s.key = key
+	}
+	aead, err := newAEAD(s.key)
+	if err != nil {
+		return nil, err
+	}
+	if plain, err := open(aead, f.Check, ""); err != nil || string(plain) != checkText {
+		s.key = nil
+		return nil, ErrWrongKey
+	}
+	return aead, nil
+}
+
+func (s *Store) save(f *storeFile) error {
+	raw, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal secrets store: %w", err)
+	}
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create secrets dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".tmp.*")
+	if err != nil {
+		return fmt.Errorf("create temp secrets file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write temp secrets file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp secrets file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0o600); err != nil {
+		return fmt.Errorf("chmod temp secrets file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("replace secrets file: %w", err)
+	}
+	return nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("init cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plain []byte, name string) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plain, []byte(name)), nil
+}
+
+func open(aead cipher.AEAD, data []byte, name string) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ct := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ct, []byte(name))
+}
@@ -0,0 +1,143 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStore_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secrets.json")
+	keys := KeyOptions{KeyFile: filepath.Join(dir, "secrets.key")}
+
+	s := NewStore(path, keys)
+	if err := s.Set("openai", "sk-test"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "sk-test") {
+		t.Fatal("store file contains the plaintext value")
+	}
+
+	// A fresh store with the same key file reads it back.
+	got, err := NewStore(path, keys).Get("openai")
+	if err != nil || got != "sk-test" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	if _, err := s.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+
+	removed, err := s.Delete("openai")
+	if err != nil || !removed {
+		t.Fatalf("Delete = %t, %v", removed, err)
+	}
+	infos, err := s.List()
+	if err != nil || len(infos) != 0 {
+		t.Fatalf("List after delete = %v, %v", infos, err)
+	}
+}
+
+func TestStore_Passphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+
+	if err := NewStore(path, KeyOptions{Passphrase: "correct horse"}).Set("token", "v1"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	src, err := NewStore(path, KeyOptions{}).KeySource()
+	if err != nil || src != KeySourcePassphrase {
+		t.Fatalf("KeySource = %q, %v", src, err)
+	}
+
+	if _, err := NewStore(path, KeyOptions{Passphrase: "wrong"}).Get("token"); !errors.Is(err, ErrWrongKey) {
+		t.Fatalf("Get with wrong passphrase error = %v, want ErrWrongKey", err)
+	}
+	if err := NewStore(path, KeyOptions{Passphrase: "wrong"}).Set("other", "v2"); !errors.Is(err, ErrWrongKey) {
+		t.Fatalf("Set with wrong passphrase error = %v, want ErrWrongKey", err)
+	}
+	got, err := NewStore(path, KeyOptions{Passphrase: "correct horse"}).Get("token")
+	if err != nil || got != "v1" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+}
+
+func TestStore_ValuesBoundToName(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secrets.json")
+	s := NewStore(path, KeyOptions{KeyFile: filepath.Join(dir, "secrets.key")})
+	if err := s.Set("a", "value-a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("b", "value-b"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Swapping ciphertexts between names must not decrypt.
+	s.mu.Lock()
+	f, err := s.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Secrets["a"], f.Secrets["b"] = f.Secrets["b"], f.Secrets["a"]
+	if err := s.save(f); err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Unlock()
+
+	if _, err := s.Get("a"); err == nil {
+		t.Fatal("Get succeeded on a swapped value")
+	}
+}
+
+func TestStore_InvalidName(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "secrets.json"), KeyOptions{Passphrase: "p"})
+	if err := s.Set("bad name}", "v"); !errors.Is(err, ErrInvalidName) {
+		t.Fatalf("Set error = %v, want ErrInvalidName", err)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("FRIDAY_HOME", home)
+	t.Setenv(EnvKeySource, "")
+	t.Setenv(EnvPassphrase, "")
+	t.Setenv(EnvKeyFile, "")
+	t.Setenv("FRIDAY_TEST_ENV", "from-env")
+	if err := Default().Set("bot", "from-store"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ExpandMap(map[string]any{
+		"token":   "${secret:bot}",
+		"api_key": "${FRIDAY_TEST_ENV}",
+		"header":  "Bearer ${secret:bot}",
+		"nested":  map[string]any{"list": []any{"${FRIDAY_TEST_ENV}", 3}},
+		"plain":   "no placeholders",
+		"timeout": 60,
+	})
+	if err != nil {
+		t.Fatalf("ExpandMap: %v", err)
+	}
+	if got["token"] != "from-store" || got["api_key"] != "from-env" || got["header"] != "Bearer from-store" {
+		t.Fatalf("ExpandMap = %v", got)
+	}
+	if list := got["nested"].(map[string]any)["list"].([]any); list[0] != "from-env" || list[1] != 3 {
+		t.Fatalf("nested = %v", list)
+	}
+	if got["plain"] != "no placeholders" || got["timeout"] != 60 {
+		t.Fatalf("untouched values changed: %v", got)
+	}
+
+	if _, err := Expand("${secret:missing}"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expand(missing secret) error = %v", err)
+	}
+	if _, err := Expand("${FRIDAY_TEST_UNSET}"); err == nil {
+		t.Fatal("Expand(unset env) succeeded")
+	}
+}
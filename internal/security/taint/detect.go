@@ -0,0 +1,33 @@
+package taint
+
+import "regexp"
+
+// injectionPatterns are heuristics for text that tries to steer the model.
+// They favour phrases that rarely occur in ordinary pages; a match is a
+// warning sign, not proof.
+var injectionPatterns = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"ignore_instructions", regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\s+(all\s+|any\s+|the\s+|your\s+|of\s+)*(previous|prior|above|earlier|preceding|system|original)\s+(instructions|prompts?|rules|directions|guidelines)`)},
+	{"new_instructions", regexp.MustCompile(`(?i)\b(new|updated|real|actual|additional)\s+(system\s+)?instructions\s*:|\b(reveal|print|show|repeat|output)\s+(your|the)\s+(system\s+prompt|instructions)`)},
+	{"role_override", regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(a|an|the|in|no\s+longer)\b|\bdeveloper\s+mode\b|\bjailbr(eak|oken)\b`)},
+	{"role_tag", regexp.MustCompile(`(?im)<\|im_(start|end)\|>|<\|(system|assistant|user)\|>|\[/?INST\]|</?(system|assistant)>|^\s*#*\s*(system|assistant)\s*:`)},
+	{"tool_directive", regexp.MustCompile(`(?i)\b(run|execute)\s+(the\s+following|this)\s+(shell\s+|bash\s+|terminal\s+)?(command|code|script)\b|\b(call|use|invoke)\s+the\s+[a-z_]+\s+tool\b`)},
+	{"pipe_to_shell", regexp.MustCompile(`(?i)\b(curl|wget)\s+[^\n|]*\|\s*(sudo\s+)?(ba|z)?sh\b`)},
+	{"exfiltration", regexp.MustCompile(`(?i)\b(send|post|upload|forward|email|leak)\b[^\n]{0,60}(\b(api[\s_-]?keys?|passwords?|credentials|secrets?|access\s+tokens?|ssh\s+keys?|private\s+keys?)\b|\.env\b)`)},
+	{"concealment", regexp.MustCompile(`(?i)\b(do\s+not|don't|never)\s+(tell|inform|alert|notify|mention\s+(this|it)\s+to)\s+(the\s+)?user\b`)},
+	{"hidden_text", regexp.MustCompile(`[\x{200B}-\x{200F}\x{2060}-\x{2064}\x{E0000}-\x{E007F}]{3,}`)},
+}
+
+// Detect returns the names of the injection patterns found in content, in
+// a fixed order, or nil when none match.
+func Detect(content string) []string {
+	var found []string
+	for _, p := range injectionPatterns {
+		if p.re.MatchString(content) {
+			found = append(found, p.name)
+		}
+	}
+	return found
+}
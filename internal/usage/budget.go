@@ -0,0 +1,225 @@
+package usage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tgifai/friday/internal/config"
+)
+
+// Budget periods.
+const (
+	PeriodDaily   = "daily"
+	PeriodMonthly = "monthly"
+)
+
+// Spend is the usage accumulated by one budget scope in one period.
+type Spend struct {
+	Tokens int
+	Cost   float64
+}
+
+// spendIndex keeps today's and this month's spend per scope key so budget
+// checks don't rescan the ledger on every turn.
+type spendIndex struct {
+	day     string
+	month   string
+	daily   map[string]Spend
+	monthly map[string]Spend
+}
+
+func newSpendIndex(now time.Time) *spendIndex {
+	return &spendIndex{
+		day:     now.Format(time.DateOnly),
+		month:   now.Format(monthLayout),
+		daily:   make(map[string]Spend),
+		monthly: make(map[string]Spend),
+	}
+}
+
+// roll resets the totals of periods that ended before now.
+func (s *spendIndex) roll(now time.Time) {
+	if month := now.Format(monthLayout); month != s.month {
+		s.month = month
+		s.monthly = make(map[string]Spend)
+	}
+	if day := now.Format(time.DateOnly); day != s.day {
+		s.day = day
+		s.daily = make(map[string]Spend)
+	}
+}
+
+func (s *spendIndex) add(rec *Record) {
+	t := rec.Time.Local()
+	if t.Format(time.DateOnly) > s.day {
+		s.roll(t)
+	}
+	inMonth := t.Format(monthLayout) == s.month
+	inDay := t.Format(time.DateOnly) == s.day
+	for _, key := range scopeKeys(rec) {
+		if inMonth {
+			m := s.monthly[key]
+			m.Tokens += rec.TotalTokens
+			m.Cost += rec.Cost
+			s.monthly[key] = m
+		}
+		if inDay {
+			d := s.daily[key]
+			d.Tokens += rec.TotalTokens
+			d.Cost += rec.Cost
+			s.daily[key] = d
+		}
+	}
+}
+
+// Scope keys of the budget index.
+func agentScope(agentID string) string          { return "agent:" + agentID }
+func channelScope(channelID string) string      { return "channel:" + channelID }
+func userScope(channelID, userID string) string { return "user:" + channelID + ":" + userID }
+func scheduledScope(agentID string) string      { return "scheduled:" + agentID }
+
+// scopeKeys lists the budget scopes a record counts against. Scheduled
+// traffic has its own budget and is kept out of the interactive scopes.
+func scopeKeys(rec *Record) []string {
+	if rec.Scheduled {
+		return []string{scheduledScope(rec.AgentID)}
+	}
+	keys := []string{agentScope(rec.AgentID), channelScope(rec.ChannelID)}
+	if rec.UserID != "" {
+		keys = append(keys, userScope(rec.ChannelID, rec.UserID))
+	}
+	return keys
+}
+
+// Spent returns today's and this month's spend for a scope key. The index is
+// built from the current month's ledger file on first use and then kept up
+// to date by Append.
+func (l *Ledger) Spent(key string, now time.Time) (daily, monthly Spend, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.spend == nil {
+		idx := newSpendIndex(now)
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		paths, err := l.files(monthStart, time.Time{})
+		if err != nil {
+			return Spend{}, Spend{}, err
+		}
+		for _, path := range paths {
+			recs, err := readRecords(path, Filter{Since: monthStart})
+			if err != nil {
+				return Spend{}, Spend{}, err
+			}
+			for i := range recs {
+				idx.add(&recs[i])
+			}
+		}
+		l.spend = idx
+	}
+	l.spend.roll(now)
+	return l.spend.daily[key], l.spend.monthly[key], nil
+}
+
+// Verdict is the outcome of a budget check.
+type Verdict struct {
+	Exceeded bool    // a budget is used up
+	Warn     bool    // a budget passed the warning threshold
+	Scope    string  // human-readable scope, e.g. "user 42"
+	Period   string  // PeriodDaily or PeriodMonthly
+	Used     float64 // fraction of the budget used
+	Key      string  // identifies the budget and its current period
+}
+
+type budgetScope struct {
+	key    string
+	label  string
+	limits config.BudgetLimits
+}
+
+// applicableBudgets lists the budgets that govern a turn tagged with tags.
+func applicableBudgets(cfg *config.BudgetConfig, tags Tags) []budgetScope {
+	if tags.Scheduled {
+		return []budgetScope{{scheduledScope(tags.AgentID), "scheduled jobs of agent " + tags.AgentID, cfg.Scheduled}}
+	}
+
+	var out []budgetScope
+	if limits, ok := lookup(cfg.Agents, tags.AgentID); ok {
+		out = append(out, budgetScope{agentScope(tags.AgentID), "agent " + tags.AgentID, limits})
+	}
+	if limits, ok := lookup(cfg.Channels, tags.ChannelID); ok {
+		out = append(out, budgetScope{channelScope(tags.ChannelID), "channel " + tags.ChannelID, limits})
+	}
+	if tags.UserID != "" {
+		if limits, ok := lookup(cfg.Users, tags.ChannelID+":"+tags.UserID, tags.UserID); ok {
+			out = append(out, budgetScope{userScope(tags.ChannelID, tags.UserID), "user " + tags.UserID, limits})
+		}
+	}
+	return out
+}
+
+// lookup returns the limits of the first matching key, falling back to "*".
+func lookup(m map[string]config.BudgetLimits, keys ...string) (config.BudgetLimits, bool) {
+	for _, k := range append(keys, "*") {
+		if limits, ok := m[k]; ok {
+			return limits, true
+		}
+	}
+	return config.BudgetLimits{}, false
+}
+
+// used returns the larger of the token and cost fractions of limit spent,
+// or -1 when the limit caps nothing.
+func used(limit config.BudgetLimit, spend Spend) float64 {
+	frac := -1.0
+	if limit.Tokens > 0 {
+		frac = max(frac, float64(spend.Tokens)/float64(limit.Tokens))
+	}
+	if limit.Cost > 0 {
+		frac = max(frac, spend.Cost/limit.Cost)
+	}
+	return frac
+}
+
+// CheckBudget evaluates every budget that applies to tags. An exhausted
+// budget wins over a warning; among warnings the fullest budget is reported.
+func (l *Ledger) CheckBudget(cfg *config.BudgetConfig, tags Tags, now time.Time) (Verdict, error) {
+	var verdict Verdict
+	for _, scope := range applicableBudgets(cfg, tags) {
+		if scope.limits == (config.BudgetLimits{}) {
+			continue
+		}
+		daily, monthly, err := l.Spent(scope.key, now)
+		if err != nil {
+			return Verdict{}, fmt.Errorf("read spend of %s: %w", scope.key, err)
+		}
+		for _, p := range []struct {
+			period string
+			id     string
+			limit  config.BudgetLimit
+			spend  Spend
+		}{
+			{PeriodDaily, now.Format(time.DateOnly), scope.limits.Daily, daily},
+			{PeriodMonthly, now.Format(monthLayout), scope.limits.Monthly, monthly},
+		} {
+			frac := used(p.limit, p.spend)
+			if frac < 0 {
+				continue
+			}
+			v := Verdict{
+				Scope:  scope.label,
+				Period: p.period,
+				Used:   frac,
+				Key:    scope.key + ":" + p.id,
+			}
+			switch {
+			case frac >= 1:
+				v.Exceeded = true
+				return v, nil
+			case frac >= cfg.WarnAt && frac > verdict.Used:
+				v.Warn = true
+				verdict = v
+			}
+		}
+	}
+	return verdict, nil
+}
@@ -0,0 +1,77 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tgifai/friday/internal/config"
+)
+
+func TestCheckBudget(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	l := NewLedger(t.TempDir())
+	for _, rec := range []Record{
+		// Earlier this month: counts towards monthly only.
+		{Time: now.AddDate(0, 0, -3), AgentID: "a1", ChannelID: "tg", UserID: "u1", TotalTokens: 500},
+		// Today.
+		{Time: now.Add(-time.Hour), AgentID: "a1", ChannelID: "tg", UserID: "u1", TotalTokens: 300, Cost: 0.9},
+		{Time: now.Add(-time.Hour), AgentID: "a1", ChannelID: "tg", UserID: "u2", TotalTokens: 100},
+		// Scheduled traffic is kept out of the interactive scopes.
+		{Time: now.Add(-time.Hour), AgentID: "a1", ChannelID: "tg", Scheduled: true, TotalTokens: 10_000},
+	} {
+		if err := l.Append(rec); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	cfg := &config.BudgetConfig{
+		WarnAt: 0.8,
+		Users: map[string]config.BudgetLimits{
+			"*":     {Daily: config.BudgetLimit{Tokens: 1000}, Monthly: config.BudgetLimit{Tokens: 800}},
+			"tg:u2": {Daily: config.BudgetLimit{Cost: 1}},
+		},
+		Channels: map[string]config.BudgetLimits{
+			"tg": {Daily: config.BudgetLimit{Cost: 1}},
+		},
+		Scheduled: config.BudgetLimits{Daily: config.BudgetLimit{Tokens: 5000}},
+	}
+
+	v, err := l.CheckBudget(cfg, Tags{AgentID: "a1", ChannelID: "tg", UserID: "u1"}, now)
+	if err != nil {
+		t.Fatalf("CheckBudget: %v", err)
+	}
+	if !v.Exceeded || v.Scope != "user u1" || v.Period != PeriodMonthly {
+		t.Errorf("u1 verdict = %+v, want monthly user budget exceeded", v)
+	}
+
+	v, _ = l.CheckBudget(cfg, Tags{AgentID: "a1", ChannelID: "tg", UserID: "u2"}, now)
+	if v.Exceeded || !v.Warn || v.Scope != "channel tg" || v.Period != PeriodDaily {
+		t.Errorf("u2 verdict = %+v, want daily channel warning", v)
+	}
+
+	v, _ = l.CheckBudget(cfg, Tags{AgentID: "a1", ChannelID: "tg", Scheduled: true}, now)
+	if !v.Exceeded || v.Scope != "scheduled jobs of agent a1" {
+		t.Errorf("scheduled verdict = %+v, want scheduled budget exceeded", v)
+	}
+
+	// The daily total resets at midnight.
+	v, _ = l.CheckBudget(cfg, Tags{AgentID: "a1", ChannelID: "tg", UserID: "u2"}, now.Add(13*time.Hour))
+	if v.Exceeded || v.Warn {
+		t.Errorf("next-day verdict = %+v, want no warning", v)
+	}
+}
+
+func TestSpent_TracksAppends(t *testing.T) {
+	now := time.Now()
+	l := NewLedger(t.TempDir())
+	if _, _, err := l.Spent(agentScope("a1"), now); err != nil {
+		t.Fatalf("Spent: %v", err)
+	}
+	if err := l.Append(Record{Time: now, AgentID: "a1", ChannelID: "tg", TotalTokens: 42, Cost: 0.5}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	daily, monthly, _ := l.Spent(agentScope("a1"), now)
+	if daily.Tokens != 42 || monthly.Cost != 0.5 {
+		t.Errorf("Spent = %+v / %+v, want the appended record", daily, monthly)
+	}
+}
@@ -0,0 +1,173 @@
+package usage
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+const monthLayout = "2006-01"
+
+// Ledger is an append-only record of model calls, stored as one JSONL file
+// per calendar month (e.g. 2026-10.jsonl).
+type Ledger struct {
+	dir string
+	mu  sync.Mutex
+
+	spend *spendIndex // current-period totals, built on the first Spent call
+}
+
+// NewLedger creates a Ledger writing to dir. The directory is created on the
+// first Append.
+func NewLedger(dir string) *Ledger {
+	return &Ledger{dir: dir}
+}
+
+// Append writes rec to the file of the month it belongs to.
+func (l *Ledger) Append(rec Record) error {
+	data, err := sonic.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal usage record: %w", err)
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return fmt.Errorf("create usage directory: %w", err)
+	}
+	f, err := os.OpenFile(l.monthPath(rec.Time), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open usage ledger: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("write usage ledger: %w", err)
+	}
+	if l.spend != nil {
+		l.spend.add(&rec)
+	}
+	return nil
+}
+
+// Filter selects ledger records. Zero fields match everything; Until is
+// exclusive.
+type Filter struct {
+	Since     time.Time
+	Until     time.Time
+	AgentID   string
+	UserID    string
+	ChannelID string
+	Model     string
+}
+
+func (f Filter) match(rec *Record) bool {
+	switch {
+	case !f.Since.IsZero() && rec.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !rec.Time.Before(f.Until):
+		return false
+	case f.AgentID != "" && rec.AgentID != f.AgentID:
+		return false
+	case f.UserID != "" && rec.UserID != f.UserID:
+		return false
+	case f.ChannelID != "" && rec.ChannelID != f.ChannelID:
+		return false
+	case f.Model != "" && rec.Model != f.Model && rec.Provider+":"+rec.Model != f.Model:
+		return false
+	}
+	return true
+}
+
+// Query returns the records matching f in chronological file order. Only the
+// monthly files overlapping [Since, Until) are read; malformed lines are
+// skipped.
+func (l *Ledger) Query(f Filter) ([]Record, error) {
+	paths, err := l.files(f.Since, f.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Record
+	for _, path := range paths {
+		recs, err := readRecords(path, f)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, recs...)
+	}
+	return out, nil
+}
+
+func (l *Ledger) monthPath(t time.Time) string {
+	return filepath.Join(l.dir, t.Format(monthLayout)+".jsonl")
+}
+
+// files lists the monthly files that may hold records in [since, until).
+func (l *Ledger) files(since, until time.Time) ([]string, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read usage directory: %w", err)
+	}
+
+	var paths []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".jsonl")
+		if e.IsDir() || !ok {
+			continue
+		}
+		month, err := time.ParseInLocation(monthLayout, name, time.Local)
+		if err != nil {
+			continue
+		}
+		if !since.IsZero() && !month.AddDate(0, 1, 0).After(since) {
+			continue
+		}
+		if !until.IsZero() && !month.Before(until) {
+			continue
+		}
+		paths = append(paths, filepath.Join(l.dir, e.Name()))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func readRecords(path string, f Filter) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open usage ledger: %w", err)
+	}
+	defer file.Close()
+
+	var out []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec Record
+		if err := sonic.Unmarshal(line, &rec); err != nil {
+			continue
+		}
+		if f.match(&rec) {
+			out = append(out, rec)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read usage ledger %s: %w", path, err)
+	}
+	return out, nil
+}
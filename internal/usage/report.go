@@ -0,0 +1,168 @@
+package usage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Report grouping dimensions.
+const (
+	ByDay     = "day"
+	ByUser    = "user"
+	ByModel   = "model"
+	ByAgent   = "agent"
+	ByChannel = "channel"
+	ByPurpose = "purpose"
+)
+
+// Dimensions lists the accepted grouping dimensions.
+var Dimensions = []string{ByDay, ByUser, ByModel, ByAgent, ByChannel, ByPurpose}
+
+// Row is the usage total of one group.
+type Row struct {
+	Keys             []string
+	Calls            int
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Cost             float64
+}
+
+func (r *Row) add(rec *Record) {
+	r.Calls++
+	r.PromptTokens += rec.PromptTokens
+	r.CompletionTokens += rec.CompletionTokens
+	r.TotalTokens += rec.TotalTokens
+	r.Cost += rec.Cost
+}
+
+// ValidateDimensions reports an unknown grouping dimension.
+func ValidateDimensions(by []string) error {
+	for _, dim := range by {
+		known := false
+		for _, d := range Dimensions {
+			known = known || d == dim
+		}
+		if !known {
+			return fmt.Errorf("unknown dimension %q (want one of %s)", dim, strings.Join(Dimensions, ", "))
+		}
+	}
+	return nil
+}
+
+func dimension(rec *Record, dim string) string {
+	var v string
+	switch dim {
+	case ByDay:
+		v = rec.Time.Local().Format("2006-01-02")
+	case ByUser:
+		v = rec.UserID
+	case ByModel:
+		v = rec.Provider + ":" + rec.Model
+	case ByAgent:
+		v = rec.AgentID
+	case ByChannel:
+		v = rec.ChannelID
+	case ByPurpose:
+		v = rec.Purpose
+	}
+	if v == "" {
+		v = "-"
+	}
+	return v
+}
+
+// Summarize groups records by the given dimensions, sorted by key. With no
+// dimensions it returns a single total row.
+func Summarize(records []Record, by ...string) []Row {
+	groups := make(map[string]*Row)
+	for i := range records {
+		rec := &records[i]
+		keys := make([]string, len(by))
+		for j, dim := range by {
+			keys[j] = dimension(rec, dim)
+		}
+		id := strings.Join(keys, "\x00")
+		row, ok := groups[id]
+		if !ok {
+			row = &Row{Keys: keys}
+			groups[id] = row
+		}
+		row.add(rec)
+	}
+
+	rows := make([]Row, 0, len(groups))
+	for _, row := range groups {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return strings.Join(rows[i].Keys, "\x00") < strings.Join(rows[j].Keys, "\x00")
+	})
+	return rows
+}
+
+// Total sums rows into one.
+func Total(rows []Row) Row {
+	var total Row
+	for _, r := range rows {
+		total.Calls += r.Calls
+		total.PromptTokens += r.PromptTokens
+		total.CompletionTokens += r.CompletionTokens
+		total.TotalTokens += r.TotalTokens
+		total.Cost += r.Cost
+	}
+	return total
+}
+
+// FormatTable renders rows as an aligned text table followed by a total line.
+func FormatTable(rows []Row, by []string, currency string) string {
+	if len(rows) == 0 {
+		return "No usage recorded\n"
+	}
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	header := make([]string, 0, len(by)+5)
+	for _, dim := range by {
+		header = append(header, strings.ToUpper(dim))
+	}
+	header = append(header, "CALLS", "PROMPT", "COMPLETION", "TOTAL", "COST ("+currency+")")
+	fmt.Fprintln(w, strings.Join(header, "\t")+"\t")
+	for _, r := range rows {
+		fmt.Fprintln(w, formatRow(r.Keys, r)+"\t")
+	}
+	if len(rows) > 1 {
+		keys := make([]string, len(by))
+		if len(keys) > 0 {
+			keys[0] = "total"
+		}
+		fmt.Fprintln(w, formatRow(keys, Total(rows))+"\t")
+	}
+	_ = w.Flush()
+	return b.String()
+}
+
+func formatRow(keys []string, r Row) string {
+	cols := append(append([]string(nil), keys...),
+		fmt.Sprint(r.Calls),
+		fmt.Sprint(r.PromptTokens),
+		fmt.Sprint(r.CompletionTokens),
+		fmt.Sprint(r.TotalTokens),
+		fmt.Sprintf("%.4f", r.Cost),
+	)
+	return strings.Join(cols, "\t")
+}
+
+// FormatTokens renders a token count compactly, e.g. 1234567 as "1.23M".
+func FormatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.2fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	default:
+		return fmt.Sprint(n)
+	}
+}
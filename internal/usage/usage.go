@@ -0,0 +1,137 @@
+package usage
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/tgifai/friday/internal/config"
+	"github.com/tgifai/friday/internal/consts"
+	"github.com/tgifai/friday/internal/pkg/logs"
+)
+
+// Purpose tells which kind of model call produced a record.
+const (
+	PurposeTurn       = "turn"       // agent loop iteration
+	PurposeSummary    = "summary"    // iteration-limit summary
+	PurposeCompact    = "compact"    // history summary during compaction
+	PurposeFlush      = "flush"      // memory flush before compaction
+	PurposeTranscribe = "transcribe" // voice transcription
+)
+
+const ledgerDirName = "usage"
+
+// Record is one model call as stored in the ledger.
+type Record struct {
+	Time             time.Time `json:"time"`
+	AgentID          string    `json:"agent_id,omitempty"`
+	SessionKey       string    `json:"session_key,omitempty"`
+	UserID           string    `json:"user_id,omitempty"`
+	ChannelID        string    `json:"channel_id,omitempty"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	Purpose          string    `json:"purpose"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CachedTokens     int       `json:"cached_tokens,omitempty"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	Cost             float64   `json:"cost,omitempty"`
+	Scheduled        bool      `json:"scheduled,omitempty"` // made by a cron job, heartbeat or memory flush
+}
+
+// Tags identify who a model call was made for.
+type Tags struct {
+	AgentID    string
+	SessionKey string
+	UserID     string
+	ChannelID  string
+	Scheduled  bool
+}
+
+type tagsCtxKey struct{}
+
+// WithTags returns a context whose model calls are recorded under tags.
+func WithTags(ctx context.Context, tags Tags) context.Context {
+	return context.WithValue(ctx, tagsCtxKey{}, tags)
+}
+
+// TagsFrom returns the tags stored in ctx, if any.
+func TagsFrom(ctx context.Context) Tags {
+	tags, _ := ctx.Value(tagsCtxKey{}).(Tags)
+	return tags
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultLedger *Ledger
+)
+
+// Default returns the ledger under FRIDAY_HOME/usage.
+func Default() *Ledger {
+	defaultOnce.Do(func() {
+		defaultLedger = NewLedger(DefaultDir())
+	})
+	return defaultLedger
+}
+
+// DefaultDir is the directory holding the default ledger files.
+func DefaultDir() string {
+	return filepath.Join(consts.FridayHomeDir(), ledgerDirName)
+}
+
+// Track records the provider-reported usage of resp in the default ledger,
+// tagged with the tags in ctx and priced from the configured price table.
+// Responses without usage are skipped.
+func Track(ctx context.Context, purpose, providerID, model string, resp *schema.Message) {
+	if resp == nil || resp.ResponseMeta == nil || resp.ResponseMeta.Usage == nil {
+		return
+	}
+	u := resp.ResponseMeta.Usage
+	tags := TagsFrom(ctx)
+	rec := Record{
+		Time:             time.Now(),
+		AgentID:          tags.AgentID,
+		SessionKey:       tags.SessionKey,
+		UserID:           tags.UserID,
+		ChannelID:        tags.ChannelID,
+		Scheduled:        tags.Scheduled,
+		Provider:         providerID,
+		Model:            model,
+		Purpose:          purpose,
+		PromptTokens:     u.PromptTokens,
+		CachedTokens:     u.PromptTokenDetails.CachedTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+	if rec.TotalTokens == 0 {
+		rec.TotalTokens = rec.PromptTokens + rec.CompletionTokens
+	}
+	if cfg, err := config.Get(); err == nil {
+		rec.Cost = Cost(cfg.Usage.Prices, rec)
+	}
+	if err := Default().Append(rec); err != nil {
+		logs.CtxWarn(ctx, "[usage] record %s:%s usage: %v", providerID, model, err)
+	}
+}
+
+// Cost prices rec from prices, looked up by "provider:model" first and then
+// by the bare model name. Unknown models cost nothing.
+func Cost(prices map[string]config.ModelPrice, rec Record) float64 {
+	price, ok := prices[rec.Provider+":"+rec.Model]
+	if !ok {
+		if price, ok = prices[rec.Model]; !ok {
+			return 0
+		}
+	}
+	cachedPrice := price.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = price.Input
+	}
+	cached := min(rec.CachedTokens, rec.PromptTokens)
+	return (float64(rec.PromptTokens-cached)*price.Input +
+		float64(cached)*cachedPrice +
+		float64(rec.CompletionTokens)*price.Output) / 1e6
+}
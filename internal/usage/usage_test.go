@@ -0,0 +1,91 @@
+package usage
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/tgifai/friday/internal/config"
+)
+
+func TestCost(t *testing.T) {
+	prices := map[string]config.ModelPrice{
+		"openai:gpt-4o": {Input: 2.5, Output: 10, CachedInput: 1.25},
+		"gpt-4o":        {Input: 100, Output: 100},
+		"claude":        {Input: 3, Output: 15},
+	}
+	cases := []struct {
+		name string
+		rec  Record
+		want float64
+	}{
+		{"provider-qualified wins", Record{Provider: "openai", Model: "gpt-4o", PromptTokens: 1_000_000, CompletionTokens: 100_000}, 3.5},
+		{"cached prompt tokens", Record{Provider: "openai", Model: "gpt-4o", PromptTokens: 1_000_000, CachedTokens: 400_000}, 2.0},
+		{"bare model name", Record{Provider: "anthropic", Model: "claude", PromptTokens: 2_000_000, CompletionTokens: 1_000_000}, 21},
+		{"unknown model", Record{Provider: "ollama", Model: "llama3", PromptTokens: 1_000_000}, 0},
+	}
+	for _, tc := range cases {
+		if got := Cost(prices, tc.rec); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s: Cost = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLedger_AppendQuery(t *testing.T) {
+	l := NewLedger(t.TempDir())
+	sep := time.Date(2026, 9, 30, 23, 0, 0, 0, time.Local)
+	oct := time.Date(2026, 10, 2, 9, 0, 0, 0, time.Local)
+	for _, rec := range []Record{
+		{Time: sep, AgentID: "a1", UserID: "u1", Provider: "openai", Model: "gpt-4o", TotalTokens: 10},
+		{Time: oct, AgentID: "a1", UserID: "u2", Provider: "openai", Model: "gpt-4o", TotalTokens: 20},
+		{Time: oct.Add(time.Hour), AgentID: "a2", UserID: "u1", Provider: "gemini", Model: "flash", TotalTokens: 30},
+	} {
+		if err := l.Append(rec); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	all, err := l.Query(Filter{})
+	if err != nil || len(all) != 3 {
+		t.Fatalf("Query(all) = %d records, %v; want 3", len(all), err)
+	}
+	octOnly, _ := l.Query(Filter{Since: time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)})
+	if len(octOnly) != 2 {
+		t.Errorf("Query(since Oct 1) = %d records, want 2", len(octOnly))
+	}
+	sepOnly, _ := l.Query(Filter{Until: time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)})
+	if len(sepOnly) != 1 || sepOnly[0].TotalTokens != 10 {
+		t.Errorf("Query(until Oct 1) = %+v, want the September record", sepOnly)
+	}
+	u1, _ := l.Query(Filter{UserID: "u1", Model: "gemini:flash"})
+	if len(u1) != 1 || u1[0].AgentID != "a2" {
+		t.Errorf("Query(u1, gemini:flash) = %+v, want the a2 record", u1)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	day := time.Date(2026, 10, 2, 9, 0, 0, 0, time.Local)
+	records := []Record{
+		{Time: day, UserID: "u1", Provider: "openai", Model: "gpt-4o", PromptTokens: 8, CompletionTokens: 2, TotalTokens: 10, Cost: 0.5},
+		{Time: day, UserID: "u1", Provider: "openai", Model: "gpt-4o", PromptTokens: 15, CompletionTokens: 5, TotalTokens: 20, Cost: 1},
+		{Time: day.AddDate(0, 0, 1), UserID: "", Provider: "openai", Model: "gpt-4o", TotalTokens: 5},
+	}
+
+	rows := Summarize(records, ByDay, ByUser)
+	if len(rows) != 2 {
+		t.Fatalf("Summarize = %d rows, want 2", len(rows))
+	}
+	first := rows[0]
+	if first.Keys[0] != "2026-10-02" || first.Keys[1] != "u1" || first.Calls != 2 || first.TotalTokens != 30 || first.Cost != 1.5 {
+		t.Errorf("first row = %+v", first)
+	}
+	if rows[1].Keys[1] != "-" {
+		t.Errorf("missing user key = %q, want -", rows[1].Keys[1])
+	}
+	if total := Total(rows); total.Calls != 3 || total.TotalTokens != 35 {
+		t.Errorf("Total = %+v", total)
+	}
+	if err := ValidateDimensions([]string{ByDay, "hour"}); err == nil {
+		t.Error("ValidateDimensions(hour) succeeded, want error")
+	}
+}